@@ -0,0 +1,115 @@
+//Package auth authorizes the calls of the OrderService. The service is called by scooter_server, which
+//authorizes its users itself, so the callers here are the services identified by their client certificates
+//or by the shared service token.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+//Role defines what the caller is allowed to do.
+type Role string
+
+//RoleService is the role of the services which call the OrderService on behalf of their users.
+const RoleService Role = "service"
+
+const (
+	authorizationHeader = "authorization"
+	//ServiceCommonName is the common name of the scooter_server certificate.
+	ServiceCommonName = "scooter_server"
+)
+
+var ErrUnauthenticated = errors.New("authentication required")
+
+//Caller is the authenticated caller of the OrderService.
+type Caller struct {
+	Role Role
+}
+
+//Policy maps the gRPC methods to the roles allowed to call them.
+//Methods which aren't listed in the policy can't be called at all.
+type Policy map[string][]Role
+
+//Allowed reports whether the caller, nil for the anonymous call, can call the method.
+func (p Policy) Allowed(method string, caller *Caller) bool {
+	if caller == nil {
+		return false
+	}
+	for _, role := range p[method] {
+		if caller.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+//Authenticator identifies the services by the verified client certificates and by the service token.
+//The empty token authenticates nobody, so without mutual TLS and the token every call is rejected.
+type Authenticator struct {
+	ServiceToken string
+}
+
+//Identify returns the caller of the call, nil when the caller can't be identified.
+func (a Authenticator) Identify(ctx context.Context) *Caller {
+	if commonName := certificateName(ctx); commonName == ServiceCommonName {
+		return &Caller{Role: RoleService}
+	}
+
+	token := bearerToken(ctx)
+	if a.ServiceToken != "" && token != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(a.ServiceToken)) == 1 {
+		return &Caller{Role: RoleService}
+	}
+	return nil
+}
+
+//UnaryInterceptor authenticates the unary calls and checks that the caller's role is allowed to call the method.
+func UnaryInterceptor(authenticator Authenticator, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		caller := authenticator.Identify(ctx)
+		if caller == nil {
+			return nil, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+		}
+		if !policy.Allowed(info.FullMethod, caller) {
+			return nil, status.Errorf(codes.PermissionDenied, "role %q can't call %v", caller.Role,
+				info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+//certificateName returns the common name of the verified client certificate, empty without mutual TLS.
+func certificateName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return info.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get(authorizationHeader) {
+		if strings.HasPrefix(value, "Bearer ") {
+			return strings.TrimPrefix(value, "Bearer ")
+		}
+	}
+	return ""
+}
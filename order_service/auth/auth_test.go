@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+const testMethod = "/proto.OrderService/TopUp"
+
+func withCertificate(ctx context.Context, commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}})
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationHeader, "Bearer "+token))
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	policy := Policy{testMethod: {RoleService}}
+	authenticator := Authenticator{ServiceToken: "secret"}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{name: "anonymous", ctx: context.Background(), want: codes.Unauthenticated},
+		{name: "service token", ctx: withToken(context.Background(), "secret"), want: codes.OK},
+		{name: "wrong token", ctx: withToken(context.Background(), "guess"), want: codes.Unauthenticated},
+		{name: "service certificate", ctx: withCertificate(context.Background(), ServiceCommonName),
+			want: codes.OK},
+		{name: "scooter certificate", ctx: withCertificate(context.Background(), "scooter-5"),
+			want: codes.Unauthenticated},
+		{name: "method not in policy", ctx: withToken(context.Background(), "secret"),
+			method: "/proto.OrderService/Unknown", want: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = testMethod
+			}
+			called := false
+			_, err := UnaryInterceptor(authenticator, policy)(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+			if status.Code(err) != tt.want {
+				t.Fatalf("interceptor error = %v, want %v", err, tt.want)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Errorf("handler called = %v, want %v", called, tt.want == codes.OK)
+			}
+		})
+	}
+}
+
+func TestEmptyServiceTokenAuthenticatesNobody(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, "Bearer "))
+	if caller := (Authenticator{}).Identify(ctx); caller != nil {
+		t.Errorf("Identify() = %+v with the empty service token, want nil", caller)
+	}
+}
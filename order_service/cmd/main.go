@@ -9,11 +9,18 @@ import (
 	"google.golang.org/grpc/reflection"
 	"log"
 	"net"
+	"order_micro/auth"
 	"order_micro/config"
+	"order_micro/outbox"
+	"order_micro/passes"
+	"order_micro/payment"
+	"order_micro/pricing"
 	"order_micro/proto"
 	"order_micro/repository"
 	"order_micro/service"
+	"order_micro/tlsconfig"
 	"order_micro/transport"
+	"time"
 )
 
 const TopicName = "order"
@@ -37,7 +44,31 @@ func main() {
 	defer db.Close()
 
 	orderRepo := repository.NewOrderRepo(db)
-	service := service.NewOrderService(orderRepo)
+	tariff := pricing.Tariff{UnlockFee: config.UNLOCK_FEE, RidePerMinute: config.RIDE_RATE,
+		PausePerMinute: config.PAUSE_RATE, Deposit: config.TRIP_DEPOSIT}
+	ledgerRepo := repository.NewLedgerRepo(db)
+	catalog := passes.NewCatalog(
+		passes.Plan{Name: passes.PlanDay, Price: config.DAY_PASS_PRICE, Duration: 24 * time.Hour,
+			Minutes: config.DAY_PASS_MINUTES, UnlimitedUnlocks: true},
+		passes.Plan{Name: passes.PlanMonth, Price: config.MONTH_PASS_PRICE, Duration: 30 * 24 * time.Hour,
+			Minutes: config.MONTH_PASS_MINUTES})
+	payments, err := payment.New(config.PAYMENT_PROVIDER, config.APP_ENV == config.DevelopmentEnv)
+	if err != nil {
+		log.Panicf("%s: failed to create payment provider - %v", "order_micro", err)
+	}
+	orderService := service.NewOrderService(orderRepo, ledgerRepo, repository.NewPromoRepo(db),
+		repository.NewPassRepo(db), repository.NewReceiptRepo(db), payments, tariff, catalog)
+
+	producer := transport.CreateProducer([]string{config.KAFKA_BROKER}, ClientID)
+	for _, topic := range []string{TopicName, config.ORDER_EVENTS_TOPIC} {
+		err = transport.CreateTopic([]string{config.KAFKA_BROKER}, topic, int32(config.KAFKA_PARTITIONS), 1)
+		if err != nil {
+			log.Panicf("%s: failed to create kafka topic - %v", "order_micro", err)
+		}
+	}
+	relay := outbox.NewRelay(repository.NewOutboxRepo(db), producer, config.ORDER_EVENTS_TOPIC,
+		config.OUTBOX_INTERVAL, config.OUTBOX_BATCH_SIZE)
+	go relay.Run(context.Background())
 
 	group := transport.CreateConsumerGroup([]string{config.KAFKA_BROKER}, ClientID, GroupConsumer)
 
@@ -52,8 +83,20 @@ func main() {
 		log.Panicf("%s: failed to listen on port - %v","order_micro", err)
 	}
 
-	server := grpc.NewServer()
-	proto.RegisterOrderServiceServer(server, service)
+	serverCredentials, err := tlsconfig.ServerCredentials(tlsconfig.Config{CertFile: config.TLS_CERT_FILE,
+		KeyFile: config.TLS_KEY_FILE, CAFile: config.TLS_CA_FILE})
+	if err != nil {
+		log.Panicf("%s: failed to load TLS credentials - %v", "order_micro", err)
+	}
+
+	if config.SERVICE_TOKEN == "" && config.TLS_CA_FILE == "" {
+		log.Println("neither SERVICE_TOKEN nor TLS_CA_FILE is set, every OrderService call will be rejected")
+	}
+	authenticator := auth.Authenticator{ServiceToken: config.SERVICE_TOKEN}
+
+	server := grpc.NewServer(grpc.Creds(serverCredentials),
+		grpc.UnaryInterceptor(auth.UnaryInterceptor(authenticator, service.Policy)))
+	proto.RegisterOrderServiceServer(server, orderService)
 	reflection.Register(server)
 
 	if err := server.Serve(listener); err != nil {
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 var PG_HOST = getStringParameter("PG_HOST", "localhost")
@@ -12,6 +15,27 @@ var POSTGRES_PASSWORD = getStringParameter("POSTGRES_PASSWORD", "Megascooter!")
 var GRPC_PORT = getStringParameter("GRPC_PORT", "9000")
 var ORDER_GRPC_PORT = getStringParameter("ORDER_GRPC_PORT", "9999")
 var KAFKA_BROKER = getStringParameter("KAFKA_BROKER", "localhost:9093")
+var KAFKA_PARTITIONS = getUintParameter("KAFKA_PARTITIONS", 3)
+var UNLOCK_FEE = getUintParameter("UNLOCK_FEE", 1000)
+var RIDE_RATE = getUintParameter("RIDE_RATE", 300)
+var PAUSE_RATE = getUintParameter("PAUSE_RATE", 100)
+var TRIP_DEPOSIT = getUintParameter("TRIP_DEPOSIT", 2000)
+var DAY_PASS_PRICE = getUintParameter("DAY_PASS_PRICE", 1500)
+var DAY_PASS_MINUTES = getUintParameter("DAY_PASS_MINUTES", 90)
+var MONTH_PASS_PRICE = getUintParameter("MONTH_PASS_PRICE", 4900)
+var MONTH_PASS_MINUTES = getUintParameter("MONTH_PASS_MINUTES", 300)
+var TLS_CERT_FILE = getStringParameter("TLS_CERT_FILE", "")
+var TLS_KEY_FILE = getStringParameter("TLS_KEY_FILE", "")
+var TLS_CA_FILE = getStringParameter("TLS_CA_FILE", "")
+var SERVICE_TOKEN = getStringParameter("SERVICE_TOKEN", "")
+var ORDER_EVENTS_TOPIC = getStringParameter("ORDER_EVENTS_TOPIC", "order_events")
+var OUTBOX_INTERVAL = getDurationParameter("OUTBOX_INTERVAL", time.Second)
+var OUTBOX_BATCH_SIZE = getUintParameter("OUTBOX_BATCH_SIZE", 100)
+var APP_ENV = getStringParameter("APP_ENV", "production")
+var PAYMENT_PROVIDER = getStringParameter("PAYMENT_PROVIDER", "")
+
+//DevelopmentEnv is the APP_ENV of the local development, it allows the fake payment provider.
+const DevelopmentEnv = "development"
 
 func getStringParameter(paramName, defaultValue string) string {
 	result, ok := os.LookupEnv(paramName)
@@ -20,3 +44,29 @@ func getStringParameter(paramName, defaultValue string) string {
 	}
 	return result
 }
+
+func getUintParameter(paramName string, defaultValue uint64) uint64 {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
+
+func getDurationParameter(paramName string, defaultValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
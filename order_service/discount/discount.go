@@ -0,0 +1,103 @@
+package discount
+
+import (
+	"errors"
+	"fmt"
+	"order_micro/proto"
+	"strings"
+	"time"
+)
+
+const (
+	KindPercent    = "percent"
+	KindFixed      = "fixed"
+	KindFreeUnlock = "free_unlock"
+)
+
+var (
+	ErrInvalidPromo     = errors.New("invalid promo code")
+	ErrPromoNotStarted  = errors.New("promo code isn't active yet")
+	ErrPromoExpired     = errors.New("promo code has expired")
+	ErrLimitReached     = errors.New("promo code has been redeemed the maximum number of times")
+	ErrUserLimitReached = errors.New("promo code has already been used by the user")
+	ErrNotFirstRide     = errors.New("promo code is valid only for the first ride")
+)
+
+//Usage holds the number of the times the promo code has been redeemed by everybody and by the user,
+//and the number of the previous orders of the user.
+type Usage struct {
+	Total          uint64
+	ByUser         uint64
+	PreviousOrders uint64
+}
+
+//Normalize returns the code in the form it is stored, codes are case-insensitive.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+//Validate checks that the promo code can be created.
+func Validate(promo *proto.Promo) error {
+	if Normalize(promo.Code) == "" {
+		return fmt.Errorf("%w: code is empty", ErrInvalidPromo)
+	}
+	switch promo.Kind {
+	case KindPercent:
+		if promo.Value == 0 || promo.Value > 100 {
+			return fmt.Errorf("%w: percent must be from 1 to 100", ErrInvalidPromo)
+		}
+	case KindFixed:
+		if promo.Value == 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidPromo)
+		}
+	case KindFreeUnlock:
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidPromo, promo.Kind)
+	}
+	if promo.ValidFrom != nil && promo.ValidUntil != nil &&
+		!promo.ValidUntil.AsTime().After(promo.ValidFrom.AsTime()) {
+		return fmt.Errorf("%w: validity window is empty", ErrInvalidPromo)
+	}
+	return nil
+}
+
+//Check reports why the promo code can't be redeemed now with the given usage.
+func Check(promo *proto.Promo, now time.Time, usage Usage) error {
+	if promo.ValidFrom != nil && now.Before(promo.ValidFrom.AsTime()) {
+		return ErrPromoNotStarted
+	}
+	if promo.ValidUntil != nil && !now.Before(promo.ValidUntil.AsTime()) {
+		return ErrPromoExpired
+	}
+	if promo.MaxRedemptions != 0 && usage.Total >= uint64(promo.MaxRedemptions) {
+		return ErrLimitReached
+	}
+	if promo.PerUserLimit != 0 && usage.ByUser >= uint64(promo.PerUserLimit) {
+		return ErrUserLimitReached
+	}
+	if promo.FirstRideOnly && usage.PreviousOrders > 0 {
+		return ErrNotFirstRide
+	}
+	return nil
+}
+
+//Apply reduces the total of the price by the promo code. The discount never exceeds the price.
+func Apply(promo *proto.Promo, price *proto.PriceBreakdown) {
+	full := price.Unlock + price.Ride + price.Pause
+
+	var discount uint64
+	switch promo.Kind {
+	case KindPercent:
+		discount = full * promo.Value / 100
+	case KindFixed:
+		discount = promo.Value
+	case KindFreeUnlock:
+		discount = price.Unlock
+	}
+	if discount > full {
+		discount = full
+	}
+
+	price.Discount = discount
+	price.Total = full - discount
+}
@@ -0,0 +1,98 @@
+package discount
+
+import (
+	"errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/proto"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		promo   *proto.Promo
+		wantErr error
+	}{
+		{name: "percent", promo: &proto.Promo{Code: "summer", Kind: KindPercent, Value: 20}},
+		{name: "fixed", promo: &proto.Promo{Code: "bonus", Kind: KindFixed, Value: 500}},
+		{name: "free unlock", promo: &proto.Promo{Code: "unlock", Kind: KindFreeUnlock}},
+		{name: "empty code", promo: &proto.Promo{Code: "  ", Kind: KindFreeUnlock}, wantErr: ErrInvalidPromo},
+		{name: "zero percent", promo: &proto.Promo{Code: "a", Kind: KindPercent}, wantErr: ErrInvalidPromo},
+		{name: "percent above 100", promo: &proto.Promo{Code: "a", Kind: KindPercent, Value: 101},
+			wantErr: ErrInvalidPromo},
+		{name: "zero amount", promo: &proto.Promo{Code: "a", Kind: KindFixed}, wantErr: ErrInvalidPromo},
+		{name: "unknown kind", promo: &proto.Promo{Code: "a", Kind: "gift"}, wantErr: ErrInvalidPromo},
+		{name: "empty window", promo: &proto.Promo{Code: "a", Kind: KindFreeUnlock,
+			ValidFrom: timestamppb.New(now), ValidUntil: timestamppb.New(now)}, wantErr: ErrInvalidPromo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.promo)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		promo   *proto.Promo
+		usage   Usage
+		wantErr error
+	}{
+		{name: "unlimited", promo: &proto.Promo{}, usage: Usage{Total: 1000, ByUser: 10, PreviousOrders: 5}},
+		{name: "inside window", promo: &proto.Promo{ValidFrom: timestamppb.New(now.Add(-time.Hour)),
+			ValidUntil: timestamppb.New(now.Add(time.Hour))}},
+		{name: "not started", promo: &proto.Promo{ValidFrom: timestamppb.New(now.Add(time.Minute))},
+			wantErr: ErrPromoNotStarted},
+		{name: "expired", promo: &proto.Promo{ValidUntil: timestamppb.New(now)}, wantErr: ErrPromoExpired},
+		{name: "below limit", promo: &proto.Promo{MaxRedemptions: 10}, usage: Usage{Total: 9}},
+		{name: "limit reached", promo: &proto.Promo{MaxRedemptions: 10}, usage: Usage{Total: 10},
+			wantErr: ErrLimitReached},
+		{name: "user limit reached", promo: &proto.Promo{PerUserLimit: 1}, usage: Usage{ByUser: 1},
+			wantErr: ErrUserLimitReached},
+		{name: "first ride", promo: &proto.Promo{FirstRideOnly: true}},
+		{name: "not first ride", promo: &proto.Promo{FirstRideOnly: true}, usage: Usage{PreviousOrders: 1},
+			wantErr: ErrNotFirstRide},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.promo, now, tt.usage)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name         string
+		promo        *proto.Promo
+		wantDiscount uint64
+		wantTotal    uint64
+	}{
+		{name: "percent", promo: &proto.Promo{Kind: KindPercent, Value: 25}, wantDiscount: 110, wantTotal: 330},
+		{name: "fixed", promo: &proto.Promo{Kind: KindFixed, Value: 200}, wantDiscount: 200, wantTotal: 240},
+		{name: "fixed above price", promo: &proto.Promo{Kind: KindFixed, Value: 1000}, wantDiscount: 440},
+		{name: "free unlock", promo: &proto.Promo{Kind: KindFreeUnlock}, wantDiscount: 100, wantTotal: 340},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := &proto.PriceBreakdown{Unlock: 100, Ride: 300, Pause: 40, Total: 440}
+			Apply(tt.promo, price)
+			if price.Discount != tt.wantDiscount || price.Total != tt.wantTotal {
+				t.Errorf("Apply() discount = %d, total = %d, want %d, %d",
+					price.Discount, price.Total, tt.wantDiscount, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"errors"
+	"order_micro/orderstate"
+	"order_micro/proto"
+	"order_micro/repository"
+	"testing"
+)
+
+func TestOrderEventsAreKnown(t *testing.T) {
+	types := []string{repository.EventOrderCreated}
+	for _, state := range []string{orderstate.Started, orderstate.InProgress, orderstate.Completed,
+		orderstate.Cancelled, orderstate.Refunded, orderstate.Disputed} {
+		types = append(types, repository.OrderStateEvent(state))
+	}
+
+	for _, eventType := range types {
+		err := Check(&proto.EventEnvelope{EventType: eventType, SchemaVersion: OrderSchemaVersion})
+		if err != nil {
+			t.Errorf("order event published by the outbox is rejected: %v", err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope *proto.EventEnvelope
+		want     error
+	}{
+		{name: "latest", envelope: &proto.EventEnvelope{EventType: TypeTripEnded, SchemaVersion: 1}},
+		{name: "legacy", envelope: &proto.EventEnvelope{EventType: TypeScooterStatusReported}},
+		{name: "newer", envelope: &proto.EventEnvelope{EventType: TypeTripEnded, SchemaVersion: 2},
+			want: ErrUnsupportedVersion},
+		{name: "unknown", envelope: &proto.EventEnvelope{EventType: "scooter.exploded"}, want: ErrUnknownEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.envelope)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Check error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS ride_seconds  BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pause_seconds BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS unlock_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ride_amount   BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pause_amount  BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS total_amount  BIGINT NOT NULL DEFAULT 0;
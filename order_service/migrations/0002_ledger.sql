@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS ledger_accounts
+(
+    id      SERIAL PRIMARY KEY,
+    user_id INT         NOT NULL DEFAULT 0,
+    kind    VARCHAR(16) NOT NULL,
+    balance BIGINT      NOT NULL DEFAULT 0,
+    UNIQUE (user_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_transactions
+(
+    id         SERIAL PRIMARY KEY,
+    kind       VARCHAR(16) NOT NULL,
+    user_id    INT         NOT NULL,
+    reference  VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries
+(
+    id             SERIAL PRIMARY KEY,
+    transaction_id INT    NOT NULL REFERENCES ledger_transactions (id),
+    account_id     INT    NOT NULL REFERENCES ledger_accounts (id),
+    amount         BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_account_id ON ledger_entries (account_id);
+
+CREATE TABLE IF NOT EXISTS holds
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    INT         NOT NULL,
+    amount     BIGINT      NOT NULL,
+    state      VARCHAR(16) NOT NULL,
+    order_id   INT,
+    created_at TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS hold_id INT;
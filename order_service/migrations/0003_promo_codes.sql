@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS promo_codes
+(
+    id              SERIAL PRIMARY KEY,
+    code            VARCHAR(32) NOT NULL UNIQUE,
+    kind            VARCHAR(16) NOT NULL,
+    value           BIGINT      NOT NULL DEFAULT 0,
+    first_ride_only BOOLEAN     NOT NULL DEFAULT FALSE,
+    max_redemptions INT         NOT NULL DEFAULT 0,
+    per_user_limit  INT         NOT NULL DEFAULT 0,
+    valid_from      TIMESTAMP,
+    valid_until     TIMESTAMP,
+    created_at      TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS promo_redemptions
+(
+    id         SERIAL PRIMARY KEY,
+    promo_id   INT       NOT NULL REFERENCES promo_codes (id),
+    user_id    INT       NOT NULL,
+    order_id   INT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS promo_redemptions_promo_id ON promo_redemptions (promo_id, user_id);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS discount_amount BIGINT      NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS promo_code      VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS passes
+(
+    id                SERIAL PRIMARY KEY,
+    user_id           INT         NOT NULL,
+    plan              VARCHAR(16) NOT NULL,
+    minutes_total     BIGINT      NOT NULL DEFAULT 0,
+    minutes_used      BIGINT      NOT NULL DEFAULT 0 CHECK (minutes_used <= minutes_total),
+    unlimited_unlocks BOOLEAN     NOT NULL DEFAULT FALSE,
+    starts_at         TIMESTAMP   NOT NULL,
+    expires_at        TIMESTAMP   NOT NULL,
+    created_at        TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS passes_user_id ON passes (user_id, expires_at);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS pass_id      INT,
+    ADD COLUMN IF NOT EXISTS pass_minutes BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS state           VARCHAR(16) NOT NULL DEFAULT 'completed',
+    ADD COLUMN IF NOT EXISTS refunded_amount BIGINT      NOT NULL DEFAULT 0,
+    ALTER COLUMN status_start_id DROP NOT NULL,
+    ALTER COLUMN status_end_id DROP NOT NULL;
+
+CREATE TABLE IF NOT EXISTS order_transitions
+(
+    id         SERIAL PRIMARY KEY,
+    order_id   INT         NOT NULL REFERENCES orders (id),
+    from_state VARCHAR(16) NOT NULL DEFAULT '',
+    to_state   VARCHAR(16) NOT NULL,
+    actor      VARCHAR(64) NOT NULL DEFAULT '',
+    reason     TEXT        NOT NULL DEFAULT '',
+    amount     BIGINT      NOT NULL DEFAULT 0,
+    date_time  TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_transitions_order_id ON order_transitions (order_id);
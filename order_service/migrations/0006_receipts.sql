@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS receipts
+(
+    order_id   INT PRIMARY KEY REFERENCES orders (id),
+    text       TEXT      NOT NULL,
+    html       TEXT      NOT NULL,
+    json       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS distance DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id           BIGSERIAL PRIMARY KEY,
+    aggregate_id INT         NOT NULL,
+    event_type   VARCHAR(32) NOT NULL,
+    payload      BYTEA       NOT NULL,
+    created_at   TIMESTAMP   NOT NULL DEFAULT now(),
+    published_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished ON outbox (id) WHERE published_at IS NULL;
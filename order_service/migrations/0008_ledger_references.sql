@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS ledger_transactions_reference ON ledger_transactions (reference);
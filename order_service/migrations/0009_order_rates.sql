@@ -0,0 +1,14 @@
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS ride_rate  BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pause_rate BIGINT NOT NULL DEFAULT 0;
+
+-- The orders priced before the rates were stored get them back from their amounts and billed minutes.
+UPDATE orders
+SET ride_rate = ride_amount / ((ride_seconds + 59) / 60 - pass_minutes)
+WHERE ride_rate = 0
+  AND (ride_seconds + 59) / 60 > pass_minutes;
+
+UPDATE orders
+SET pause_rate = pause_amount / ((pause_seconds + 59) / 60)
+WHERE pause_rate = 0
+  AND pause_seconds > 0;
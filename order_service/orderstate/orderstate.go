@@ -0,0 +1,39 @@
+package orderstate
+
+import (
+	"errors"
+	"fmt"
+)
+
+//States of the order. The order is started when the trip deposit is held, it is in progress when the scooter
+//is unlocked and completed when the trip is priced and paid.
+const (
+	Started    = "started"
+	InProgress = "in_progress"
+	Completed  = "completed"
+	Cancelled  = "cancelled"
+	Refunded   = "refunded"
+	Disputed   = "disputed"
+)
+
+var ErrInvalidTransition = errors.New("order can't be moved to this state")
+
+//transitions lists the states each order state can be moved to. The order in progress is completed when its trip
+//is ended, so only the started order can be cancelled. The partially refunded order can be refunded again.
+var transitions = map[string][]string{
+	Started:    {InProgress, Cancelled},
+	InProgress: {Completed},
+	Completed:  {Refunded, Disputed},
+	Disputed:   {Refunded, Completed},
+	Refunded:   {Refunded},
+}
+
+//Check returns ErrInvalidTransition if the order can't be moved from one state to another.
+func Check(from, to string) error {
+	for _, state := range transitions[from] {
+		if state == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: from %q to %q", ErrInvalidTransition, from, to)
+}
@@ -0,0 +1,39 @@
+package orderstate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		wantErr error
+	}{
+		{from: Started, to: InProgress},
+		{from: Started, to: Cancelled},
+		{from: InProgress, to: Completed},
+		{from: Completed, to: Refunded},
+		{from: Completed, to: Disputed},
+		{from: Disputed, to: Refunded},
+		{from: Disputed, to: Completed},
+		{from: Refunded, to: Refunded},
+		{from: Started, to: Completed, wantErr: ErrInvalidTransition},
+		{from: InProgress, to: Cancelled, wantErr: ErrInvalidTransition},
+		{from: InProgress, to: Refunded, wantErr: ErrInvalidTransition},
+		{from: Completed, to: Cancelled, wantErr: ErrInvalidTransition},
+		{from: Cancelled, to: InProgress, wantErr: ErrInvalidTransition},
+		{from: Refunded, to: Completed, wantErr: ErrInvalidTransition},
+		{from: "unknown", to: Started, wantErr: ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			err := Check(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check(%q, %q) error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package outbox
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"order_micro/events"
+	"order_micro/proto"
+	"order_micro/repository"
+	"strconv"
+	"time"
+)
+
+//Source is the source of the events published by the relay.
+const Source = "order_service"
+
+//Relay publishes the events written to the outbox to Kafka. The events are keyed by the order ID,
+//so the events of an order get into the same partition in the order they were written.
+//Each order is wrapped into the event envelope with the outbox ID as the event ID.
+type Relay struct {
+	Repo      repository.OutboxRepository
+	Producer  sarama.SyncProducer
+	Topic     string
+	Interval  time.Duration
+	BatchSize uint64
+}
+
+//NewRelay creates a new Relay which polls the outbox every interval.
+func NewRelay(repo repository.OutboxRepository, producer sarama.SyncProducer, topic string, interval time.Duration,
+	batchSize uint64) *Relay {
+	return &Relay{
+		Repo:      repo,
+		Producer:  producer,
+		Topic:     topic,
+		Interval:  interval,
+		BatchSize: batchSize,
+	}
+}
+
+//Run publishes the events until the context is done. Full batches are followed by the next one without waiting.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.relayBatch(ctx)
+		if err != nil {
+			log.Printf("outbox relay: %v\n", err)
+		}
+		if err == nil && uint64(published) == r.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//relayBatch publishes the next batch of the outbox events and returns the number of the published ones.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	return r.Repo.Relay(ctx, r.BatchSize, r.publish)
+}
+
+//publish sends the events one by one and stops at the first failure, so a later event of an order
+//is never published before an earlier one.
+func (r *Relay) publish(outboxEvents []repository.OutboxEvent) (int, error) {
+	for i, event := range outboxEvents {
+		envelope := &proto.EventEnvelope{EventID: strconv.FormatUint(event.ID, 10), EventType: event.Type,
+			SchemaVersion: events.OrderSchemaVersion, OccurredAt: timestamppb.New(event.CreatedAt),
+			Source: Source, Payload: event.Payload}
+		message, err := events.Message(r.Topic, strconv.FormatUint(event.AggregateID, 10), envelope)
+		if err != nil {
+			return i, err
+		}
+
+		_, _, err = r.Producer.SendMessage(message)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(outboxEvents), nil
+}
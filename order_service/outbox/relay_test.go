@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/Shopify/sarama"
+	protobuf "google.golang.org/protobuf/proto"
+	"order_micro/events"
+	"order_micro/proto"
+	"order_micro/repository"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+//outboxStub keeps the events like the outbox table: the unpublished events are relayed in the ID order
+//and the number of the events reported by publish is marked as published.
+type outboxStub struct {
+	events    []repository.OutboxEvent
+	published map[uint64]bool
+}
+
+func newOutboxStub(events ...repository.OutboxEvent) *outboxStub {
+	return &outboxStub{events: events, published: make(map[uint64]bool)}
+}
+
+func (stub *outboxStub) Relay(ctx context.Context, limit uint64,
+	publish func(events []repository.OutboxEvent) (int, error)) (int, error) {
+	var unpublished []repository.OutboxEvent
+	for _, event := range stub.events {
+		if !stub.published[event.ID] {
+			unpublished = append(unpublished, event)
+		}
+	}
+	sort.Slice(unpublished, func(i, j int) bool { return unpublished[i].ID < unpublished[j].ID })
+	if uint64(len(unpublished)) > limit {
+		unpublished = unpublished[:limit]
+	}
+	if len(unpublished) == 0 {
+		return 0, nil
+	}
+
+	published, err := publish(unpublished)
+	for _, event := range unpublished[:published] {
+		stub.published[event.ID] = true
+	}
+	return published, err
+}
+
+var errBrokerDown = errors.New("broker is down")
+
+//producerStub records the sent messages and fails the sends after the given number of messages.
+type producerStub struct {
+	messages []*sarama.ProducerMessage
+	failFrom int
+}
+
+func (ps *producerStub) SendMessage(message *sarama.ProducerMessage) (int32, int64, error) {
+	if ps.failFrom >= 0 && len(ps.messages) >= ps.failFrom {
+		return 0, 0, errBrokerDown
+	}
+	ps.messages = append(ps.messages, message)
+	return 0, int64(len(ps.messages)), nil
+}
+
+func (ps *producerStub) SendMessages(messages []*sarama.ProducerMessage) error {
+	for _, message := range messages {
+		if _, _, err := ps.SendMessage(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ps *producerStub) Close() error {
+	return nil
+}
+
+//sent returns the event IDs and the keys of the sent messages.
+func (ps *producerStub) sent(t *testing.T) (ids, keys []string) {
+	for _, message := range ps.messages {
+		value, err := message.Value.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope := &proto.EventEnvelope{}
+		err = protobuf.Unmarshal(value, envelope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := message.Key.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, envelope.EventID)
+		keys = append(keys, string(key))
+	}
+	return ids, keys
+}
+
+func outboxEvents() []repository.OutboxEvent {
+	created := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	return []repository.OutboxEvent{
+		{ID: 3, AggregateID: 10, Type: events.TypeOrderInProgress, CreatedAt: created.Add(2 * time.Second)},
+		{ID: 1, AggregateID: 10, Type: events.TypeOrderCreated, CreatedAt: created},
+		{ID: 2, AggregateID: 11, Type: events.TypeOrderCreated, CreatedAt: created.Add(time.Second)},
+		{ID: 4, AggregateID: 12, Type: events.TypeOrderCreated, CreatedAt: created.Add(3 * time.Second)},
+	}
+}
+
+func TestRelayPublishesInOrder(t *testing.T) {
+	repo := newOutboxStub(outboxEvents()...)
+	producer := &producerStub{failFrom: -1}
+	relay := NewRelay(repo, producer, "order_events", time.Second, 10)
+
+	published, err := relay.relayBatch(context.Background())
+	if err != nil || published != 4 {
+		t.Fatalf("relayBatch() = %d, %v, want 4 events published", published, err)
+	}
+
+	ids, keys := producer.sent(t)
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("published event IDs = %v, want %v", ids, want)
+	}
+	if want := []string{"10", "11", "10", "12"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("message keys = %v, want the order IDs %v", keys, want)
+	}
+	for _, message := range producer.messages {
+		if message.Topic != "order_events" {
+			t.Errorf("message published to %q, want %q", message.Topic, "order_events")
+		}
+	}
+}
+
+func TestRelayStopsAtFirstFailure(t *testing.T) {
+	repo := newOutboxStub(outboxEvents()...)
+	producer := &producerStub{failFrom: 2}
+	relay := NewRelay(repo, producer, "order_events", time.Second, 10)
+
+	published, err := relay.relayBatch(context.Background())
+	if !errors.Is(err, errBrokerDown) || published != 2 {
+		t.Fatalf("relayBatch() = %d, %v, want 2 events published and %v", published, err, errBrokerDown)
+	}
+	if want := map[uint64]bool{1: true, 2: true}; !reflect.DeepEqual(repo.published, want) {
+		t.Errorf("marked as published %v, want only the published prefix %v", repo.published, want)
+	}
+
+	producer.failFrom = -1
+	published, err = relay.relayBatch(context.Background())
+	if err != nil || published != 2 {
+		t.Fatalf("relayBatch() after the failure = %d, %v, want the 2 remaining events published", published, err)
+	}
+	ids, _ := producer.sent(t)
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("published event IDs = %v, want %v", ids, want)
+	}
+}
+
+func TestRelayBatchSize(t *testing.T) {
+	repo := newOutboxStub(outboxEvents()...)
+	producer := &producerStub{failFrom: -1}
+	relay := NewRelay(repo, producer, "order_events", time.Second, 3)
+
+	published, err := relay.relayBatch(context.Background())
+	if err != nil || published != 3 {
+		t.Fatalf("relayBatch() = %d, %v, want a full batch of 3", published, err)
+	}
+	if repo.published[4] {
+		t.Error("event beyond the batch is marked as published")
+	}
+}
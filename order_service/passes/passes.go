@@ -0,0 +1,56 @@
+package passes
+
+import (
+	"errors"
+	"fmt"
+	"order_micro/proto"
+	"time"
+)
+
+const (
+	PlanDay   = "day"
+	PlanMonth = "month"
+)
+
+var ErrUnknownPlan = errors.New("unknown pass plan")
+
+//Plan is a kind of the pass. The pass is valid for the Duration from its purchase, the riding minutes
+//are free until the included Minutes are used, with UnlimitedUnlocks the unlock fee isn't charged.
+type Plan struct {
+	Name             string
+	Price            uint64
+	Duration         time.Duration
+	Minutes          uint64
+	UnlimitedUnlocks bool
+}
+
+//Catalog holds the plans which can be bought.
+type Catalog struct {
+	plans []Plan
+}
+
+//NewCatalog creates a new Catalog with the given plans.
+func NewCatalog(plans ...Plan) *Catalog {
+	return &Catalog{plans: plans}
+}
+
+//Plan returns the plan by its name.
+func (c *Catalog) Plan(name string) (Plan, error) {
+	for _, plan := range c.plans {
+		if plan.Name == name {
+			return plan, nil
+		}
+	}
+	return Plan{}, fmt.Errorf("%w: %q", ErrUnknownPlan, name)
+}
+
+//List returns the plans in the proto form.
+func (c *Catalog) List() *proto.PassPlanList {
+	list := &proto.PassPlanList{}
+	for _, plan := range c.plans {
+		list.Plans = append(list.Plans, &proto.PassPlan{Name: plan.Name, Price: plan.Price,
+			DurationHours: uint64(plan.Duration / time.Hour), Minutes: plan.Minutes,
+			UnlimitedUnlocks: plan.UnlimitedUnlocks})
+	}
+	return list
+}
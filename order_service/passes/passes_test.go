@@ -0,0 +1,41 @@
+package passes
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCatalogPlan(t *testing.T) {
+	catalog := NewCatalog(
+		Plan{Name: PlanDay, Price: 900, Duration: 24 * time.Hour, Minutes: 60},
+		Plan{Name: PlanMonth, Price: 9900, Duration: 30 * 24 * time.Hour, Minutes: 600, UnlimitedUnlocks: true},
+	)
+
+	plan, err := catalog.Plan(PlanMonth)
+	if err != nil {
+		t.Fatalf("Plan(%q) error = %v", PlanMonth, err)
+	}
+	if plan.Price != 9900 || !plan.UnlimitedUnlocks {
+		t.Errorf("Plan(%q) = %+v", PlanMonth, plan)
+	}
+
+	_, err = catalog.Plan("year")
+	if !errors.Is(err, ErrUnknownPlan) {
+		t.Errorf("Plan(%q) error = %v, want %v", "year", err, ErrUnknownPlan)
+	}
+}
+
+func TestCatalogList(t *testing.T) {
+	catalog := NewCatalog(Plan{Name: PlanDay, Price: 900, Duration: 24 * time.Hour, Minutes: 60})
+
+	list := catalog.List()
+	if len(list.Plans) != 1 {
+		t.Fatalf("List() returned %d plans, want 1", len(list.Plans))
+	}
+	plan := list.Plans[0]
+	if plan.Name != PlanDay || plan.Price != 900 || plan.DurationHours != 24 || plan.Minutes != 60 ||
+		plan.UnlimitedUnlocks {
+		t.Errorf("List() plan = %v", plan)
+	}
+}
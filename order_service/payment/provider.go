@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//ProviderFake is the name of the FakeProvider in the configuration.
+const ProviderFake = "fake"
+
+var (
+	ErrDeclined        = errors.New("payment declined")
+	ErrPaymentNotFound = errors.New("payment not found")
+	ErrNoProvider      = errors.New("no payment provider is configured")
+)
+
+//Provider charges the money from the payment method of the user. Amounts are in cents.
+type Provider interface {
+	//Charge takes the money from the user and returns the ID of the payment.
+	Charge(ctx context.Context, userID, amount uint64) (string, error)
+	//Refund returns the money of the payment to the user.
+	Refund(ctx context.Context, paymentID string, amount uint64) error
+}
+
+//FakeProvider is an in-process Provider which accepts every payment, it is used for the tests and the local
+//development only. Payments of the users from Declined are declined.
+type FakeProvider struct {
+	mu       sync.Mutex
+	nextID   uint64
+	payments map[string]uint64
+	Declined map[uint64]bool
+}
+
+//NewFakeProvider creates a new FakeProvider.
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{
+		payments: make(map[string]uint64),
+		Declined: make(map[uint64]bool),
+	}
+}
+
+//New returns the provider configured by its name. The FakeProvider doesn't take any real money, so it is
+//returned only in the development environment, any other environment needs a real provider.
+func New(name string, development bool) (Provider, error) {
+	switch name {
+	case ProviderFake:
+		if !development {
+			return nil, fmt.Errorf("%w: the %q provider is allowed only in the development environment",
+				ErrNoProvider, name)
+		}
+		return NewFakeProvider(), nil
+	case "":
+		return nil, ErrNoProvider
+	default:
+		return nil, fmt.Errorf("%w: unknown provider %q", ErrNoProvider, name)
+	}
+}
+
+func (fp *FakeProvider) Charge(ctx context.Context, userID, amount uint64) (string, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.Declined[userID] {
+		return "", ErrDeclined
+	}
+	fp.nextID++
+	id := fmt.Sprintf("fake-%d", fp.nextID)
+	fp.payments[id] = amount
+	return id, nil
+}
+
+func (fp *FakeProvider) Refund(ctx context.Context, paymentID string, amount uint64) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	charged, ok := fp.payments[paymentID]
+	if !ok {
+		return ErrPaymentNotFound
+	}
+	if amount > charged {
+		return fmt.Errorf("refund %d is more than the payment %d", amount, charged)
+	}
+	fp.payments[paymentID] = charged - amount
+	return nil
+}
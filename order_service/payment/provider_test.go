@@ -0,0 +1,61 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		provider    string
+		development bool
+		wantErr     error
+	}{
+		{name: "fake in development", provider: ProviderFake, development: true},
+		{name: "fake in production", provider: ProviderFake, wantErr: ErrNoProvider},
+		{name: "not configured", provider: "", development: true, wantErr: ErrNoProvider},
+		{name: "unknown", provider: "bank", development: true, wantErr: ErrNoProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.provider, tt.development)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New(%q, %v) error = %v, want %v", tt.provider, tt.development, err, tt.wantErr)
+			}
+			if err == nil && provider == nil {
+				t.Fatal("New returned no provider")
+			}
+		})
+	}
+}
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider()
+	provider.Declined[2] = true
+
+	_, err := provider.Charge(ctx, 2, 100)
+	if !errors.Is(err, ErrDeclined) {
+		t.Fatalf("Charge of the declined user error = %v, want %v", err, ErrDeclined)
+	}
+
+	id, err := provider.Charge(ctx, 1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.Refund(ctx, id, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = provider.Refund(ctx, id, 50)
+	if err == nil {
+		t.Error("Refund above the rest of the payment succeeded")
+	}
+	err = provider.Refund(ctx, "unknown", 1)
+	if !errors.Is(err, ErrPaymentNotFound) {
+		t.Errorf("Refund of the unknown payment error = %v, want %v", err, ErrPaymentNotFound)
+	}
+}
@@ -0,0 +1,94 @@
+package pricing
+
+import (
+	"order_micro/proto"
+	"sort"
+	"time"
+)
+
+const (
+	StateActive = "active"
+	StatePaused = "paused"
+	StateEnded  = "ended"
+)
+
+//Tariff holds the prices of the trip in cents. Deposit is the money held at the trip start.
+type Tariff struct {
+	UnlockFee      uint64
+	RidePerMinute  uint64
+	PausePerMinute uint64
+	Deposit        uint64
+}
+
+//Durations splits the trip time into the riding and the paused time by the trip events.
+//Every interval between two events is counted by the state of the earlier event.
+func Durations(events []*proto.TripEvent) (ride, pause time.Duration) {
+	sorted := append([]*proto.TripEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].DateTime.AsTime().Before(sorted[j].DateTime.AsTime())
+	})
+
+	for i := 0; i+1 < len(sorted); i++ {
+		elapsed := sorted[i+1].DateTime.AsTime().Sub(sorted[i].DateTime.AsTime())
+		switch sorted[i].State {
+		case StateActive:
+			ride += elapsed
+		case StatePaused:
+			pause += elapsed
+		}
+	}
+	return ride, pause
+}
+
+//Allowance is the part of the trip covered by the pass of the rider.
+type Allowance struct {
+	Minutes    uint64
+	FreeUnlock bool
+}
+
+//Price calculates the price of the trip. Riding and paused time are billed separately for every started minute.
+func (t Tariff) Price(ride, pause time.Duration) *proto.PriceBreakdown {
+	return t.PriceWithAllowance(ride, pause, Allowance{})
+}
+
+//PriceWithAllowance calculates the price of the trip, the riding minutes and the unlock covered by the allowance
+//are free. The price keeps the per minute rates, so the order can be explained after the tariff changes.
+func (t Tariff) PriceWithAllowance(ride, pause time.Duration, allowance Allowance) *proto.PriceBreakdown {
+	rideMinutes := minutes(ride)
+	passMinutes := allowance.Minutes
+	if passMinutes > rideMinutes {
+		passMinutes = rideMinutes
+	}
+
+	price := &proto.PriceBreakdown{
+		Unlock:         t.UnlockFee,
+		Ride:           (rideMinutes - passMinutes) * t.RidePerMinute,
+		Pause:          minutes(pause) * t.PausePerMinute,
+		PassMinutes:    passMinutes,
+		RidePerMinute:  t.RidePerMinute,
+		PausePerMinute: t.PausePerMinute,
+	}
+	if allowance.FreeUnlock {
+		price.Unlock = 0
+	}
+	price.Total = price.Unlock + price.Ride + price.Pause
+	return price
+}
+
+//RideMinutes returns the number of the billed riding minutes.
+func RideMinutes(ride time.Duration) uint64 {
+	return minutes(ride)
+}
+
+//PauseMinutes returns the number of the billed paused minutes.
+func PauseMinutes(pause time.Duration) uint64 {
+	return minutes(pause)
+}
+
+//minutes returns the number of started minutes of the duration.
+func minutes(d time.Duration) uint64 {
+	if d <= 0 {
+		return 0
+	}
+	return uint64((d + time.Minute - 1) / time.Minute)
+}
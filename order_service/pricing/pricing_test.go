@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/proto"
+	"testing"
+	"time"
+)
+
+func TestDurations(t *testing.T) {
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	event := func(state string, offset time.Duration) *proto.TripEvent {
+		return &proto.TripEvent{State: state, DateTime: timestamppb.New(start.Add(offset))}
+	}
+
+	tests := []struct {
+		name      string
+		events    []*proto.TripEvent
+		wantRide  time.Duration
+		wantPause time.Duration
+	}{
+		{name: "no events"},
+		{name: "not ended", events: []*proto.TripEvent{event(StateActive, 0)}},
+		{name: "ride only", events: []*proto.TripEvent{
+			event(StateActive, 0),
+			event(StateEnded, 12*time.Minute),
+		}, wantRide: 12 * time.Minute},
+		{name: "ride and pause", events: []*proto.TripEvent{
+			event(StateActive, 0),
+			event(StatePaused, 5*time.Minute),
+			event(StateActive, 8*time.Minute),
+			event(StateEnded, 20*time.Minute),
+		}, wantRide: 17 * time.Minute, wantPause: 3 * time.Minute},
+		{name: "unsorted", events: []*proto.TripEvent{
+			event(StateEnded, 20*time.Minute),
+			event(StateActive, 8*time.Minute),
+			event(StateActive, 0),
+			event(StatePaused, 5*time.Minute),
+		}, wantRide: 17 * time.Minute, wantPause: 3 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ride, pause := Durations(tt.events)
+			if ride != tt.wantRide || pause != tt.wantPause {
+				t.Errorf("Durations() = %v, %v, want %v, %v", ride, pause, tt.wantRide, tt.wantPause)
+			}
+		})
+	}
+}
+
+func TestPrice(t *testing.T) {
+	tariff := Tariff{UnlockFee: 100, RidePerMinute: 30, PausePerMinute: 10}
+
+	tests := []struct {
+		name  string
+		ride  time.Duration
+		pause time.Duration
+		want  *proto.PriceBreakdown
+	}{
+		{name: "empty trip", want: &proto.PriceBreakdown{Unlock: 100, Total: 100}},
+		{name: "negative duration", ride: -time.Minute, want: &proto.PriceBreakdown{Unlock: 100, Total: 100}},
+		{name: "started minutes", ride: 10*time.Minute + time.Second, pause: 30 * time.Second,
+			want: &proto.PriceBreakdown{Unlock: 100, Ride: 330, Pause: 10, Total: 440}},
+		{name: "whole minutes", ride: 10 * time.Minute, pause: 2 * time.Minute,
+			want: &proto.PriceBreakdown{Unlock: 100, Ride: 300, Pause: 20, Total: 420}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tariff.Price(tt.ride, tt.pause)
+			if got.Unlock != tt.want.Unlock || got.Ride != tt.want.Ride || got.Pause != tt.want.Pause ||
+				got.Total != tt.want.Total {
+				t.Errorf("Price() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceWithAllowance(t *testing.T) {
+	tariff := Tariff{UnlockFee: 100, RidePerMinute: 30, PausePerMinute: 10}
+
+	tests := []struct {
+		name      string
+		ride      time.Duration
+		pause     time.Duration
+		allowance Allowance
+		want      *proto.PriceBreakdown
+	}{
+		{name: "empty trip", want: &proto.PriceBreakdown{Unlock: 100, Total: 100}},
+		{name: "started minutes", ride: 10*time.Minute + time.Second, pause: 30 * time.Second,
+			want: &proto.PriceBreakdown{Unlock: 100, Ride: 330, Pause: 10, Total: 440}},
+		{name: "whole minutes", ride: 10 * time.Minute, pause: 2 * time.Minute,
+			want: &proto.PriceBreakdown{Unlock: 100, Ride: 300, Pause: 20, Total: 420}},
+		{name: "pass minutes", ride: 10 * time.Minute, allowance: Allowance{Minutes: 4},
+			want: &proto.PriceBreakdown{Unlock: 100, Ride: 180, PassMinutes: 4, Total: 280}},
+		{name: "pass minutes above ride", ride: 10 * time.Minute, pause: time.Minute, allowance: Allowance{Minutes: 60},
+			want: &proto.PriceBreakdown{Unlock: 100, Pause: 10, PassMinutes: 10, Total: 110}},
+		{name: "free unlock", ride: 10 * time.Minute, allowance: Allowance{FreeUnlock: true},
+			want: &proto.PriceBreakdown{Ride: 300, Total: 300}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tariff.PriceWithAllowance(tt.ride, tt.pause, tt.allowance)
+			if got.Unlock != tt.want.Unlock || got.Ride != tt.want.Ride || got.Pause != tt.want.Pause ||
+				got.PassMinutes != tt.want.PassMinutes || got.Total != tt.want.Total {
+				t.Errorf("PriceWithAllowance() = %v, want %v", got, tt.want)
+			}
+			if got.RidePerMinute != tariff.RidePerMinute || got.PausePerMinute != tariff.PausePerMinute {
+				t.Errorf("PriceWithAllowance() rates = %d, %d, want the tariff rates %d, %d",
+					got.RidePerMinute, got.PausePerMinute, tariff.RidePerMinute, tariff.PausePerMinute)
+			}
+		})
+	}
+}
+
+func TestRideMinutes(t *testing.T) {
+	tests := []struct {
+		ride time.Duration
+		want uint64
+	}{
+		{ride: -time.Minute, want: 0},
+		{ride: 0, want: 0},
+		{ride: time.Second, want: 1},
+		{ride: time.Minute, want: 1},
+		{ride: time.Minute + time.Nanosecond, want: 2},
+	}
+
+	for _, tt := range tests {
+		if got := RideMinutes(tt.ride); got != tt.want {
+			t.Errorf("RideMinutes(%v) = %d, want %d", tt.ride, got, tt.want)
+		}
+	}
+}
@@ -23,6 +23,21 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type OrderServiceClient interface {
 	CreateOrder(ctx context.Context, in *TripInfo, opts ...grpc.CallOption) (*Order, error)
+	StartOrder(ctx context.Context, in *TripInfo, opts ...grpc.CallOption) (*Order, error)
+	ConfirmOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error)
+	CancelOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error)
+	RefundOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error)
+	DisputeOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error)
+	GetOrder(ctx context.Context, in *OrderID, opts ...grpc.CallOption) (*Order, error)
+	GetReceipt(ctx context.Context, in *OrderID, opts ...grpc.CallOption) (*Receipt, error)
+	GetBalance(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Balance, error)
+	TopUp(ctx context.Context, in *TopUpRequest, opts ...grpc.CallOption) (*Balance, error)
+	PlaceHold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*Hold, error)
+	ReleaseHold(ctx context.Context, in *HoldID, opts ...grpc.CallOption) (*Hold, error)
+	CreatePromo(ctx context.Context, in *Promo, opts ...grpc.CallOption) (*Promo, error)
+	GetPassPlans(ctx context.Context, in *PassPlansRequest, opts ...grpc.CallOption) (*PassPlanList, error)
+	BuyPass(ctx context.Context, in *BuyPassRequest, opts ...grpc.CallOption) (*Pass, error)
+	GetPasses(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*PassList, error)
 }
 
 type orderServiceClient struct {
@@ -42,11 +57,161 @@ func (c *orderServiceClient) CreateOrder(ctx context.Context, in *TripInfo, opts
 	return out, nil
 }
 
+func (c *orderServiceClient) StartOrder(ctx context.Context, in *TripInfo, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/StartOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ConfirmOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/ConfirmOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CancelOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/CancelOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RefundOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/RefundOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) DisputeOrder(ctx context.Context, in *OrderChange, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/DisputeOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrder(ctx context.Context, in *OrderID, opts ...grpc.CallOption) (*Order, error) {
+	out := new(Order)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/GetOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetReceipt(ctx context.Context, in *OrderID, opts ...grpc.CallOption) (*Receipt, error) {
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/GetReceipt", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetBalance(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/GetBalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) TopUp(ctx context.Context, in *TopUpRequest, opts ...grpc.CallOption) (*Balance, error) {
+	out := new(Balance)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/TopUp", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) PlaceHold(ctx context.Context, in *HoldRequest, opts ...grpc.CallOption) (*Hold, error) {
+	out := new(Hold)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/PlaceHold", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ReleaseHold(ctx context.Context, in *HoldID, opts ...grpc.CallOption) (*Hold, error) {
+	out := new(Hold)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/ReleaseHold", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CreatePromo(ctx context.Context, in *Promo, opts ...grpc.CallOption) (*Promo, error) {
+	out := new(Promo)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/CreatePromo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetPassPlans(ctx context.Context, in *PassPlansRequest, opts ...grpc.CallOption) (*PassPlanList, error) {
+	out := new(PassPlanList)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/GetPassPlans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) BuyPass(ctx context.Context, in *BuyPassRequest, opts ...grpc.CallOption) (*Pass, error) {
+	out := new(Pass)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/BuyPass", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetPasses(ctx context.Context, in *WalletRequest, opts ...grpc.CallOption) (*PassList, error) {
+	out := new(PassList)
+	err := c.cc.Invoke(ctx, "/proto.OrderService/GetPasses", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility
 type OrderServiceServer interface {
 	CreateOrder(context.Context, *TripInfo) (*Order, error)
+	StartOrder(context.Context, *TripInfo) (*Order, error)
+	ConfirmOrder(context.Context, *OrderChange) (*Order, error)
+	CancelOrder(context.Context, *OrderChange) (*Order, error)
+	RefundOrder(context.Context, *OrderChange) (*Order, error)
+	DisputeOrder(context.Context, *OrderChange) (*Order, error)
+	GetOrder(context.Context, *OrderID) (*Order, error)
+	GetReceipt(context.Context, *OrderID) (*Receipt, error)
+	GetBalance(context.Context, *WalletRequest) (*Balance, error)
+	TopUp(context.Context, *TopUpRequest) (*Balance, error)
+	PlaceHold(context.Context, *HoldRequest) (*Hold, error)
+	ReleaseHold(context.Context, *HoldID) (*Hold, error)
+	CreatePromo(context.Context, *Promo) (*Promo, error)
+	GetPassPlans(context.Context, *PassPlansRequest) (*PassPlanList, error)
+	BuyPass(context.Context, *BuyPassRequest) (*Pass, error)
+	GetPasses(context.Context, *WalletRequest) (*PassList, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -57,6 +222,51 @@ type UnimplementedOrderServiceServer struct {
 func (UnimplementedOrderServiceServer) CreateOrder(context.Context, *TripInfo) (*Order, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
 }
+func (UnimplementedOrderServiceServer) StartOrder(context.Context, *TripInfo) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) ConfirmOrder(context.Context, *OrderChange) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) CancelOrder(context.Context, *OrderChange) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) RefundOrder(context.Context, *OrderChange) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) DisputeOrder(context.Context, *OrderChange) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisputeOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrder(context.Context, *OrderID) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) GetReceipt(context.Context, *OrderID) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReceipt not implemented")
+}
+func (UnimplementedOrderServiceServer) GetBalance(context.Context, *WalletRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedOrderServiceServer) TopUp(context.Context, *TopUpRequest) (*Balance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopUp not implemented")
+}
+func (UnimplementedOrderServiceServer) PlaceHold(context.Context, *HoldRequest) (*Hold, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceHold not implemented")
+}
+func (UnimplementedOrderServiceServer) ReleaseHold(context.Context, *HoldID) (*Hold, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseHold not implemented")
+}
+func (UnimplementedOrderServiceServer) CreatePromo(context.Context, *Promo) (*Promo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePromo not implemented")
+}
+func (UnimplementedOrderServiceServer) GetPassPlans(context.Context, *PassPlansRequest) (*PassPlanList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPassPlans not implemented")
+}
+func (UnimplementedOrderServiceServer) BuyPass(context.Context, *BuyPassRequest) (*Pass, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuyPass not implemented")
+}
+func (UnimplementedOrderServiceServer) GetPasses(context.Context, *WalletRequest) (*PassList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPasses not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 
 // UnsafeOrderServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +298,276 @@ func _OrderService_CreateOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_StartOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TripInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).StartOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/StartOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).StartOrder(ctx, req.(*TripInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ConfirmOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ConfirmOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/ConfirmOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ConfirmOrder(ctx, req.(*OrderChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/CancelOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CancelOrder(ctx, req.(*OrderChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RefundOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RefundOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/RefundOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RefundOrder(ctx, req.(*OrderChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_DisputeOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderChange)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).DisputeOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/DisputeOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).DisputeOrder(ctx, req.(*OrderChange))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/GetOrder",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrder(ctx, req.(*OrderID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/GetReceipt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetReceipt(ctx, req.(*OrderID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/GetBalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetBalance(ctx, req.(*WalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_TopUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopUpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).TopUp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/TopUp",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).TopUp(ctx, req.(*TopUpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_PlaceHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).PlaceHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/PlaceHold",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).PlaceHold(ctx, req.(*HoldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ReleaseHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ReleaseHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/ReleaseHold",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ReleaseHold(ctx, req.(*HoldID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreatePromo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Promo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreatePromo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/CreatePromo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreatePromo(ctx, req.(*Promo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetPassPlans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PassPlansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetPassPlans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/GetPassPlans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetPassPlans(ctx, req.(*PassPlansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_BuyPass_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BuyPassRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).BuyPass(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/BuyPass",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).BuyPass(ctx, req.(*BuyPassRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetPasses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetPasses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.OrderService/GetPasses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetPasses(ctx, req.(*WalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,6 +579,66 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateOrder",
 			Handler:    _OrderService_CreateOrder_Handler,
 		},
+		{
+			MethodName: "StartOrder",
+			Handler:    _OrderService_StartOrder_Handler,
+		},
+		{
+			MethodName: "ConfirmOrder",
+			Handler:    _OrderService_ConfirmOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _OrderService_CancelOrder_Handler,
+		},
+		{
+			MethodName: "RefundOrder",
+			Handler:    _OrderService_RefundOrder_Handler,
+		},
+		{
+			MethodName: "DisputeOrder",
+			Handler:    _OrderService_DisputeOrder_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _OrderService_GetOrder_Handler,
+		},
+		{
+			MethodName: "GetReceipt",
+			Handler:    _OrderService_GetReceipt_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _OrderService_GetBalance_Handler,
+		},
+		{
+			MethodName: "TopUp",
+			Handler:    _OrderService_TopUp_Handler,
+		},
+		{
+			MethodName: "PlaceHold",
+			Handler:    _OrderService_PlaceHold_Handler,
+		},
+		{
+			MethodName: "ReleaseHold",
+			Handler:    _OrderService_ReleaseHold_Handler,
+		},
+		{
+			MethodName: "CreatePromo",
+			Handler:    _OrderService_CreatePromo_Handler,
+		},
+		{
+			MethodName: "GetPassPlans",
+			Handler:    _OrderService_GetPassPlans_Handler,
+		},
+		{
+			MethodName: "BuyPass",
+			Handler:    _OrderService_BuyPass_Handler,
+		},
+		{
+			MethodName: "GetPasses",
+			Handler:    _OrderService_GetPasses_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/order_micro.proto",
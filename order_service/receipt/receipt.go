@@ -0,0 +1,140 @@
+package receipt
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	htmltemplate "html/template"
+	"order_micro/orderstate"
+	"order_micro/pricing"
+	"order_micro/proto"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var templates embed.FS
+
+var funcs = map[string]interface{}{
+	"money":    money,
+	"duration": formatDuration,
+	"km":       func(meters float64) string { return fmt.Sprintf("%.2f", meters/1000) },
+	"date":     func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+}
+
+var (
+	textTemplate = texttemplate.Must(texttemplate.New("receipt.txt").Funcs(funcs).
+			ParseFS(templates, "templates/receipt.txt"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("receipt.html").Funcs(funcs).
+			ParseFS(templates, "templates/receipt.html"))
+)
+
+//Line is a priced part of the trip, amounts are in cents.
+type Line struct {
+	Name     string `json:"name"`
+	Quantity uint64 `json:"quantity"`
+	Rate     uint64 `json:"rate"`
+	Amount   uint64 `json:"amount"`
+}
+
+//Receipt holds the data rendered by the templates, it is also the JSON form of the receipt.
+type Receipt struct {
+	OrderID      uint64    `json:"orderId"`
+	UserID       uint64    `json:"userId"`
+	ScooterID    uint64    `json:"scooterId"`
+	CompletedAt  time.Time `json:"completedAt"`
+	Distance     float64   `json:"distance"`
+	RideSeconds  uint64    `json:"rideSeconds"`
+	PauseSeconds uint64    `json:"pauseSeconds"`
+	Lines        []Line    `json:"lines"`
+	PassMinutes  uint64    `json:"passMinutes,omitempty"`
+	PromoCode    string    `json:"promoCode,omitempty"`
+	Discount     uint64    `json:"discount"`
+	Total        uint64    `json:"total"`
+}
+
+//New creates the receipt of the completed order. The quantities are the billed minutes of the order and the rates
+//are the ones it was priced with, so the receipt doesn't depend on the current tariff.
+func New(order *proto.Order) *Receipt {
+	price := order.Price
+	if price == nil {
+		price = &proto.PriceBreakdown{}
+	}
+
+	receipt := &Receipt{
+		OrderID:      order.Id,
+		UserID:       order.UserID,
+		ScooterID:    order.ScooterID,
+		CompletedAt:  completedAt(order),
+		Distance:     order.Distance,
+		RideSeconds:  order.RideSeconds,
+		PauseSeconds: order.PauseSeconds,
+		PassMinutes:  price.PassMinutes,
+		PromoCode:    order.PromoCode,
+		Discount:     price.Discount,
+		Total:        price.Total,
+	}
+
+	rideMinutes := pricing.RideMinutes(time.Duration(order.RideSeconds) * time.Second)
+	if rideMinutes > price.PassMinutes {
+		rideMinutes -= price.PassMinutes
+	} else {
+		rideMinutes = 0
+	}
+	pauseMinutes := pricing.PauseMinutes(time.Duration(order.PauseSeconds) * time.Second)
+
+	receipt.Lines = append(receipt.Lines, Line{Name: "Unlock", Quantity: 1, Rate: price.Unlock,
+		Amount: price.Unlock})
+	if price.RidePerMinute != 0 {
+		receipt.Lines = append(receipt.Lines, Line{Name: "Ride, min", Quantity: rideMinutes,
+			Rate: price.RidePerMinute, Amount: price.Ride})
+	}
+	if price.PausePerMinute != 0 {
+		receipt.Lines = append(receipt.Lines, Line{Name: "Pause, min", Quantity: pauseMinutes,
+			Rate: price.PausePerMinute, Amount: price.Pause})
+	}
+	return receipt
+}
+
+//Render renders the receipt to the plain text, HTML and JSON.
+func (r *Receipt) Render() (*proto.Receipt, error) {
+	var text, html bytes.Buffer
+	err := textTemplate.Execute(&text, r)
+	if err != nil {
+		return nil, err
+	}
+	err = htmlTemplate.Execute(&html, r)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Receipt{OrderID: r.OrderID, Text: text.String(), Html: html.String(), Json: string(data),
+		CreatedAt: timestamppb.Now()}, nil
+}
+
+//completedAt returns the time the order was completed, or now if the transition isn't known.
+func completedAt(order *proto.Order) time.Time {
+	for _, transition := range order.Transitions {
+		if transition.To == orderstate.Completed && transition.DateTime != nil {
+			return transition.DateTime.AsTime()
+		}
+	}
+	return time.Now().UTC()
+}
+
+//money formats the amount in cents.
+func money(cents uint64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+//formatDuration formats the number of seconds as hours, minutes and seconds.
+func formatDuration(seconds uint64) string {
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
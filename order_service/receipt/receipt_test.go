@@ -0,0 +1,145 @@
+package receipt
+
+import (
+	"encoding/json"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/orderstate"
+	"order_micro/proto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name  string
+		order *proto.Order
+		want  []Line
+	}{
+		{name: "started minutes",
+			order: &proto.Order{RideSeconds: 601, PauseSeconds: 90, Price: &proto.PriceBreakdown{Unlock: 100,
+				Ride: 330, Pause: 20, Total: 450, RidePerMinute: 30, PausePerMinute: 10}},
+			want: []Line{
+				{Name: "Unlock", Quantity: 1, Rate: 100, Amount: 100},
+				{Name: "Ride, min", Quantity: 11, Rate: 30, Amount: 330},
+				{Name: "Pause, min", Quantity: 2, Rate: 10, Amount: 20},
+			}},
+		{name: "pass minutes",
+			order: &proto.Order{RideSeconds: 600, Price: &proto.PriceBreakdown{Unlock: 100, Ride: 180, Total: 280,
+				PassMinutes: 4, RidePerMinute: 30, PausePerMinute: 10}},
+			want: []Line{
+				{Name: "Unlock", Quantity: 1, Rate: 100, Amount: 100},
+				{Name: "Ride, min", Quantity: 6, Rate: 30, Amount: 180},
+				{Name: "Pause, min", Quantity: 0, Rate: 10, Amount: 0},
+			}},
+		{name: "ride covered by pass",
+			order: &proto.Order{RideSeconds: 300, Price: &proto.PriceBreakdown{PassMinutes: 5, RidePerMinute: 30}},
+			want: []Line{
+				{Name: "Unlock", Quantity: 1},
+				{Name: "Ride, min", Quantity: 0, Rate: 30},
+			}},
+		{name: "rates of the order, not the tariff",
+			order: &proto.Order{RideSeconds: 120, Price: &proto.PriceBreakdown{Unlock: 50, Ride: 90, Total: 140,
+				RidePerMinute: 45}},
+			want: []Line{
+				{Name: "Unlock", Quantity: 1, Rate: 50, Amount: 50},
+				{Name: "Ride, min", Quantity: 2, Rate: 45, Amount: 90},
+			}},
+		{name: "no price", order: &proto.Order{RideSeconds: 60},
+			want: []Line{{Name: "Unlock", Quantity: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.order)
+			if !reflect.DeepEqual(got.Lines, tt.want) {
+				t.Fatalf("New() lines = %+v, want %+v", got.Lines, tt.want)
+			}
+
+			var sum uint64
+			for _, line := range got.Lines {
+				sum += line.Amount
+			}
+			if got.Total != sum-got.Discount {
+				t.Errorf("New() total = %d, want the lines %d less the discount %d", got.Total, sum, got.Discount)
+			}
+		})
+	}
+}
+
+func TestNewCompletedAt(t *testing.T) {
+	completed := time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC)
+	order := &proto.Order{Transitions: []*proto.OrderTransition{
+		{To: orderstate.Started, DateTime: timestamppb.New(completed.Add(-time.Hour))},
+		{To: orderstate.Completed, DateTime: timestamppb.New(completed)},
+		{To: orderstate.Refunded, DateTime: timestamppb.New(completed.Add(time.Hour))},
+	}}
+
+	if got := New(order).CompletedAt; !got.Equal(completed) {
+		t.Errorf("New() completed at %v, want %v", got, completed)
+	}
+}
+
+func TestRender(t *testing.T) {
+	order := &proto.Order{Id: 42, UserID: 7, ScooterID: 3, Distance: 2500, RideSeconds: 600, PauseSeconds: 60,
+		PromoCode: "SUMMER", Price: &proto.PriceBreakdown{Unlock: 100, Ride: 180, Pause: 10, Discount: 100,
+			Total: 190, PassMinutes: 4, RidePerMinute: 30, PausePerMinute: 10}}
+	receipt := New(order)
+
+	rendered, err := receipt.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered.OrderID != 42 || rendered.CreatedAt == nil {
+		t.Errorf("Render() = order %d, created at %v", rendered.OrderID, rendered.CreatedAt)
+	}
+
+	for _, want := range []string{
+		"Order:     #42",
+		"Distance:  2.50 km",
+		"Riding:    0:10:00",
+		"Ride, min       6 x     0.30 =      1.80",
+		"Pass minutes used: 4",
+		"Discount (SUMMER): -1.00",
+		"Total: 1.90",
+	} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Errorf("text receipt doesn't contain %q:\n%s", want, rendered.Text)
+		}
+	}
+	for _, want := range []string{
+		"<td>Ride, min</td>",
+		"<p>Pass minutes used: 4</p>",
+		"<p>Discount (SUMMER): -1.00</p>",
+		"<strong>Total: 1.90</strong>",
+	} {
+		if !strings.Contains(rendered.Html, want) {
+			t.Errorf("HTML receipt doesn't contain %q:\n%s", want, rendered.Html)
+		}
+	}
+
+	var decoded Receipt
+	err = json.Unmarshal([]byte(rendered.Json), &decoded)
+	if err != nil {
+		t.Fatalf("JSON receipt error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Lines, receipt.Lines) || decoded.Total != 190 || decoded.PromoCode != "SUMMER" {
+		t.Errorf("JSON receipt = %+v, want %+v", decoded, receipt)
+	}
+}
+
+func TestRenderWithoutPassAndPromo(t *testing.T) {
+	receipt := New(&proto.Order{Id: 1, RideSeconds: 60, Price: &proto.PriceBreakdown{Unlock: 100, Ride: 30,
+		Total: 130, RidePerMinute: 30}})
+
+	rendered, err := receipt.Render()
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, unwanted := range []string{"Pass minutes", "Discount"} {
+		if strings.Contains(rendered.Text, unwanted) || strings.Contains(rendered.Html, unwanted) {
+			t.Errorf("receipt contains %q without a pass and a promo code:\n%s", unwanted, rendered.Text)
+		}
+	}
+}
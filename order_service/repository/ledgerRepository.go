@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"order_micro/proto"
+)
+
+//Ledger accounts. Every user has the available and the held account, the provider and the revenue accounts
+//belong to the system and are stored with zero user ID.
+const (
+	AccountAvailable = "available"
+	AccountHeld      = "held"
+	AccountProvider  = "provider"
+	AccountRevenue   = "revenue"
+)
+
+const (
+	HoldHeld     = "held"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+)
+
+var (
+	ErrUnbalanced        = errors.New("ledger transaction isn't balanced")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrHoldClosed        = errors.New("hold has already been captured or released")
+)
+
+//Entry is a change of the account balance in cents.
+type Entry struct {
+	UserID  uint64
+	Account string
+	Amount  int64
+}
+
+//Transaction is a set of the entries which sum to zero, so the money is only moved between the accounts.
+//Without AllowOverdraft the transaction fails when the available balance of the user becomes negative.
+type Transaction struct {
+	Kind           string
+	UserID         uint64
+	Reference      string
+	Entries        []Entry
+	AllowOverdraft bool
+}
+
+//LedgerRepository the interface which implemented by functions which store the double-entry ledger.
+type LedgerRepository interface {
+	Post(ctx context.Context, t Transaction) error
+	GetBalance(ctx context.Context, userID uint64) (*proto.Balance, error)
+	CreateHold(ctx context.Context, userID, amount uint64) (*proto.Hold, error)
+	ReleaseHold(ctx context.Context, id uint64) (*proto.Hold, error)
+	CaptureHold(ctx context.Context, id, orderID, amount uint64) (*proto.Hold, error)
+	Charge(ctx context.Context, userID, orderID, amount uint64) error
+}
+
+type LedgerRepo struct {
+	db *sql.DB
+}
+
+func NewLedgerRepo(db *sql.DB) *LedgerRepo {
+	return &LedgerRepo{db: db}
+}
+
+//Post records the transaction and updates the balances of its accounts.
+func (lr *LedgerRepo) Post(ctx context.Context, t Transaction) error {
+	tx, err := lr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = post(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//GetBalance returns the available and the held money of the user.
+func (lr *LedgerRepo) GetBalance(ctx context.Context, userID uint64) (*proto.Balance, error) {
+	balance := &proto.Balance{UserID: userID}
+	var held int64
+	querySQL := `SELECT COALESCE(SUM(balance) FILTER (WHERE kind = $2), 0),
+						COALESCE(SUM(balance) FILTER (WHERE kind = $3), 0)
+					FROM ledger_accounts
+					WHERE user_id = $1`
+	err := lr.db.QueryRowContext(ctx, querySQL, userID, AccountAvailable, AccountHeld).Scan(&balance.Available, &held)
+	if err != nil {
+		return nil, err
+	}
+	balance.Held = uint64(held)
+	return balance, nil
+}
+
+//CreateHold moves the amount from the available to the held account of the user.
+func (lr *LedgerRepo) CreateHold(ctx context.Context, userID, amount uint64) (*proto.Hold, error) {
+	tx, err := lr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold := &proto.Hold{UserID: userID, Amount: amount, State: HoldHeld}
+	querySQL := `INSERT INTO holds(user_id, amount, state) VALUES ($1, $2, $3) RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, userID, amount, HoldHeld).Scan(&hold.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = post(ctx, tx, Transaction{Kind: "hold", UserID: userID, Reference: holdReference(hold.Id),
+		Entries: []Entry{
+			{UserID: userID, Account: AccountAvailable, Amount: -int64(amount)},
+			{UserID: userID, Account: AccountHeld, Amount: int64(amount)},
+		}})
+	if err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+//ReleaseHold returns the held money to the available account of the user.
+func (lr *LedgerRepo) ReleaseHold(ctx context.Context, id uint64) (*proto.Hold, error) {
+	tx, err := lr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := closeHold(ctx, tx, id, HoldReleased, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = post(ctx, tx, Transaction{Kind: "release", UserID: hold.UserID, Reference: holdReference(hold.Id),
+		Entries: []Entry{
+			{UserID: hold.UserID, Account: AccountHeld, Amount: -int64(hold.Amount)},
+			{UserID: hold.UserID, Account: AccountAvailable, Amount: int64(hold.Amount)},
+		}})
+	if err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+//CaptureHold pays the order by the held money. The rest of the hold is returned to the available account,
+//the amount above the hold is taken from the available account even if the user goes into debt.
+func (lr *LedgerRepo) CaptureHold(ctx context.Context, id, orderID, amount uint64) (*proto.Hold, error) {
+	tx, err := lr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	hold, err := captureHold(ctx, tx, id, orderID, amount)
+	if err != nil {
+		return nil, err
+	}
+	return hold, tx.Commit()
+}
+
+//Charge pays the order from the available account of the user, the user can go into debt.
+func (lr *LedgerRepo) Charge(ctx context.Context, userID, orderID, amount uint64) error {
+	return lr.Post(ctx, chargeTransaction(userID, orderID, amount))
+}
+
+//payOrder pays the order in the transaction which completes it, by its hold or by the wallet of the user.
+func payOrder(ctx context.Context, tx *sql.Tx, order *proto.Order) error {
+	if order.HoldID != 0 {
+		_, err := captureHold(ctx, tx, order.HoldID, order.Id, order.Price.Total)
+		return err
+	}
+	return post(ctx, tx, chargeTransaction(order.UserID, order.Id, order.Price.Total))
+}
+
+func captureHold(ctx context.Context, tx *sql.Tx, id, orderID, amount uint64) (*proto.Hold, error) {
+	hold, err := closeHold(ctx, tx, id, HoldCaptured, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = post(ctx, tx, captureTransaction(hold, orderID, amount))
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+//paidAmount returns the money of the order kept in the revenue: the captured and charged amounts without the refunds.
+func paidAmount(ctx context.Context, tx *sql.Tx, orderID uint64) (uint64, error) {
+	var paid int64
+	querySQL := `SELECT COALESCE(SUM(e.amount), 0)
+					FROM ledger_entries AS e
+					JOIN ledger_transactions AS t ON t.id = e.transaction_id
+					JOIN ledger_accounts AS a ON a.id = e.account_id
+					WHERE t.reference = $1 AND a.user_id = 0 AND a.kind = $2`
+	err := tx.QueryRowContext(ctx, querySQL, orderReference(orderID), AccountRevenue).Scan(&paid)
+	if err != nil {
+		return 0, err
+	}
+	if paid < 0 {
+		return 0, nil
+	}
+	return uint64(paid), nil
+}
+
+//refundAmount returns the amount of the refund which can't exceed the paid money. Zero requested amount
+//refunds all the paid money.
+func refundAmount(paid, requested uint64) (uint64, error) {
+	amount := requested
+	if amount == 0 {
+		amount = paid
+	}
+	if amount == 0 || amount > paid {
+		return 0, fmt.Errorf("%w: %d cents of the order can be refunded", ErrRefundExceeded, paid)
+	}
+	return amount, nil
+}
+
+//captureTransaction moves the held money to the revenue, the rest of the hold returns to the available balance.
+//The amount above the hold is taken from the available balance, which may become negative.
+func captureTransaction(hold *proto.Hold, orderID, amount uint64) Transaction {
+	return Transaction{Kind: "capture", UserID: hold.UserID, Reference: orderReference(orderID), AllowOverdraft: true,
+		Entries: []Entry{
+			{UserID: hold.UserID, Account: AccountHeld, Amount: -int64(hold.Amount)},
+			{UserID: hold.UserID, Account: AccountAvailable, Amount: int64(hold.Amount) - int64(amount)},
+			{Account: AccountRevenue, Amount: int64(amount)},
+		}}
+}
+
+func chargeTransaction(userID, orderID, amount uint64) Transaction {
+	return Transaction{Kind: "charge", UserID: userID, Reference: orderReference(orderID), AllowOverdraft: true,
+		Entries: []Entry{
+			{UserID: userID, Account: AccountAvailable, Amount: -int64(amount)},
+			{Account: AccountRevenue, Amount: int64(amount)},
+		}}
+}
+
+//balanced returns ErrUnbalanced unless the entries of the transaction sum to zero.
+func (t Transaction) balanced() error {
+	var sum int64
+	for _, entry := range t.Entries {
+		sum += entry.Amount
+	}
+	if sum != 0 {
+		return fmt.Errorf("%w: %v entries sum to %d", ErrUnbalanced, t.Kind, sum)
+	}
+	return nil
+}
+
+func post(ctx context.Context, tx *sql.Tx, t Transaction) error {
+	err := t.balanced()
+	if err != nil {
+		return err
+	}
+
+	var transactionID uint64
+	querySQL := `INSERT INTO ledger_transactions(kind, user_id, reference) VALUES ($1, $2, $3) RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, t.Kind, t.UserID, t.Reference).Scan(&transactionID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range t.Entries {
+		var accountID uint64
+		var balance int64
+		querySQL = `INSERT INTO ledger_accounts(user_id, kind, balance) VALUES ($1, $2, $3)
+						ON CONFLICT (user_id, kind) DO UPDATE SET balance = ledger_accounts.balance + EXCLUDED.balance
+						RETURNING id, balance`
+		err = tx.QueryRowContext(ctx, querySQL, entry.UserID, entry.Account, entry.Amount).Scan(&accountID, &balance)
+		if err != nil {
+			return err
+		}
+		if entry.Account == AccountAvailable && balance < 0 && entry.Amount < 0 && !t.AllowOverdraft {
+			return ErrInsufficientFunds
+		}
+
+		querySQL = `INSERT INTO ledger_entries(transaction_id, account_id, amount) VALUES ($1, $2, $3)`
+		_, err = tx.ExecContext(ctx, querySQL, transactionID, accountID, entry.Amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//closeHold moves the hold which is still held to the final state.
+func closeHold(ctx context.Context, tx *sql.Tx, id uint64, state string, orderID uint64) (*proto.Hold, error) {
+	hold := &proto.Hold{Id: id, State: state, OrderID: orderID}
+	querySQL := `UPDATE holds SET state = $1, order_id = NULLIF($2, 0) WHERE id = $3 AND state = $4
+					RETURNING user_id, amount`
+	err := tx.QueryRowContext(ctx, querySQL, state, orderID, id, HoldHeld).Scan(&hold.UserID, &hold.Amount)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM holds WHERE id = $1)`, id).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrHoldClosed
+		}
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func holdReference(id uint64) string {
+	return fmt.Sprintf("hold:%d", id)
+}
+
+func orderReference(id uint64) string {
+	return fmt.Sprintf("order:%d", id)
+}
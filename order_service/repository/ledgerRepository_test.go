@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"order_micro/proto"
+	"testing"
+)
+
+func TestRefundAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		paid      uint64
+		requested uint64
+		want      uint64
+		wantErr   error
+	}{
+		{name: "all the paid money", paid: 1200, want: 1200},
+		{name: "part", paid: 1200, requested: 500, want: 500},
+		{name: "exactly paid", paid: 1200, requested: 1200, want: 1200},
+		{name: "above paid", paid: 1200, requested: 1201, wantErr: ErrRefundExceeded},
+		{name: "unpaid order", paid: 0, wantErr: ErrRefundExceeded},
+		{name: "part of unpaid order", paid: 0, requested: 100, wantErr: ErrRefundExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := refundAmount(tt.paid, tt.requested)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("refundAmount(%d, %d) error = %v, want %v", tt.paid, tt.requested, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("refundAmount(%d, %d) = %d, want %d", tt.paid, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionBalanced(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		wantErr error
+	}{
+		{name: "no entries"},
+		{name: "balanced", entries: []Entry{
+			{UserID: 1, Account: AccountAvailable, Amount: -500},
+			{UserID: 1, Account: AccountHeld, Amount: 500},
+		}},
+		{name: "unbalanced", entries: []Entry{
+			{UserID: 1, Account: AccountAvailable, Amount: -500},
+			{Account: AccountRevenue, Amount: 400},
+		}, wantErr: ErrUnbalanced},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Transaction{Kind: "test", Entries: tt.entries}.balanced()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("balanced() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChargeTransaction(t *testing.T) {
+	transaction := chargeTransaction(7, 42, 1200)
+	if err := transaction.balanced(); err != nil {
+		t.Fatalf("chargeTransaction isn't balanced: %v", err)
+	}
+	if transaction.Reference != "order:42" || transaction.UserID != 7 || !transaction.AllowOverdraft {
+		t.Errorf("chargeTransaction = %+v", transaction)
+	}
+
+	amounts := accountAmounts(transaction)
+	if amounts[AccountAvailable] != -1200 || amounts[AccountRevenue] != 1200 {
+		t.Errorf("chargeTransaction amounts = %v, want available -1200 and revenue 1200", amounts)
+	}
+}
+
+func TestCaptureTransaction(t *testing.T) {
+	tests := []struct {
+		name          string
+		hold          uint64
+		amount        uint64
+		wantAvailable int64
+	}{
+		{name: "below hold", hold: 2000, amount: 1200, wantAvailable: 800},
+		{name: "exactly hold", hold: 2000, amount: 2000, wantAvailable: 0},
+		{name: "above hold", hold: 2000, amount: 2600, wantAvailable: -600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := captureTransaction(&proto.Hold{Id: 3, UserID: 7, Amount: tt.hold}, 42, tt.amount)
+			if err := transaction.balanced(); err != nil {
+				t.Fatalf("captureTransaction isn't balanced: %v", err)
+			}
+			if transaction.Reference != "order:42" || !transaction.AllowOverdraft {
+				t.Errorf("captureTransaction = %+v", transaction)
+			}
+
+			amounts := accountAmounts(transaction)
+			if amounts[AccountHeld] != -int64(tt.hold) {
+				t.Errorf("held = %d, want %d", amounts[AccountHeld], -int64(tt.hold))
+			}
+			if amounts[AccountAvailable] != tt.wantAvailable {
+				t.Errorf("available = %d, want %d", amounts[AccountAvailable], tt.wantAvailable)
+			}
+			if amounts[AccountRevenue] != int64(tt.amount) {
+				t.Errorf("revenue = %d, want %d", amounts[AccountRevenue], tt.amount)
+			}
+		})
+	}
+}
+
+func accountAmounts(transaction Transaction) map[string]int64 {
+	amounts := make(map[string]int64)
+	for _, entry := range transaction.Entries {
+		amounts[entry.Account] += entry.Amount
+	}
+	return amounts
+}
@@ -3,12 +3,29 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/orderstate"
 	"order_micro/proto"
+	"time"
+)
+
+var (
+	ErrOrderNotFound  = errors.New("order not found")
+	ErrRefundExceeded = errors.New("refund exceeds the paid amount")
+	ErrTripActive     = errors.New("trip of the order is still active, end the trip first")
 )
 
 type OrderRepository interface {
-	CreateOrder(ctx context.Context, info *proto.TripInfo) (*proto.Order, error)
+	CreateOrder(ctx context.Context, order *proto.Order) (*proto.Order, error)
+	CompleteOrder(ctx context.Context, order *proto.Order, change *proto.OrderChange) (*proto.Order, error)
+	ChangeState(ctx context.Context, change *proto.OrderChange, to string) (*proto.Order, error)
+	CancelOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error)
+	RefundOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error)
+	GetOrder(ctx context.Context, id uint64) (*proto.Order, error)
+	GetStatusTimes(ctx context.Context, statusStartID, statusEndID uint64) (time.Time, time.Time, error)
+	CountUserOrders(ctx context.Context, userID uint64) (uint64, error)
 }
 
 type OrderRepo struct {
@@ -19,21 +36,346 @@ func NewOrderRepo(db *sql.DB) *OrderRepo {
 	return &OrderRepo{db: db}
 }
 
-func (or *OrderRepo) CreateOrder(ctx context.Context, info *proto.TripInfo) (*proto.Order, error) {
+//CreateOrder inserts the order in its state and returns it with the new ID. Orders without the state are completed,
+//the completed order is paid in the same transaction, so it isn't saved if it can't be paid.
+func (or *OrderRepo) CreateOrder(ctx context.Context, order *proto.Order) (*proto.Order, error) {
 	fmt.Println("Create Order called on Order_micro")
-	var order = &proto.Order{}
-	order.UserID = info.UserID
-	order.ScooterID = info.ScooterID
-	order.StatusStartID = info.StatusStartID
-	order.StatusEndID = info.StatusEndID
+	price := order.Price
+	if price == nil {
+		price = &proto.PriceBreakdown{}
+	}
+	if order.State == "" {
+		order.State = orderstate.Completed
+	}
+
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	querySQL := `INSERT INTO orders(user_id, scooter_id, status_start_id, status_end_id, ride_seconds, pause_seconds,
+					unlock_amount, ride_amount, pause_amount, discount_amount, total_amount, hold_id, promo_code,
+					pass_id, pass_minutes, state, distance, ride_rate, pause_rate) 
+					VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9, $10, $11, NULLIF($12, 0), $13, NULLIF($14, 0),
+						$15, $16, $17, $18, $19)
+					RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, order.UserID, order.ScooterID, order.StatusStartID, order.StatusEndID,
+		order.RideSeconds, order.PauseSeconds, price.Unlock, price.Ride, price.Pause, price.Discount, price.Total,
+		order.HoldID, order.PromoCode, order.PassID, price.PassMinutes, order.State, order.Distance,
+		price.RidePerMinute, price.PausePerMinute).Scan(&order.Id)
+	if err != nil {
+		return nil, err
+	}
 
-	querySQL := `INSERT INTO orders(user_id, scooter_id, status_start_id, status_end_id) 
-					VALUES ($1, $2, $3, $4) RETURNING id`
-	err := or.db.QueryRowContext(ctx, querySQL, order.UserID, order.ScooterID, order.StatusStartID, order.StatusEndID).Scan(&order.Id)
+	transition, err := insertTransition(ctx, tx, order.Id, &proto.OrderTransition{To: order.State, Actor: "system"})
 	if err != nil {
 		return nil, err
 	}
+	order.Transitions = append(order.Transitions, transition)
+
+	if order.State == orderstate.Completed {
+		err = payOrder(ctx, tx, order)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	events := []string{EventOrderCreated}
+	if order.State != orderstate.Started {
+		events = append(events, OrderStateEvent(order.State))
+	}
 
 	fmt.Println("Order created on Order_service")
+	return commitOrder(ctx, tx, order, events...)
+}
+
+//CompleteOrder saves the statuses and the price of the order which is in progress, completes it and pays it
+//by its hold or by the wallet of the user. Nothing is changed if the order can't be paid.
+func (or *OrderRepo) CompleteOrder(ctx context.Context, order *proto.Order,
+	change *proto.OrderChange) (*proto.Order, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := lockOrder(ctx, tx, order.Id)
+	if err != nil {
+		return nil, err
+	}
+	err = orderstate.Check(current.State, orderstate.Completed)
+	if err != nil {
+		return nil, err
+	}
+
+	price := order.Price
+	querySQL := `UPDATE orders SET status_start_id = $1, status_end_id = NULLIF($2, 0), ride_seconds = $3,
+					pause_seconds = $4, unlock_amount = $5, ride_amount = $6, pause_amount = $7, discount_amount = $8,
+					total_amount = $9, promo_code = $10, pass_id = NULLIF($11, 0), pass_minutes = $12, distance = $13,
+					ride_rate = $14, pause_rate = $15
+					WHERE id = $16`
+	_, err = tx.ExecContext(ctx, querySQL, order.StatusStartID, order.StatusEndID, order.RideSeconds,
+		order.PauseSeconds, price.Unlock, price.Ride, price.Pause, price.Discount, price.Total, order.PromoCode,
+		order.PassID, price.PassMinutes, order.Distance, price.RidePerMinute, price.PausePerMinute, order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setState(ctx, tx, current, orderstate.Completed, change)
+	if err != nil {
+		return nil, err
+	}
+
+	order.State, order.HoldID, order.Transitions = current.State, current.HoldID, current.Transitions
+	err = payOrder(ctx, tx, order)
+	if err != nil {
+		return nil, err
+	}
+	return commitOrder(ctx, tx, order, OrderStateEvent(order.State))
+}
+
+//ChangeState moves the order to the state which doesn't move any money.
+func (or *OrderRepo) ChangeState(ctx context.Context, change *proto.OrderChange, to string) (*proto.Order, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(ctx, tx, change.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	err = setState(ctx, tx, order, to, change)
+	if err != nil {
+		return nil, err
+	}
+	return commitOrder(ctx, tx, order, OrderStateEvent(order.State))
+}
+
+//CancelOrder cancels the started order and releases its hold. The order in progress belongs to the trip which
+//isn't ended, it can't be cancelled, the trip has to be ended and billed first.
+func (or *OrderRepo) CancelOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(ctx, tx, change.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.State == orderstate.InProgress {
+		return nil, ErrTripActive
+	}
+	err = setState(ctx, tx, order, orderstate.Cancelled, change)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.HoldID != 0 {
+		hold, err := closeHold(ctx, tx, order.HoldID, HoldReleased, 0)
+		if err != nil {
+			return nil, err
+		}
+		err = post(ctx, tx, Transaction{Kind: "release", UserID: hold.UserID, Reference: holdReference(hold.Id),
+			Entries: []Entry{
+				{UserID: hold.UserID, Account: AccountHeld, Amount: -int64(hold.Amount)},
+				{UserID: hold.UserID, Account: AccountAvailable, Amount: int64(hold.Amount)},
+			}})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return commitOrder(ctx, tx, order, OrderStateEvent(order.State))
+}
+
+//RefundOrder returns the amount of the change to the wallet of the user. The refunds can't exceed the money
+//the ledger took for the order, zero amount refunds all of it.
+func (or *OrderRepo) RefundOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := lockOrder(ctx, tx, change.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	paid, err := paidAmount(ctx, tx, order.Id)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := refundAmount(paid, change.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &proto.OrderChange{OrderID: change.OrderID, Actor: change.Actor, Reason: change.Reason, Amount: amount}
+	err = setState(ctx, tx, order, orderstate.Refunded, refund)
+	if err != nil {
+		return nil, err
+	}
+
+	order.RefundedAmount += amount
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET refunded_amount = $1 WHERE id = $2`, order.RefundedAmount,
+		order.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = post(ctx, tx, Transaction{Kind: "refund", UserID: order.UserID, Reference: orderReference(order.Id),
+		Entries: []Entry{
+			{Account: AccountRevenue, Amount: -int64(amount)},
+			{UserID: order.UserID, Account: AccountAvailable, Amount: int64(amount)},
+		}})
+	if err != nil {
+		return nil, err
+	}
+	return commitOrder(ctx, tx, order, OrderStateEvent(order.State))
+}
+
+//GetOrder returns the order with its state transitions.
+func (or *OrderRepo) GetOrder(ctx context.Context, id uint64) (*proto.Order, error) {
+	order, err := scanOrder(or.db.QueryRowContext(ctx, selectOrderSQL+` WHERE id = $1`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	querySQL := `SELECT from_state, to_state, actor, reason, amount, date_time
+					FROM order_transitions
+					WHERE order_id = $1
+					ORDER BY id`
+	rows, err := or.db.QueryContext(ctx, querySQL, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		transition := &proto.OrderTransition{}
+		var dateTime time.Time
+		err = rows.Scan(&transition.From, &transition.To, &transition.Actor, &transition.Reason, &transition.Amount,
+			&dateTime)
+		if err != nil {
+			return nil, err
+		}
+		transition.DateTime = timestamppb.New(dateTime)
+		order.Transitions = append(order.Transitions, transition)
+	}
+	return order, rows.Err()
+}
+
+//GetStatusTimes returns the times of the statuses in rent which started and ended the trip.
+func (or *OrderRepo) GetStatusTimes(ctx context.Context, statusStartID, statusEndID uint64) (time.Time, time.Time,
+	error) {
+	var start, end time.Time
+	querySQL := `SELECT date_time FROM scooter_statuses_in_rent WHERE id = $1`
+
+	err := or.db.QueryRowContext(ctx, querySQL, statusStartID).Scan(&start)
+	if err != nil {
+		return start, end, err
+	}
+	err = or.db.QueryRowContext(ctx, querySQL, statusEndID).Scan(&end)
+	return start, end, err
+}
+
+//CountUserOrders returns the number of the orders of the user.
+func (or *OrderRepo) CountUserOrders(ctx context.Context, userID uint64) (uint64, error) {
+	var count uint64
+	querySQL := `SELECT COUNT(*) FROM orders WHERE user_id = $1 AND state IN ($2, $3, $4)`
+	err := or.db.QueryRowContext(ctx, querySQL, userID, orderstate.Completed, orderstate.Refunded,
+		orderstate.Disputed).Scan(&count)
+	return count, err
+}
+
+const selectOrderSQL = `SELECT id, user_id, scooter_id, status_start_id, status_end_id, ride_seconds, pause_seconds,
+					unlock_amount, ride_amount, pause_amount, discount_amount, total_amount, pass_minutes, hold_id,
+					promo_code, pass_id, state, refunded_amount, distance, ride_rate, pause_rate
+					FROM orders`
+
+//lockOrder returns the order locked until the end of the transaction.
+func lockOrder(ctx context.Context, tx *sql.Tx, id uint64) (*proto.Order, error) {
+	return scanOrder(tx.QueryRowContext(ctx, selectOrderSQL+` WHERE id = $1 FOR UPDATE`, id))
+}
+
+func scanOrder(row *sql.Row) (*proto.Order, error) {
+	order := &proto.Order{Price: &proto.PriceBreakdown{}}
+	var statusStartID, statusEndID, holdID, passID sql.NullInt64
+	price := order.Price
+
+	err := row.Scan(&order.Id, &order.UserID, &order.ScooterID, &statusStartID, &statusEndID,
+		&order.RideSeconds, &order.PauseSeconds, &price.Unlock, &price.Ride, &price.Pause, &price.Discount,
+		&price.Total, &price.PassMinutes, &holdID, &order.PromoCode, &passID, &order.State, &order.RefundedAmount,
+		&order.Distance, &price.RidePerMinute, &price.PausePerMinute)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	order.StatusStartID = uint64(statusStartID.Int64)
+	order.StatusEndID = uint64(statusEndID.Int64)
+	order.HoldID = uint64(holdID.Int64)
+	order.PassID = uint64(passID.Int64)
 	return order, nil
 }
+
+//commitOrder writes the events of the changed order to the outbox and commits the transaction.
+func commitOrder(ctx context.Context, tx *sql.Tx, order *proto.Order, events ...string) (*proto.Order, error) {
+	for _, event := range events {
+		err := insertEvent(ctx, tx, event, order)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, tx.Commit()
+}
+
+//setState checks the transition, moves the locked order to the new state and records the transition.
+func setState(ctx context.Context, tx *sql.Tx, order *proto.Order, to string, change *proto.OrderChange) error {
+	err := orderstate.Check(order.State, to)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET state = $1 WHERE id = $2`, to, order.Id)
+	if err != nil {
+		return err
+	}
+
+	transition, err := insertTransition(ctx, tx, order.Id, &proto.OrderTransition{From: order.State, To: to,
+		Actor: change.Actor, Reason: change.Reason, Amount: change.Amount})
+	if err != nil {
+		return err
+	}
+
+	order.State = to
+	order.Transitions = append(order.Transitions, transition)
+	return nil
+}
+
+func insertTransition(ctx context.Context, tx *sql.Tx, orderID uint64,
+	transition *proto.OrderTransition) (*proto.OrderTransition, error) {
+	var dateTime time.Time
+	querySQL := `INSERT INTO order_transitions(order_id, from_state, to_state, actor, reason, amount)
+					VALUES ($1, $2, $3, $4, $5, $6) RETURNING date_time`
+	err := tx.QueryRowContext(ctx, querySQL, orderID, transition.From, transition.To, transition.Actor,
+		transition.Reason, transition.Amount).Scan(&dateTime)
+	if err != nil {
+		return nil, err
+	}
+	transition.DateTime = timestamppb.New(dateTime)
+	return transition, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	protobuf "google.golang.org/protobuf/proto"
+	"order_micro/proto"
+	"time"
+)
+
+//relayLockKey is the key of the advisory lock held by the relay, so only one relay publishes the events at a time
+//and the events of an order are published in the order they were written.
+const relayLockKey = 38001
+
+const EventOrderCreated = "order.created"
+
+//OrderStateEvent returns the type of the event written when the order is moved to the state.
+func OrderStateEvent(state string) string {
+	return "order." + state
+}
+
+//OutboxEvent is an event written in the same transaction as the order and published to Kafka later.
+type OutboxEvent struct {
+	ID          uint64
+	AggregateID uint64
+	Type        string
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+//OutboxRepository the interface which implemented by functions which relay the outbox events.
+type OutboxRepository interface {
+	Relay(ctx context.Context, limit uint64, publish func(events []OutboxEvent) (int, error)) (int, error)
+}
+
+type OutboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+//Relay passes up to limit unpublished events to publish in the order they were written and marks
+//as published as many of them as publish reports. The events are published at least once: they are published
+//again if marking them fails. Relay does nothing while another relay holds the lock.
+func (or *OutboxRepo) Relay(ctx context.Context, limit uint64,
+	publish func(events []OutboxEvent) (int, error)) (int, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLockKey).Scan(&locked)
+	if err != nil || !locked {
+		return 0, err
+	}
+
+	events, err := unpublishedEvents(ctx, tx, limit)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	published, publishErr := publish(events)
+	if published > 0 {
+		ids := make([]int64, published)
+		for i := range ids {
+			ids[i] = int64(events[i].ID)
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE outbox SET published_at = now() WHERE id = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return published, publishErr
+}
+
+func unpublishedEvents(ctx context.Context, tx *sql.Tx, limit uint64) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+	querySQL := `SELECT id, aggregate_id, event_type, payload, created_at
+					FROM outbox
+					WHERE published_at IS NULL
+					ORDER BY id
+					LIMIT $1`
+	rows, err := tx.QueryContext(ctx, querySQL, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var event OutboxEvent
+		err = rows.Scan(&event.ID, &event.AggregateID, &event.Type, &event.Payload, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+//insertEvent writes the event of the order to the outbox in the transaction which changes the order.
+func insertEvent(ctx context.Context, tx *sql.Tx, eventType string, order *proto.Order) error {
+	payload, err := protobuf.Marshal(order)
+	if err != nil {
+		return err
+	}
+
+	querySQL := `INSERT INTO outbox(aggregate_id, event_type, payload) VALUES ($1, $2, $3)`
+	_, err = tx.ExecContext(ctx, querySQL, order.Id, eventType, payload)
+	return err
+}
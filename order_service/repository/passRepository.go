@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/proto"
+	"time"
+)
+
+//PassRepository the interface which implemented by functions which store the passes of the users.
+type PassRepository interface {
+	BuyPass(ctx context.Context, pass *proto.Pass, price uint64) (*proto.Pass, error)
+	GetPasses(ctx context.Context, userID uint64, at time.Time) ([]*proto.Pass, error)
+	UseMinutes(ctx context.Context, passID, minutes uint64) (uint64, error)
+	ReturnMinutes(ctx context.Context, passID, minutes uint64) error
+}
+
+type PassRepo struct {
+	db *sql.DB
+}
+
+func NewPassRepo(db *sql.DB) *PassRepo {
+	return &PassRepo{db: db}
+}
+
+//BuyPass pays the price of the pass from the wallet of the user and saves the pass.
+func (pr *PassRepo) BuyPass(ctx context.Context, pass *proto.Pass, price uint64) (*proto.Pass, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	querySQL := `INSERT INTO passes(user_id, plan, minutes_total, unlimited_unlocks, starts_at, expires_at)
+					VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, pass.UserID, pass.Plan, pass.MinutesTotal, pass.UnlimitedUnlocks,
+		pass.StartsAt.AsTime(), pass.ExpiresAt.AsTime()).Scan(&pass.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = post(ctx, tx, Transaction{Kind: "pass", UserID: pass.UserID, Reference: fmt.Sprintf("pass:%d", pass.Id),
+		Entries: []Entry{
+			{UserID: pass.UserID, Account: AccountAvailable, Amount: -int64(price)},
+			{Account: AccountRevenue, Amount: int64(price)},
+		}})
+	if err != nil {
+		return nil, err
+	}
+
+	pass.MinutesRemaining = pass.MinutesTotal
+	return pass, tx.Commit()
+}
+
+//GetPasses returns the passes of the user which are valid at the given time, the earliest expiring first.
+func (pr *PassRepo) GetPasses(ctx context.Context, userID uint64, at time.Time) ([]*proto.Pass, error) {
+	passes := []*proto.Pass{}
+	querySQL := `SELECT id, user_id, plan, minutes_total, minutes_used, unlimited_unlocks, starts_at, expires_at
+					FROM passes
+					WHERE user_id = $1 AND starts_at <= $2 AND expires_at > $2
+					ORDER BY expires_at, id`
+	rows, err := pr.db.QueryContext(ctx, querySQL, userID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		pass := &proto.Pass{}
+		var startsAt, expiresAt time.Time
+		err = rows.Scan(&pass.Id, &pass.UserID, &pass.Plan, &pass.MinutesTotal, &pass.MinutesUsed,
+			&pass.UnlimitedUnlocks, &startsAt, &expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		pass.MinutesRemaining = pass.MinutesTotal - pass.MinutesUsed
+		pass.StartsAt, pass.ExpiresAt = timestamppb.New(startsAt), timestamppb.New(expiresAt)
+		passes = append(passes, pass)
+	}
+	return passes, rows.Err()
+}
+
+//UseMinutes takes up to the given number of minutes from the pass and returns how many were taken.
+func (pr *PassRepo) UseMinutes(ctx context.Context, passID, minutes uint64) (uint64, error) {
+	var used uint64
+	querySQL := `UPDATE passes p SET minutes_used = LEAST(p.minutes_total, p.minutes_used + $2)
+					FROM (SELECT id, minutes_used FROM passes WHERE id = $1 FOR UPDATE) old
+					WHERE p.id = old.id
+					RETURNING p.minutes_used - old.minutes_used`
+	err := pr.db.QueryRowContext(ctx, querySQL, passID, minutes).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return used, err
+}
+
+//ReturnMinutes gives back the minutes taken for the order which wasn't created.
+func (pr *PassRepo) ReturnMinutes(ctx context.Context, passID, minutes uint64) error {
+	querySQL := `UPDATE passes SET minutes_used = GREATEST(0, minutes_used - $2) WHERE id = $1`
+	_, err := pr.db.ExecContext(ctx, querySQL, passID, minutes)
+	return err
+}
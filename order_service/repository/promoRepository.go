@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/proto"
+)
+
+var (
+	ErrPromoNotFound = errors.New("promo code not found")
+	ErrPromoExists   = errors.New("promo code already exists")
+)
+
+//PromoRepository the interface which implemented by functions which store the promo codes and their redemptions.
+type PromoRepository interface {
+	CreatePromo(ctx context.Context, promo *proto.Promo) (*proto.Promo, error)
+	Redeem(ctx context.Context, code string, userID uint64,
+		check func(promo *proto.Promo, total, byUser uint64) error) (*proto.Promo, uint64, error)
+	SetRedemptionOrder(ctx context.Context, redemptionID, orderID uint64) error
+	DeleteRedemption(ctx context.Context, redemptionID uint64) error
+}
+
+type PromoRepo struct {
+	db *sql.DB
+}
+
+func NewPromoRepo(db *sql.DB) *PromoRepo {
+	return &PromoRepo{db: db}
+}
+
+//CreatePromo inserts the promo code and returns it with the new ID.
+func (pr *PromoRepo) CreatePromo(ctx context.Context, promo *proto.Promo) (*proto.Promo, error) {
+	querySQL := `INSERT INTO promo_codes(code, kind, value, first_ride_only, max_redemptions, per_user_limit,
+					valid_from, valid_until)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	err := pr.db.QueryRowContext(ctx, querySQL, promo.Code, promo.Kind, promo.Value, promo.FirstRideOnly,
+		promo.MaxRedemptions, promo.PerUserLimit, nullTime(promo.ValidFrom), nullTime(promo.ValidUntil)).Scan(&promo.Id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return nil, ErrPromoExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+//Redeem records the redemption of the promo code by the user if check allows it. The promo code is locked
+//while its redemptions are counted, so the limits hold for the concurrent orders.
+//It returns the promo code and the ID of the redemption.
+func (pr *PromoRepo) Redeem(ctx context.Context, code string, userID uint64,
+	check func(promo *proto.Promo, total, byUser uint64) error) (*proto.Promo, uint64, error) {
+	tx, err := pr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	promo := &proto.Promo{}
+	var validFrom, validUntil sql.NullTime
+	querySQL := `SELECT id, code, kind, value, first_ride_only, max_redemptions, per_user_limit, valid_from, valid_until
+					FROM promo_codes
+					WHERE code = $1
+					FOR UPDATE`
+	err = tx.QueryRowContext(ctx, querySQL, code).Scan(&promo.Id, &promo.Code, &promo.Kind, &promo.Value,
+		&promo.FirstRideOnly, &promo.MaxRedemptions, &promo.PerUserLimit, &validFrom, &validUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, ErrPromoNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if validFrom.Valid {
+		promo.ValidFrom = timestamppb.New(validFrom.Time)
+	}
+	if validUntil.Valid {
+		promo.ValidUntil = timestamppb.New(validUntil.Time)
+	}
+
+	var total, byUser uint64
+	querySQL = `SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2) FROM promo_redemptions WHERE promo_id = $1`
+	err = tx.QueryRowContext(ctx, querySQL, promo.Id, userID).Scan(&total, &byUser)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = check(promo, total, byUser)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var redemptionID uint64
+	querySQL = `INSERT INTO promo_redemptions(promo_id, user_id) VALUES ($1, $2) RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, promo.Id, userID).Scan(&redemptionID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return promo, redemptionID, tx.Commit()
+}
+
+//SetRedemptionOrder links the redemption to the order which got the discount.
+func (pr *PromoRepo) SetRedemptionOrder(ctx context.Context, redemptionID, orderID uint64) error {
+	_, err := pr.db.ExecContext(ctx, `UPDATE promo_redemptions SET order_id = $1 WHERE id = $2`, orderID,
+		redemptionID)
+	return err
+}
+
+//DeleteRedemption cancels the redemption of the order which wasn't created.
+func (pr *PromoRepo) DeleteRedemption(ctx context.Context, redemptionID uint64) error {
+	_, err := pr.db.ExecContext(ctx, `DELETE FROM promo_redemptions WHERE id = $1`, redemptionID)
+	return err
+}
+
+func nullTime(t *timestamppb.Timestamp) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.AsTime(), Valid: true}
+}
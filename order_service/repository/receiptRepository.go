@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/proto"
+	"time"
+)
+
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+//ReceiptRepository the interface which implemented by functions which store the rendered receipts.
+type ReceiptRepository interface {
+	GetReceipt(ctx context.Context, orderID uint64) (*proto.Receipt, error)
+	SaveReceipt(ctx context.Context, receipt *proto.Receipt) (*proto.Receipt, error)
+}
+
+type ReceiptRepo struct {
+	db *sql.DB
+}
+
+func NewReceiptRepo(db *sql.DB) *ReceiptRepo {
+	return &ReceiptRepo{db: db}
+}
+
+//GetReceipt returns the stored receipt of the order.
+func (rr *ReceiptRepo) GetReceipt(ctx context.Context, orderID uint64) (*proto.Receipt, error) {
+	receipt := &proto.Receipt{OrderID: orderID}
+	var createdAt time.Time
+	querySQL := `SELECT text, html, json, created_at FROM receipts WHERE order_id = $1`
+	err := rr.db.QueryRowContext(ctx, querySQL, orderID).Scan(&receipt.Text, &receipt.Html, &receipt.Json,
+		&createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	receipt.CreatedAt = timestamppb.New(createdAt)
+	return receipt, nil
+}
+
+//SaveReceipt stores the receipt unless the order already has one, and returns the stored receipt.
+func (rr *ReceiptRepo) SaveReceipt(ctx context.Context, receipt *proto.Receipt) (*proto.Receipt, error) {
+	querySQL := `INSERT INTO receipts(order_id, text, html, json, created_at) VALUES ($1, $2, $3, $4, $5)
+					ON CONFLICT (order_id) DO NOTHING`
+	_, err := rr.db.ExecContext(ctx, querySQL, receipt.OrderID, receipt.Text, receipt.Html, receipt.Json,
+		receipt.CreatedAt.AsTime())
+	if err != nil {
+		return nil, err
+	}
+	return rr.GetReceipt(ctx, receipt.OrderID)
+}
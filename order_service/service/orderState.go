@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order_micro/orderstate"
+	"order_micro/proto"
+	"order_micro/repository"
+)
+
+//actorSystem is the actor of the state changes made by the order service itself.
+const actorSystem = "system"
+
+//StartOrder holds the trip deposit in the wallet of the user and starts the order of the trip.
+func (os *OrderService) StartOrder(ctx context.Context, info *proto.TripInfo) (*proto.Order, error) {
+	hold, err := os.Ledger.CreateHold(ctx, info.UserID, os.Tariff.Deposit)
+	if err != nil {
+		return nil, walletError(err)
+	}
+
+	order, err := os.Repo.CreateOrder(ctx, &proto.Order{UserID: info.UserID, ScooterID: info.ScooterID,
+		StatusStartID: info.StatusStartID, HoldID: hold.Id, State: orderstate.Started})
+	if err != nil {
+		_, releaseErr := os.Ledger.ReleaseHold(ctx, hold.Id)
+		if releaseErr != nil {
+			fmt.Println(releaseErr)
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+//ConfirmOrder moves the started order in progress when the scooter is unlocked.
+//The disputed order is confirmed as completed when the dispute is rejected.
+func (os *OrderService) ConfirmOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	order, err := os.Repo.GetOrder(ctx, change.OrderID)
+	if err != nil {
+		return nil, orderError(err)
+	}
+
+	to := orderstate.InProgress
+	if order.State == orderstate.Disputed {
+		to = orderstate.Completed
+	}
+	order, err = os.Repo.ChangeState(ctx, change, to)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return order, nil
+}
+
+//CancelOrder cancels the started order and releases its deposit. The order in progress belongs to the active trip
+//and can't be cancelled.
+func (os *OrderService) CancelOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	order, err := os.Repo.CancelOrder(ctx, change)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return order, nil
+}
+
+//RefundOrder returns the whole or a part of the paid order to the wallet of the user.
+func (os *OrderService) RefundOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	order, err := os.Repo.RefundOrder(ctx, change)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return order, nil
+}
+
+//DisputeOrder marks the completed order as disputed until it is refunded or confirmed.
+func (os *OrderService) DisputeOrder(ctx context.Context, change *proto.OrderChange) (*proto.Order, error) {
+	order, err := os.Repo.ChangeState(ctx, change, orderstate.Disputed)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return order, nil
+}
+
+//GetOrder returns the order with its state transitions.
+func (os *OrderService) GetOrder(ctx context.Context, id *proto.OrderID) (*proto.Order, error) {
+	order, err := os.Repo.GetOrder(ctx, id.Id)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	return order, nil
+}
+
+func orderError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, orderstate.ErrInvalidTransition), errors.Is(err, repository.ErrTripActive):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrRefundExceeded):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return walletError(err)
+	}
+}
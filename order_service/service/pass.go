@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"order_micro/passes"
+	"order_micro/pricing"
+	"order_micro/proto"
+	"time"
+)
+
+//passUse is the number of minutes taken from the pass for the order.
+type passUse struct {
+	passID  uint64
+	minutes uint64
+}
+
+//GetPassPlans returns the plans of the passes which can be bought.
+func (os *OrderService) GetPassPlans(ctx context.Context, request *proto.PassPlansRequest) (*proto.PassPlanList,
+	error) {
+	return os.Catalog.List(), nil
+}
+
+//BuyPass pays the pass from the wallet of the user, the pass is valid from now for the duration of its plan.
+func (os *OrderService) BuyPass(ctx context.Context, request *proto.BuyPassRequest) (*proto.Pass, error) {
+	plan, err := os.Catalog.Plan(request.Plan)
+	if errors.Is(err, passes.ErrUnknownPlan) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pass, err := os.Passes.BuyPass(ctx, &proto.Pass{UserID: request.UserID, Plan: plan.Name,
+		MinutesTotal: plan.Minutes, UnlimitedUnlocks: plan.UnlimitedUnlocks, StartsAt: timestamppb.New(now),
+		ExpiresAt: timestamppb.New(now.Add(plan.Duration))}, plan.Price)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return pass, nil
+}
+
+//GetPasses returns the valid passes of the user with their remaining minutes.
+func (os *OrderService) GetPasses(ctx context.Context, request *proto.WalletRequest) (*proto.PassList, error) {
+	list, err := os.Passes.GetPasses(ctx, request.UserID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &proto.PassList{Passes: list}, nil
+}
+
+//usePasses takes the riding minutes of the trip from the valid passes of the user, the earliest expiring first.
+//The unlock is free if any of the passes has unlimited unlocks.
+func (os *OrderService) usePasses(ctx context.Context, userID uint64, ride time.Duration) (pricing.Allowance,
+	[]passUse, error) {
+	var allowance pricing.Allowance
+	valid, err := os.Passes.GetPasses(ctx, userID, time.Now())
+	if err != nil {
+		return allowance, nil, err
+	}
+
+	var uses []passUse
+	needed := pricing.RideMinutes(ride)
+	for _, pass := range valid {
+		if pass.UnlimitedUnlocks {
+			allowance.FreeUnlock = true
+			uses = append(uses, passUse{passID: pass.Id})
+		}
+		if needed == 0 || pass.MinutesRemaining == 0 {
+			continue
+		}
+
+		used, err := os.Passes.UseMinutes(ctx, pass.Id, needed)
+		if err != nil {
+			os.returnMinutes(ctx, uses)
+			return pricing.Allowance{}, nil, err
+		}
+		if used > 0 {
+			uses = append(uses, passUse{passID: pass.Id, minutes: used})
+			allowance.Minutes += used
+			needed -= used
+		}
+	}
+	return allowance, uses, nil
+}
+
+//returnMinutes gives back the minutes taken for the order which wasn't created.
+func (os *OrderService) returnMinutes(ctx context.Context, uses []passUse) {
+	for _, use := range uses {
+		if use.minutes == 0 {
+			continue
+		}
+		err := os.Passes.ReturnMinutes(ctx, use.passID, use.minutes)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+}
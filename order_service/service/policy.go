@@ -0,0 +1,30 @@
+package service
+
+import (
+	"order_micro/auth"
+)
+
+const orderServiceMethod = "/proto.OrderService/"
+
+var services = []auth.Role{auth.RoleService}
+
+//Policy lists the roles allowed to call every OrderService method. The orders, the wallets and the promos are
+//changed only through scooter_server, which checks the roles and the owners of its users.
+var Policy = auth.Policy{
+	orderServiceMethod + "CreateOrder":  services,
+	orderServiceMethod + "StartOrder":   services,
+	orderServiceMethod + "ConfirmOrder": services,
+	orderServiceMethod + "CancelOrder":  services,
+	orderServiceMethod + "RefundOrder":  services,
+	orderServiceMethod + "DisputeOrder": services,
+	orderServiceMethod + "GetOrder":     services,
+	orderServiceMethod + "GetReceipt":   services,
+	orderServiceMethod + "GetBalance":   services,
+	orderServiceMethod + "TopUp":        services,
+	orderServiceMethod + "PlaceHold":    services,
+	orderServiceMethod + "ReleaseHold":  services,
+	orderServiceMethod + "CreatePromo":  services,
+	orderServiceMethod + "GetPassPlans": services,
+	orderServiceMethod + "BuyPass":      services,
+	orderServiceMethod + "GetPasses":    services,
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"order_micro/proto"
+	"testing"
+)
+
+func TestPolicyCoversOrderService(t *testing.T) {
+	served := make(map[string]bool)
+	for _, method := range proto.OrderService_ServiceDesc.Methods {
+		served[orderServiceMethod+method.MethodName] = true
+		if _, ok := Policy[orderServiceMethod+method.MethodName]; !ok {
+			t.Errorf("%v isn't in the policy, nobody can call it", method.MethodName)
+		}
+	}
+	for method := range Policy {
+		if !served[method] {
+			t.Errorf("policy lists %v which isn't served", method)
+		}
+	}
+}
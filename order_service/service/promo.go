@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order_micro/discount"
+	"order_micro/proto"
+	"order_micro/repository"
+	"time"
+)
+
+//CreatePromo validates and saves the new promo code.
+func (os *OrderService) CreatePromo(ctx context.Context, promo *proto.Promo) (*proto.Promo, error) {
+	promo.Code = discount.Normalize(promo.Code)
+	err := discount.Validate(promo)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	promo, err = os.Promos.CreatePromo(ctx, promo)
+	if errors.Is(err, repository.ErrPromoExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	return promo, err
+}
+
+//redeem redeems the promo code for the order of the user if its validity window and limits allow it.
+func (os *OrderService) redeem(ctx context.Context, userID uint64, code string) (*proto.Promo, uint64, error) {
+	previousOrders, err := os.Repo.CountUserOrders(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	return os.Promos.Redeem(ctx, discount.Normalize(code), userID,
+		func(promo *proto.Promo, total, byUser uint64) error {
+			return discount.Check(promo, now, discount.Usage{Total: total, ByUser: byUser,
+				PreviousOrders: previousOrders})
+		})
+}
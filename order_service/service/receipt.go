@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order_micro/orderstate"
+	"order_micro/proto"
+	"order_micro/receipt"
+	"order_micro/repository"
+)
+
+//GetReceipt returns the receipt of the completed order. The receipt is rendered on the first request
+//if it wasn't issued when the order was completed.
+func (os *OrderService) GetReceipt(ctx context.Context, id *proto.OrderID) (*proto.Receipt, error) {
+	stored, err := os.Receipts.GetReceipt(ctx, id.Id)
+	if err == nil {
+		return stored, nil
+	}
+	if !errors.Is(err, repository.ErrReceiptNotFound) {
+		return nil, err
+	}
+
+	order, err := os.Repo.GetOrder(ctx, id.Id)
+	if err != nil {
+		return nil, orderError(err)
+	}
+	switch order.State {
+	case orderstate.Completed, orderstate.Refunded, orderstate.Disputed:
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "order %d isn't completed", order.Id)
+	}
+	return os.issueReceipt(ctx, order)
+}
+
+//issueReceipt renders and stores the receipt of the completed order.
+func (os *OrderService) issueReceipt(ctx context.Context, order *proto.Order) (*proto.Receipt, error) {
+	rendered, err := receipt.New(order).Render()
+	if err != nil {
+		return nil, fmt.Errorf("render receipt of order %d: %w", order.Id, err)
+	}
+	return os.Receipts.SaveReceipt(ctx, rendered)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"order_micro/orderstate"
+	"order_micro/proto"
+	"order_micro/repository"
+	"testing"
+)
+
+//ordersStub returns the orders by their IDs, the other methods of the repository aren't used by the receipts.
+type ordersStub struct {
+	repository.OrderRepository
+	orders map[uint64]*proto.Order
+}
+
+func (stub *ordersStub) GetOrder(ctx context.Context, id uint64) (*proto.Order, error) {
+	order, ok := stub.orders[id]
+	if !ok {
+		return nil, repository.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+//receiptStore keeps the first receipt of every order like the receipts table does.
+type receiptStore struct {
+	receipts map[uint64]*proto.Receipt
+	saves    int
+}
+
+func (rs *receiptStore) GetReceipt(ctx context.Context, orderID uint64) (*proto.Receipt, error) {
+	receipt, ok := rs.receipts[orderID]
+	if !ok {
+		return nil, repository.ErrReceiptNotFound
+	}
+	return receipt, nil
+}
+
+func (rs *receiptStore) SaveReceipt(ctx context.Context, receipt *proto.Receipt) (*proto.Receipt, error) {
+	rs.saves++
+	if _, ok := rs.receipts[receipt.OrderID]; !ok {
+		rs.receipts[receipt.OrderID] = receipt
+	}
+	return rs.receipts[receipt.OrderID], nil
+}
+
+func newReceiptService(orders ...*proto.Order) (*OrderService, *receiptStore) {
+	stub := &ordersStub{orders: make(map[uint64]*proto.Order)}
+	for _, order := range orders {
+		stub.orders[order.Id] = order
+	}
+	store := &receiptStore{receipts: make(map[uint64]*proto.Receipt)}
+	return &OrderService{Repo: stub, Receipts: store}, store
+}
+
+func TestGetReceiptIssuedOnce(t *testing.T) {
+	order := &proto.Order{Id: 1, State: orderstate.Completed, RideSeconds: 600,
+		Price: &proto.PriceBreakdown{Unlock: 100, Ride: 300, Total: 400, RidePerMinute: 30}}
+	os, store := newReceiptService(order)
+	ctx := context.Background()
+
+	first, err := os.GetReceipt(ctx, &proto.OrderID{Id: 1})
+	if err != nil {
+		t.Fatalf("GetReceipt() error = %v", err)
+	}
+
+	//The order changes after the receipt was issued, the receipt stays as it was issued.
+	order.State = orderstate.Refunded
+	order.Price = &proto.PriceBreakdown{Unlock: 200, Ride: 600, Total: 800, RidePerMinute: 60}
+
+	second, err := os.GetReceipt(ctx, &proto.OrderID{Id: 1})
+	if err != nil {
+		t.Fatalf("GetReceipt() error = %v", err)
+	}
+	if second != first || second.Text != first.Text || !second.CreatedAt.AsTime().Equal(first.CreatedAt.AsTime()) {
+		t.Errorf("GetReceipt() returned another receipt after it was issued:\n%s\nwant\n%s", second.Text, first.Text)
+	}
+
+	reissued, err := os.issueReceipt(ctx, order)
+	if err != nil {
+		t.Fatalf("issueReceipt() error = %v", err)
+	}
+	if reissued != first {
+		t.Errorf("issueReceipt() replaced the issued receipt:\n%s\nwant\n%s", reissued.Text, first.Text)
+	}
+	if store.saves != 2 {
+		t.Errorf("receipt saved %d times, want 2", store.saves)
+	}
+}
+
+func TestGetReceiptErrors(t *testing.T) {
+	os, store := newReceiptService(&proto.Order{Id: 1, State: orderstate.InProgress})
+
+	tests := []struct {
+		name string
+		id   uint64
+		want codes.Code
+	}{
+		{name: "not completed", id: 1, want: codes.FailedPrecondition},
+		{name: "unknown order", id: 2, want: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := os.GetReceipt(context.Background(), &proto.OrderID{Id: tt.id})
+			if status.Code(err) != tt.want {
+				t.Errorf("GetReceipt(%d) error = %v, want %v", tt.id, err, tt.want)
+			}
+		})
+	}
+	if len(store.receipts) != 0 {
+		t.Errorf("receipts issued for the orders which aren't completed: %v", store.receipts)
+	}
+}
@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"order_micro/discount"
+	"order_micro/passes"
+	"order_micro/payment"
+	"order_micro/pricing"
 	"order_micro/proto"
 	"order_micro/repository"
+	"time"
 )
 
 type OrderInterface interface {
@@ -11,14 +17,105 @@ type OrderInterface interface {
 }
 
 type OrderService struct {
-	Repo *repository.OrderRepo
+	Repo     repository.OrderRepository
+	Ledger   *repository.LedgerRepo
+	Promos   *repository.PromoRepo
+	Passes   *repository.PassRepo
+	Catalog  *passes.Catalog
+	Receipts repository.ReceiptRepository
+	Payments payment.Provider
+	Tariff   pricing.Tariff
 	*proto.UnimplementedOrderServiceServer
 }
 
-func NewOrderService(repo *repository.OrderRepo) *OrderService {
-	return &OrderService{Repo: repo}
+func NewOrderService(repo repository.OrderRepository, ledger *repository.LedgerRepo, promos *repository.PromoRepo,
+	passRepo *repository.PassRepo, receipts repository.ReceiptRepository, payments payment.Provider,
+	tariff pricing.Tariff, catalog *passes.Catalog) *OrderService {
+	return &OrderService{Repo: repo, Ledger: ledger, Promos: promos, Passes: passRepo, Receipts: receipts,
+		Payments: payments, Tariff: tariff, Catalog: catalog}
 }
 
+//CreateOrder prices the trip and saves the order. The riding and paused time are taken from the trip events,
+//trips without events are priced as riding from the start to the end status.
+//The riding minutes and the unlock covered by the passes of the user are free.
+//The promo code of the trip is applied if it can be redeemed, otherwise the trip is priced without the discount.
+//The order started with the trip is completed, trips without the order get the new completed order.
+//The order is paid by the hold placed at the trip start or by the wallet of the user in the transaction which
+//completes it, then its receipt is issued. The order which can't be paid isn't completed and the error is returned.
 func (os *OrderService) CreateOrder(ctx context.Context, info *proto.TripInfo) (*proto.Order, error) {
-	return os.Repo.CreateOrder(ctx, info)
-}
\ No newline at end of file
+	ride, pause := pricing.Durations(info.Events)
+	if len(info.Events) == 0 {
+		start, end, err := os.Repo.GetStatusTimes(ctx, info.StatusStartID, info.StatusEndID)
+		if err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+		if end.After(start) {
+			ride = end.Sub(start)
+		}
+	}
+
+	allowance, uses, err := os.usePasses(ctx, info.UserID, ride)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+
+	order := &proto.Order{
+		UserID:        info.UserID,
+		ScooterID:     info.ScooterID,
+		StatusStartID: info.StatusStartID,
+		StatusEndID:   info.StatusEndID,
+		RideSeconds:   uint64(ride / time.Second),
+		PauseSeconds:  uint64(pause / time.Second),
+		Price:         os.Tariff.PriceWithAllowance(ride, pause, allowance),
+		HoldID:        info.HoldID,
+		Distance:      info.Distance,
+	}
+	if len(uses) > 0 {
+		order.PassID = uses[0].passID
+	}
+
+	var redemptionID uint64
+	if info.PromoCode != "" {
+		promo, id, err := os.redeem(ctx, info.UserID, info.PromoCode)
+		if err != nil {
+			fmt.Printf("promo code %q isn't applied: %v\n", info.PromoCode, err)
+		} else {
+			discount.Apply(promo, order.Price)
+			order.PromoCode = promo.Code
+			redemptionID = id
+		}
+	}
+
+	if info.OrderID != 0 {
+		order.Id = info.OrderID
+		order, err = os.Repo.CompleteOrder(ctx, order, &proto.OrderChange{OrderID: info.OrderID, Actor: actorSystem,
+			Reason: "trip ended"})
+	} else {
+		order, err = os.Repo.CreateOrder(ctx, order)
+	}
+	if err != nil {
+		os.returnMinutes(ctx, uses)
+		if redemptionID != 0 {
+			deleteErr := os.Promos.DeleteRedemption(ctx, redemptionID)
+			if deleteErr != nil {
+				fmt.Println(deleteErr)
+			}
+		}
+		return nil, orderError(err)
+	}
+
+	if redemptionID != 0 {
+		err = os.Promos.SetRedemptionOrder(ctx, redemptionID, order.Id)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	_, err = os.issueReceipt(ctx, order)
+	if err != nil {
+		fmt.Println(err)
+	}
+	return order, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math"
+	"order_micro/payment"
+	"order_micro/proto"
+	"order_micro/repository"
+)
+
+//maxAmount is the largest amount in cents the ledger can record.
+const maxAmount = math.MaxInt64
+
+//GetBalance returns the wallet balance of the user.
+func (os *OrderService) GetBalance(ctx context.Context, request *proto.WalletRequest) (*proto.Balance, error) {
+	return os.Ledger.GetBalance(ctx, request.UserID)
+}
+
+//TopUp charges the amount by the payment provider and adds it to the wallet of the user.
+//The payment is refunded if it can't be recorded in the ledger.
+func (os *OrderService) TopUp(ctx context.Context, request *proto.TopUpRequest) (*proto.Balance, error) {
+	if request.Amount == 0 || request.Amount > maxAmount {
+		return nil, status.Errorf(codes.InvalidArgument, "top-up amount must be from 1 to %d cents", uint64(maxAmount))
+	}
+
+	paymentID, err := os.Payments.Charge(ctx, request.UserID, request.Amount)
+	if err != nil {
+		return nil, walletError(err)
+	}
+
+	err = os.Ledger.Post(ctx, repository.Transaction{Kind: "topup", UserID: request.UserID, Reference: paymentID,
+		Entries: []repository.Entry{
+			{Account: repository.AccountProvider, Amount: -int64(request.Amount)},
+			{UserID: request.UserID, Account: repository.AccountAvailable, Amount: int64(request.Amount)},
+		}})
+	if err != nil {
+		refundErr := os.Payments.Refund(ctx, paymentID, request.Amount)
+		if refundErr != nil {
+			fmt.Println(refundErr)
+		}
+		return nil, walletError(err)
+	}
+	return os.Ledger.GetBalance(ctx, request.UserID)
+}
+
+//PlaceHold reserves the money of the user for the trip. Zero amount reserves the deposit of the tariff.
+func (os *OrderService) PlaceHold(ctx context.Context, request *proto.HoldRequest) (*proto.Hold, error) {
+	amount := request.Amount
+	if amount == 0 {
+		amount = os.Tariff.Deposit
+	}
+	if amount > maxAmount {
+		return nil, status.Errorf(codes.InvalidArgument, "hold amount must be at most %d cents", uint64(maxAmount))
+	}
+
+	hold, err := os.Ledger.CreateHold(ctx, request.UserID, amount)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return hold, nil
+}
+
+//ReleaseHold returns the reserved money of the trip which was cancelled.
+func (os *OrderService) ReleaseHold(ctx context.Context, request *proto.HoldID) (*proto.Hold, error) {
+	hold, err := os.Ledger.ReleaseHold(ctx, request.Id)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return hold, nil
+}
+
+func walletError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, repository.ErrHoldClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrHoldNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, payment.ErrDeclined):
+		return status.Error(codes.Aborted, err.Error())
+	default:
+		return err
+	}
+}
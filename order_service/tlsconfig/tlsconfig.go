@@ -0,0 +1,87 @@
+//Package tlsconfig loads the TLS credentials of the gRPC servers and clients. Like the events package,
+//it is copied into every module and the copies must be kept the same until they move into a shared module.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"io/ioutil"
+)
+
+//Config holds the paths to the PEM files of the certificates.
+//TLS is disabled without the certificate, mutual TLS is enabled when the CA is given too.
+type Config struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	ServerName string
+}
+
+//Enabled reports whether the certificate is configured.
+func (c Config) Enabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+//ServerCredentials returns the credentials of the gRPC server. With the CA the server requires
+//the clients to present a certificate signed by it.
+func ServerCredentials(c Config) (credentials.TransportCredentials, error) {
+	if !c.Enabled() {
+		return insecure.NewCredentials(), nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if c.CAFile != "" {
+		pool, err := loadCA(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+//DialCredentials returns the credentials of the gRPC client. The server is verified by the CA,
+//the certificate is presented to the servers which require mutual TLS.
+func DialCredentials(c Config) (credentials.TransportCredentials, error) {
+	if !c.Enabled() && c.CAFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{ServerName: c.ServerName, MinVersion: tls.VersionTLS12}
+	if c.Enabled() {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		pool, err := loadCA(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+	return credentials.NewTLS(config), nil
+}
+
+func loadCA(caFile string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %v", caFile)
+	}
+	return pool, nil
+}
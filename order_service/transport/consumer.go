@@ -3,7 +3,10 @@ package transport
 import (
 	"context"
 	"github.com/Shopify/sarama"
+	protobuf "google.golang.org/protobuf/proto"
 	"log"
+	"order_micro/events"
+	"order_micro/proto"
 	"sync"
 )
 
@@ -61,11 +64,38 @@ func (consumer *Consumer) Cleanup(session sarama.ConsumerGroupSession) error {
 	return nil
 }
 
+//ConsumeClaim reads both the event envelopes and the legacy JSON messages. Messages which can't be read
+//by this version of the service are logged and skipped.
+//The group calls ConsumeClaim in its own goroutine for every claimed partition, so the partitions are processed
+//in parallel, while the messages of a partition, and so the statuses of a scooter, are processed in order.
 func (consumer *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	for message := range claim.Messages() {
-		log.Printf("Kafka: value=%s, time=%v, topic=%s", string(message.Value), message.Timestamp, message.Topic)
+		err := handleMessage(message)
+		if err != nil {
+			log.Printf("Kafka: skipped message topic=%s, partition=%d, offset=%d: %v", message.Topic,
+				message.Partition, message.Offset, err)
+		}
 		session.MarkMessage(message, "")
 	}
 
 	return nil
 }
+
+func handleMessage(message *sarama.ConsumerMessage) error {
+	envelope, err := events.Decode(message)
+	if err != nil {
+		return err
+	}
+
+	switch envelope.EventType {
+	case events.TypeScooterStatusReported:
+		status := &proto.ScooterStatusReported{}
+		err = protobuf.Unmarshal(envelope.Payload, status)
+		if err != nil {
+			return err
+		}
+		log.Printf("Kafka: event=%s, version=%d, id=%s, status=%v, time=%v, topic=%s", envelope.EventType,
+			envelope.SchemaVersion, envelope.EventID, status, envelope.OccurredAt.AsTime(), message.Topic)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"errors"
+	"github.com/Shopify/sarama"
+	"log"
+)
+
+//CreateProducer creates the producer which keeps the order of the messages with the same key.
+//The messages are partitioned by the hash of the key and only one request is in flight while retrying.
+func CreateProducer(brokerList []string, clientID string) sarama.SyncProducer {
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 10
+	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	config.Net.MaxOpenRequests = 1
+	config.ClientID = clientID
+
+	producer, err := sarama.NewSyncProducer(brokerList, config)
+	if err != nil {
+		log.Fatalln("Failed to start Sarama producer:", err)
+	}
+
+	return producer
+}
+
+//CreateTopic creates the topic with nPartitions partitions. The existing topic which has fewer partitions
+//is extended, so the number of partitions can be raised by the configuration. Messages already written
+//keep their partitions, only the new messages of a key may be moved to another partition.
+func CreateTopic(brokerList []string, topicName string, nPartitions int32, replicas int16) error {
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+
+	admin, err := sarama.NewClusterAdmin(brokerList, config)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = admin.Close() }()
+
+	err = admin.CreateTopic(topicName, &sarama.TopicDetail{
+		NumPartitions:     nPartitions,
+		ReplicationFactor: replicas,
+	}, false)
+	var topicErr *sarama.TopicError
+	if !errors.As(err, &topicErr) || topicErr.Err != sarama.ErrTopicAlreadyExists {
+		return err
+	}
+
+	topics, err := admin.DescribeTopics([]string{topicName})
+	if err != nil {
+		return err
+	}
+	if len(topics) == 0 || len(topics[0].Partitions) >= int(nPartitions) {
+		return nil
+	}
+	return admin.CreatePartitions(topicName, nPartitions, nil, false)
+}
@@ -2,18 +2,19 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"github.com/Shopify/sarama"
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
 	"io"
 	"log"
 	"scooter_client/config"
+	"scooter_client/events"
 	"scooter_client/model"
 	"scooter_client/proto"
 	"scooter_client/service"
+	"scooter_client/tlsconfig"
 	"scooter_client/transport"
+	"strconv"
 	"time"
 )
 
@@ -24,7 +25,24 @@ const TopicName = "order"
 
 
 func main() {
-	conn, err := grpc.Dial(config.SERVER_CONN_GRPC_ADDRESS, grpc.WithInsecure() )
+	transportCredentials, err := tlsconfig.DialCredentials(tlsconfig.Config{CertFile: config.TLS_CERT_FILE,
+		KeyFile: config.TLS_KEY_FILE, CAFile: config.TLS_CA_FILE, ServerName: config.SERVER_TLS_SERVER_NAME})
+	if err != nil {
+		log.Fatalf("failed to load TLS credentials: %v", err)
+	}
+
+	//The scooter with its own certificate is identified by it, the shared device token is the fallback
+	//for the scooters without the certificates and is never committed to the configuration.
+	options := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if config.TLS_CERT_FILE == "" {
+		if config.DEVICE_TOKEN == "" {
+			log.Fatalln("either TLS_CERT_FILE with the certificate of the scooter or DEVICE_TOKEN is required")
+		}
+		options = append(options, grpc.WithPerRPCCredentials(transport.TokenCredentials{Token: config.DEVICE_TOKEN,
+			Insecure: config.TLS_CA_FILE == ""}))
+	}
+
+	conn, err := grpc.Dial(config.SERVER_CONN_GRPC_ADDRESS, options...)
 	if err != nil {
 		log.Printf("gRPC connection to %v port failed. With: %v\n", config.GRPC_PORT, err)
 	}
@@ -46,8 +64,8 @@ func main() {
 
 	producer := transport.CreateProducer([]string{config.KAFKA_BROKER}, ClientID)
 
-	err = transport.CreateTopic([]string{config.KAFKA_BROKER}, TopicName, 1, 1)
-	if err != nil && err.(*sarama.TopicError).Err != sarama.ErrTopicAlreadyExists {
+	err = transport.CreateTopic([]string{config.KAFKA_BROKER}, TopicName, int32(config.KAFKA_PARTITIONS), 1)
+	if err != nil {
 		log.Fatalln("Failed to create kafka topic:", err)
 		return
 	}
@@ -67,6 +85,15 @@ func main() {
 			if err != nil {
 				log.Fatalf("can not receive %v", err)
 			}
+
+			if resp.Command != nil {
+				err = scooterClient.Acknowledge(scooterClient.HandleCommand(resp.Command))
+				if err != nil {
+					fmt.Println(err)
+				}
+				continue
+			}
+
 			currentStationID = uint64(resp.StationID)
 			destination.Latitude = resp.DestLatitude
 			destination.Longitude = resp.DestLongitude
@@ -100,20 +127,23 @@ func main() {
 					fmt.Println(err)
 				}
 
-				msg, err := json.Marshal(currentStatus)
-				if err != nil {fmt.Println(err)}
-				err = transport.SendMessage(producer, TopicName, string(msg))
-				if err != nil {fmt.Println(err)}
+				envelope, err := events.New(events.TypeScooterStatusReported, ClientID,
+					&proto.ScooterStatusReported{ScooterID: currentStatus.ScooterID,
+						StationID: currentStatus.StationID, Latitude: currentStatus.Latitude,
+						Longitude: currentStatus.Longitude, BatteryRemain: currentStatus.BatteryRemain})
+				if err == nil {
+					err = transport.SendEvent(producer, TopicName,
+						strconv.FormatUint(currentStatus.ScooterID, 10), envelope)
+				}
+				if err != nil {
+					fmt.Println(err)
+				}
 			}
 			// a mock message for keeping the stream.
-			msg := &proto.ClientMessage{
-				Id:        scooterClient.ID,
-				Latitude:  scooterClient.Latitude,
-				Longitude: scooterClient.Longitude,
-			}
+			msg := scooterClient.Message()
 
 			fmt.Printf("Sent to server this message: %v\n", msg)
-			err := scooterClient.Stream.Send(msg)
+			err := scooterClient.Send(msg)
 			if err != nil {
 				fmt.Println(err)
 			}
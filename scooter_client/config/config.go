@@ -1,13 +1,21 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 )
 
 var GRPC_PORT = getStringParameter("GRPC_PORT", "9000")
 var ORDER_GRPC_PORT = getStringParameter("ORDER_GRPC_PORT", "9999")
 var KAFKA_BROKER = getStringParameter("KAFKA_BROKER", "localhost:9093")
+var KAFKA_PARTITIONS = getUintParameter("KAFKA_PARTITIONS", 3)
 var SERVER_CONN_GRPC_ADDRESS = getStringParameter("SERVER_CONN_GRPC_ADDRESS", ":9000")
+var DEVICE_TOKEN = getStringParameter("DEVICE_TOKEN", "")
+var TLS_CERT_FILE = getStringParameter("TLS_CERT_FILE", "")
+var TLS_KEY_FILE = getStringParameter("TLS_KEY_FILE", "")
+var TLS_CA_FILE = getStringParameter("TLS_CA_FILE", "")
+var SERVER_TLS_SERVER_NAME = getStringParameter("SERVER_TLS_SERVER_NAME", "scooter_server")
 
 func getStringParameter(paramName, defaultValue string) string {
 	result, ok := os.LookupEnv(paramName)
@@ -16,3 +24,16 @@ func getStringParameter(paramName, defaultValue string) string {
 	}
 	return result
 }
+
+func getUintParameter(paramName string, defaultValue uint64) uint64 {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
@@ -0,0 +1,185 @@
+//Package events defines the envelopes of the Kafka events and their schema versions.
+//The package is copied into the scooter_server, scooter_client and order_service modules, which don't share
+//a module yet, so the copies differ only in the import of the proto package and must be changed together.
+//Moving it into a shared module is a follow-up.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Shopify/sarama"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"scooter_client/proto"
+	"strconv"
+	"time"
+)
+
+//Kafka headers of the events.
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+)
+
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+//Event types.
+const (
+	TypeScooterStatusReported = "scooter.status_reported"
+	TypeScooterRentable       = "scooter.rentable"
+	TypeScooterUnrentable     = "scooter.unrentable"
+	TypeScooterOnline         = "scooter.online"
+	TypeScooterOffline        = "scooter.offline"
+	TypeScooterBatteryLow     = "scooter.battery_low"
+	TypeScooterStationChanged = "scooter.station_changed"
+	TypeTripStarted           = "trip.started"
+	TypeTripEnded             = "trip.ended"
+	TypeOrderCreated          = "order.created"
+	TypeOrderStarted          = "order.started"
+	TypeOrderInProgress       = "order.in_progress"
+	TypeOrderCompleted        = "order.completed"
+	TypeOrderCancelled        = "order.cancelled"
+	TypeOrderRefunded         = "order.refunded"
+	TypeOrderDisputed         = "order.disputed"
+)
+
+//OrderSchemaVersion is the schema version of the order events published from the outbox of the order service.
+const OrderSchemaVersion = 1
+
+//LegacyVersion is the schema version of the plain JSON messages published before the envelopes.
+const LegacyVersion = 0
+
+var (
+	ErrUnknownEvent       = errors.New("unknown event type")
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+)
+
+//SchemaVersions holds the latest schema version of every event type known to this service.
+var SchemaVersions = map[string]uint32{
+	TypeScooterStatusReported: 1,
+	TypeScooterRentable:       1,
+	TypeScooterUnrentable:     1,
+	TypeScooterOnline:         1,
+	TypeScooterOffline:        1,
+	TypeScooterBatteryLow:     1,
+	TypeScooterStationChanged: 1,
+	TypeTripStarted:           1,
+	TypeTripEnded:             1,
+	TypeOrderCreated:          OrderSchemaVersion,
+	TypeOrderStarted:          OrderSchemaVersion,
+	TypeOrderInProgress:       OrderSchemaVersion,
+	TypeOrderCompleted:        OrderSchemaVersion,
+	TypeOrderCancelled:        OrderSchemaVersion,
+	TypeOrderRefunded:         OrderSchemaVersion,
+	TypeOrderDisputed:         OrderSchemaVersion,
+}
+
+//New wraps the payload into the envelope of the latest schema version of the event type.
+func New(eventType, source string, payload protobuf.Message) (*proto.EventEnvelope, error) {
+	version, ok := SchemaVersions[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEvent, eventType)
+	}
+
+	data, err := protobuf.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.EventEnvelope{EventID: NewID(), EventType: eventType, SchemaVersion: version,
+		OccurredAt: timestamppb.Now(), Source: source, Payload: data}, nil
+}
+
+//NewID returns a random event ID.
+func NewID() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+//Message creates the Kafka message of the envelope. The headers let the consumers check the event type
+//and the version without decoding the envelope.
+func Message(topic, key string, envelope *proto.EventEnvelope) (*sarama.ProducerMessage, error) {
+	data, err := protobuf.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	message := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(data),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(HeaderContentType), Value: []byte(ContentTypeProtobuf)},
+			{Key: []byte(HeaderEventType), Value: []byte(envelope.EventType)},
+			{Key: []byte(HeaderSchemaVersion), Value: []byte(strconv.FormatUint(uint64(envelope.SchemaVersion), 10))},
+		},
+		Timestamp: envelope.OccurredAt.AsTime(),
+	}
+	if key != "" {
+		message.Key = sarama.StringEncoder(key)
+	}
+	return message, nil
+}
+
+//Decode returns the envelope of the consumed message. Messages without the protobuf content type are
+//the legacy JSON scooter statuses, they are wrapped into the envelope of the legacy version.
+func Decode(message *sarama.ConsumerMessage) (*proto.EventEnvelope, error) {
+	if header(message, HeaderContentType) != ContentTypeProtobuf {
+		return decodeLegacy(message)
+	}
+
+	envelope := &proto.EventEnvelope{}
+	err := protobuf.Unmarshal(message.Value, envelope)
+	if err != nil {
+		return nil, err
+	}
+	return envelope, Check(envelope)
+}
+
+//Check reports whether this service can read the payload of the envelope. The versions up to the latest
+//known one are compatible.
+func Check(envelope *proto.EventEnvelope) error {
+	latest, ok := SchemaVersions[envelope.EventType]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEvent, envelope.EventType)
+	}
+	if envelope.SchemaVersion > latest {
+		return fmt.Errorf("%w: %v version %d, latest known is %d", ErrUnsupportedVersion, envelope.EventType,
+			envelope.SchemaVersion, latest)
+	}
+	return nil
+}
+
+func decodeLegacy(message *sarama.ConsumerMessage) (*proto.EventEnvelope, error) {
+	status := &proto.ScooterStatusReported{}
+	err := protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(message.Value, status)
+	if err != nil {
+		return nil, fmt.Errorf("decode legacy message: %w", err)
+	}
+
+	data, err := protobuf.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.EventEnvelope{EventID: fmt.Sprintf("%v-%d-%d", message.Topic, message.Partition, message.Offset),
+		EventType: TypeScooterStatusReported, SchemaVersion: LegacyVersion,
+		OccurredAt: timestamppb.New(message.Timestamp), Payload: data}, nil
+}
+
+func header(message *sarama.ConsumerMessage, key string) string {
+	for _, h := range message.Headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"scooter_client/proto"
+	"time"
+)
+
+//HandleCommand applies the remote command to the scooter and returns the acknowledgement for the server.
+func (s *ScooterClient) HandleCommand(command *proto.Command) *proto.CommandAck {
+	ack := &proto.CommandAck{CommandID: command.Id, Ok: true}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch c := command.Type.(type) {
+	case *proto.Command_Lock:
+		s.locked = true
+	case *proto.Command_Unlock:
+		s.locked = false
+	case *proto.Command_Beep:
+		fmt.Printf("Scooter %v beeps for %v seconds\n", s.ID, c.Beep.Seconds)
+	case *proto.Command_EndTrip:
+		if s.running {
+			s.ended = true
+		}
+		s.locked = false
+	case *proto.Command_SetSpeedLimit:
+		if c.SetSpeedLimit.Speed < 0 {
+			ack.Ok = false
+			ack.Error = fmt.Sprintf("speed limit can't be negative: %v", c.SetSpeedLimit.Speed)
+			break
+		}
+		s.speedLimit = c.SetSpeedLimit.Speed
+	default:
+		ack.Ok = false
+		ack.Error = fmt.Sprintf("unknown command %T", command.Type)
+	}
+
+	fmt.Printf("Command %v handled: %v\n", command, ack)
+	return ack
+}
+
+//startTrip marks the trip running, the "end trip" command received before it is ignored.
+func (s *ScooterClient) startTrip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running, s.ended = true, false
+}
+
+//finishTrip marks the trip finished, so the next trip isn't ended by the command of this one.
+func (s *ScooterClient) finishTrip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running, s.ended = false, false
+}
+
+//isLocked reports whether the scooter is locked by the remote command.
+func (s *ScooterClient) isLocked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+//canMove reports whether the scooter can make the next step of the trip.
+func (s *ScooterClient) canMove() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.BatteryRemain > 0 && !s.ended
+}
+
+//waitWhileLocked holds the scooter on its place while it is locked.
+//The scooter keeps sending its position, so the server can deliver the unlock command.
+func (s *ScooterClient) waitWhileLocked() {
+	for s.isLocked() {
+		err := s.Send(s.Message())
+		if err != nil {
+			fmt.Println(err)
+		}
+		time.Sleep(time.Duration(interval) * time.Millisecond)
+	}
+}
+
+//stepInterval returns the pause after the step made with the given speed. The pause is extended when
+//the speed is over the limit, so the scooter slows down to the limit.
+func (s *ScooterClient) stepInterval(speed float64) time.Duration {
+	intPol := time.Duration(interval) * time.Millisecond
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.speedLimit > 0 && speed > s.speedLimit {
+		intPol = time.Duration(float64(intPol) * speed / s.speedLimit)
+	}
+	return intPol
+}
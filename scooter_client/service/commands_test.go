@@ -0,0 +1,40 @@
+package service
+
+import (
+	"scooter_client/proto"
+	"testing"
+)
+
+func endTrip() *proto.Command {
+	return &proto.Command{Id: 1, Type: &proto.Command_EndTrip{EndTrip: &proto.EndTrip{}}}
+}
+
+func TestEndTripWhileIdleIsIgnored(t *testing.T) {
+	s := NewScooterClient(1, 0, 0, 50, nil)
+
+	ack := s.HandleCommand(endTrip())
+	if !ack.Ok {
+		t.Fatalf("end_trip ack = %v, want ok", ack)
+	}
+
+	s.startTrip()
+	if !s.canMove() {
+		t.Error("end_trip received while idle stopped the next trip")
+	}
+}
+
+func TestEndTripStopsRunningTrip(t *testing.T) {
+	s := NewScooterClient(1, 0, 0, 50, nil)
+	s.startTrip()
+
+	s.HandleCommand(endTrip())
+	if s.canMove() {
+		t.Error("running trip isn't stopped by end_trip")
+	}
+
+	s.finishTrip()
+	s.startTrip()
+	if !s.canMove() {
+		t.Error("end_trip of the finished trip stopped the next one")
+	}
+}
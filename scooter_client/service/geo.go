@@ -0,0 +1,29 @@
+package service
+
+import (
+	"math"
+)
+
+const earthRadius = 6371000.0
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+//distance returns the great-circle distance between two points in meters.
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
+
+//bearing returns the direction from the first point to the second one in degrees clockwise from the north.
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	dLon := toRadians(lon2 - lon1)
+	y := math.Sin(dLon) * math.Cos(toRadians(lat2))
+	x := math.Cos(toRadians(lat1))*math.Sin(toRadians(lat2)) -
+		math.Sin(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"scooter_client/model"
 	"scooter_client/proto"
+	"sync"
 	"time"
 )
 
@@ -20,9 +22,18 @@ type ScooterClient struct {
 	Longitude     float64
 	BatteryRemain float64
 	Stream        proto.ScooterService_RegisterClient
+	sequence      uint64
+	last          *proto.ClientMessage
+	sendMu        sync.Mutex
+	mu            sync.Mutex
+	locked        bool
+	running       bool
+	ended         bool
+	speedLimit    float64
 }
 
 //NewScooterClient creates a new GrpcScooterClient with given parameters.
+//The sequence numbers start from the current time, so they keep increasing after the client restarts.
 func NewScooterClient(id uint64, latitude, longitude, battery float64,
 	stream proto.ScooterService_RegisterClient) *ScooterClient {
 	return &ScooterClient{
@@ -31,36 +42,78 @@ func NewScooterClient(id uint64, latitude, longitude, battery float64,
 		Longitude:     longitude,
 		BatteryRemain: battery,
 		Stream:        stream,
+		sequence:      uint64(time.Now().UnixNano()),
 	}
 }
 
+//Message creates the next stream message with the current scooter position, numbered by the sequence.
+//Speed and heading are calculated from the previous message.
+func (s *ScooterClient) Message() *proto.ClientMessage {
+	now := time.Now()
+	s.sequence++
+	msg := &proto.ClientMessage{
+		Id:            s.ID,
+		Latitude:      s.Latitude,
+		Longitude:     s.Longitude,
+		DeviceTime:    timestamppb.New(now),
+		Sequence:      s.sequence,
+		BatteryRemain: s.BatteryRemain,
+	}
+
+	if s.last != nil && s.last.Id == s.ID {
+		meters := distance(s.last.Latitude, s.last.Longitude, s.Latitude, s.Longitude)
+		elapsed := now.Sub(s.last.DeviceTime.AsTime()).Hours()
+		if elapsed > 0 {
+			msg.Speed = meters / 1000 / elapsed
+		}
+		msg.Heading = s.last.Heading
+		if meters > 0 {
+			msg.Heading = bearing(s.last.Latitude, s.last.Longitude, s.Latitude, s.Longitude)
+		}
+	}
+	s.last = msg
+	return msg
+}
+
+//Send sends the message by the gRPC stream. It is safe to call it from several goroutines.
+func (s *ScooterClient) Send(msg *proto.ClientMessage) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.Stream.Send(msg)
+}
+
+//Acknowledge sends the acknowledgement of the remote command to the server.
+func (s *ScooterClient) Acknowledge(ack *proto.CommandAck) error {
+	return s.Send(&proto.ClientMessage{Id: s.ID, Ack: ack})
+}
+
 //GrpcScooterMessage sends the message be gRPC stream in a format which defined in the *proto file.
+//The locked scooter waits until it is unlocked before it moves.
 func (s *ScooterClient) GrpcScooterMessage() {
-	intPol := time.Duration(interval) * time.Millisecond
+	s.waitWhileLocked()
 
 	fmt.Println("executing run in client")
-	msg := proto.ClientMessage{
-		Id:        s.ID,
-		Latitude:  s.Latitude,
-		Longitude: s.Longitude,
-	}
+	msg := s.Message()
 
-	fmt.Printf("Send to server this message: %v\n", &msg)
-	err := s.Stream.Send(&msg)
+	fmt.Printf("Send to server this message: %v\n", msg)
+	err := s.Send(msg)
 	if err != nil {
 		fmt.Println(err)
 	}
-	time.Sleep(intPol)
+	time.Sleep(s.stepInterval(msg.Speed))
 }
 
 //Run is responsible for scooter's movements from his current position to the destination point.
 //Run also is responsible for scooter's discharge. Every step battery charge decrease by the constant discharge value.
+//The trip is stopped earlier by the "end trip" remote command.
 func (s *ScooterClient) Run(station model.Location) (*proto.SendStatus, error) {
+	s.startTrip()
+	defer s.finishTrip()
 
 	switch {
 	case s.Latitude <= station.Latitude && s.Longitude <= station.Longitude:
 		for ; s.Latitude <= station.Latitude && s.Longitude <= station.Longitude && s.
-			BatteryRemain > 0; s.
+			canMove(); s.
 			Latitude,
 			s.Longitude, s.BatteryRemain = s.Latitude+step, s.Longitude+step,
 			s.BatteryRemain-dischargeStep {
@@ -69,7 +122,7 @@ func (s *ScooterClient) Run(station model.Location) (*proto.SendStatus, error) {
 		fallthrough
 	case s.Latitude >= station.Latitude && s.Longitude <= station.Longitude:
 		for ; s.Latitude >= station.Latitude && s.Longitude <= station.Longitude && s.
-			BatteryRemain > 0; s.Latitude,
+			canMove(); s.Latitude,
 			s.Longitude, s.BatteryRemain = s.Latitude-step, s.Longitude+step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
@@ -77,7 +130,7 @@ func (s *ScooterClient) Run(station model.Location) (*proto.SendStatus, error) {
 		fallthrough
 	case s.Latitude >= station.Latitude && s.Longitude >= station.Longitude:
 		for ; s.Latitude >= station.Latitude && s.Longitude >= station.Longitude && s.
-			BatteryRemain > 0; s.Latitude,
+			canMove(); s.Latitude,
 			s.Longitude, s.BatteryRemain = s.Latitude-step, s.Longitude-step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
@@ -85,7 +138,7 @@ func (s *ScooterClient) Run(station model.Location) (*proto.SendStatus, error) {
 		fallthrough
 	case s.Latitude <= station.Latitude && s.Longitude >= station.Longitude:
 		for ; s.Latitude <= station.Latitude && s.Longitude >= station.Longitude && s.
-			BatteryRemain > 0; s.Latitude,
+			canMove(); s.Latitude,
 			s.Longitude, s.BatteryRemain = s.Latitude+step, s.Longitude-step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
@@ -93,28 +146,28 @@ func (s *ScooterClient) Run(station model.Location) (*proto.SendStatus, error) {
 		fallthrough
 	case s.Latitude <= station.Latitude:
 		for ; s.Latitude <= station.Latitude && s.
-			BatteryRemain > 0; s.Latitude, s.BatteryRemain = s.Latitude+step,
+			canMove(); s.Latitude, s.BatteryRemain = s.Latitude+step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
 		}
 		fallthrough
 	case s.Latitude >= station.Latitude:
 		for ; s.Latitude >= station.Latitude && s.
-			BatteryRemain > 0; s.Latitude, s.BatteryRemain = s.Latitude-step,
+			canMove(); s.Latitude, s.BatteryRemain = s.Latitude-step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
 		}
 		fallthrough
 	case s.Longitude >= station.Longitude:
 		for ; s.Longitude >= station.Longitude && s.
-			BatteryRemain > 0; s.Longitude, s.BatteryRemain = s.Longitude-step,
+			canMove(); s.Longitude, s.BatteryRemain = s.Longitude-step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
 		}
 		fallthrough
 	case s.Longitude <= station.Longitude:
 		for ; s.Longitude <= station.Longitude && s.
-			BatteryRemain > 0; s.Longitude, s.BatteryRemain = s.Longitude+step,
+			canMove(); s.Longitude, s.BatteryRemain = s.Longitude+step,
 			s.BatteryRemain-dischargeStep {
 			s.GrpcScooterMessage()
 		}
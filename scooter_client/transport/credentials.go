@@ -0,0 +1,19 @@
+package transport
+
+import (
+	"context"
+)
+
+//TokenCredentials adds the device token to every gRPC call of the scooter.
+type TokenCredentials struct {
+	Token    string
+	Insecure bool
+}
+
+func (tc TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + tc.Token}, nil
+}
+
+func (tc TokenCredentials) RequireTransportSecurity() bool {
+	return !tc.Insecure
+}
@@ -1,17 +1,25 @@
 package transport
 
 import (
+	"errors"
 	"github.com/Shopify/sarama"
 	"log"
+	"scooter_client/events"
+	"scooter_client/proto"
 )
 
 var kafkaVersion = sarama.V3_0_0_0
 
+//CreateProducer creates the producer which keeps the order of the messages with the same key.
+//The messages are partitioned by the hash of the key and only one request is in flight while retrying.
 func CreateProducer(brokerList []string, clientID string) sarama.SyncProducer {
 	config := sarama.NewConfig()
+	config.Version = kafkaVersion
 	config.Producer.RequiredAcks = sarama.WaitForAll
 	config.Producer.Retry.Max = 10
 	config.Producer.Return.Successes = true
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	config.Net.MaxOpenRequests = 1
 	config.ClientID = clientID
 
 	producer, err := sarama.NewSyncProducer(brokerList, config)
@@ -22,15 +30,31 @@ func CreateProducer(brokerList []string, clientID string) sarama.SyncProducer {
 	return producer
 }
 
-func SendMessage(producer sarama.SyncProducer, topic, message string) error {
+//SendMessage sends the message keyed by the key, the messages with the same key get into the same partition.
+func SendMessage(producer sarama.SyncProducer, topic, key, message string) error {
 	_, _, err := producer.SendMessage(&sarama.ProducerMessage{
 		Topic: topic,
+		Key:   sarama.StringEncoder(key),
 		Value: sarama.StringEncoder(message),
 	})
 
 	return err
 }
 
+//SendEvent sends the protobuf encoded envelope with the headers of its content type, event type and schema version.
+func SendEvent(producer sarama.SyncProducer, topic, key string, envelope *proto.EventEnvelope) error {
+	message, err := events.Message(topic, key, envelope)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = producer.SendMessage(message)
+	return err
+}
+
+//CreateTopic creates the topic with nPartitions partitions. The existing topic which has fewer partitions
+//is extended, so the number of partitions can be raised by the configuration. Messages already written
+//keep their partitions, only the new messages of a key may be moved to another partition.
 func CreateTopic(brokerList []string, topicName string, nPartitions int32, replicas int16) error {
 	config := sarama.NewConfig()
 	config.Version = kafkaVersion
@@ -45,6 +69,17 @@ func CreateTopic(brokerList []string, topicName string, nPartitions int32, repli
 		NumPartitions:     nPartitions,
 		ReplicationFactor: replicas,
 	}, false)
+	var topicErr *sarama.TopicError
+	if !errors.As(err, &topicErr) || topicErr.Err != sarama.ErrTopicAlreadyExists {
+		return err
+	}
 
-	return err
+	topics, err := admin.DescribeTopics([]string{topicName})
+	if err != nil {
+		return err
+	}
+	if len(topics) == 0 || len(topics[0].Partitions) >= int(nPartitions) {
+		return nil
+	}
+	return admin.CreatePartitions(topicName, nPartitions, nil, false)
 }
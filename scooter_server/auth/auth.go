@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tokenSize = 32
+
+type contextKey struct{}
+
+//User is the authenticated caller of the API.
+type User struct {
+	ID    uint64 `json:"id"`
+	Email string `json:"email"`
+	Role  Role   `json:"role"`
+	//ScooterID is set for the devices identified by the certificate of the scooter.
+	ScooterID uint64 `json:"scooterId,omitempty"`
+}
+
+//WithUser returns a copy of the context which carries the authenticated user.
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+//UserFromContext returns the authenticated user of the request, false for anonymous requests.
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*User)
+	return user, ok && user != nil
+}
+
+//HashPassword hashes the password with bcrypt.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+//CheckPassword reports whether the password matches the bcrypt hash.
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+//NewToken generates a random session token. Only the hash of the token is stored,
+//so the leaked sessions table can't be used to log in.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, tokenSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+//HashToken returns the hex-encoded SHA-256 hash of the session token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"strconv"
+	"strings"
+)
+
+const (
+	authorizationHeader = "authorization"
+	//DeviceCommonName is the prefix of the common names of the scooter certificates, the scooter ID follows it
+	//after the dash: "scooter-5".
+	DeviceCommonName = "scooter"
+)
+
+//UnaryInterceptor authenticates the unary calls by the scooter certificate or by the bearer token from the metadata
+//and checks that the caller's role is allowed to call the method.
+func UnaryInterceptor(authenticator Authenticator, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authorize(ctx, authenticator, policy, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+//StreamInterceptor authenticates the streams the same way as UnaryInterceptor does for the unary calls.
+func StreamInterceptor(authenticator Authenticator, policy Policy) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, err := authorize(stream.Context(), authenticator, policy, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, authenticator Authenticator, policy Policy, method string) (context.Context,
+	error) {
+	user := certificateIdentity(ctx)
+	if token := bearerToken(ctx); user == nil && token != "" {
+		var err error
+		user, err = authenticator.Authenticate(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	if user != nil {
+		ctx = WithUser(ctx, user)
+	}
+
+	if !policy.Allowed(method, user) {
+		if user == nil {
+			return nil, status.Error(codes.Unauthenticated, ErrUnauthenticated.Error())
+		}
+		return nil, status.Errorf(codes.PermissionDenied, "role %q can't call %v", user.Role, method)
+	}
+	return ctx, nil
+}
+
+//certificateIdentity returns the device identity from the verified client certificate of the scooter,
+//nil when the caller isn't a scooter or doesn't use mutual TLS.
+func certificateIdentity(ctx context.Context) *User {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	scooterID, ok := DeviceScooterID(info.State.VerifiedChains[0][0].Subject.CommonName)
+	if !ok {
+		return nil
+	}
+	return &User{Role: RoleDevice, ScooterID: scooterID}
+}
+
+//DeviceScooterID returns the scooter ID from the common name of the scooter certificate. It reports false
+//for the other names, including the bare DeviceCommonName and the zero ID.
+func DeviceScooterID(commonName string) (uint64, bool) {
+	if !strings.HasPrefix(commonName, DeviceCommonName+"-") {
+		return 0, false
+	}
+	scooterID, err := strconv.ParseUint(strings.TrimPrefix(commonName, DeviceCommonName+"-"), 10, 64)
+	if err != nil || scooterID == 0 {
+		return 0, false
+	}
+	return scooterID, true
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get(authorizationHeader) {
+		if strings.HasPrefix(value, "Bearer ") {
+			return strings.TrimPrefix(value, "Bearer ")
+		}
+	}
+	return ""
+}
+
+//authorizedStream passes the context with the authenticated user to the stream handler.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+//TokenCredentials adds the bearer token to every call of the client.
+type TokenCredentials struct {
+	Token    string
+	Insecure bool
+}
+
+func (tc TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: "Bearer " + tc.Token}, nil
+}
+
+func (tc TokenCredentials) RequireTransportSecurity() bool {
+	return !tc.Insecure
+}
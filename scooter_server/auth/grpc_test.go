@@ -0,0 +1,29 @@
+package auth
+
+import "testing"
+
+func TestDeviceScooterID(t *testing.T) {
+	tests := []struct {
+		commonName string
+		scooterID  uint64
+		ok         bool
+	}{
+		{"scooter-5", 5, true},
+		{"scooter-18446744073709551615", 18446744073709551615, true},
+		{"scooter", 0, false},
+		{"scooter-", 0, false},
+		{"scooter-0", 0, false},
+		{"scooter--5", 0, false},
+		{"scooter-5a", 0, false},
+		{"scooter_server", 0, false},
+		{"order_service", 0, false},
+	}
+
+	for _, test := range tests {
+		scooterID, ok := DeviceScooterID(test.commonName)
+		if scooterID != test.scooterID || ok != test.ok {
+			t.Errorf("DeviceScooterID(%q) = %v, %v, want %v, %v", test.commonName, scooterID, ok,
+				test.scooterID, test.ok)
+		}
+	}
+}
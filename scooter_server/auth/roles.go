@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+)
+
+//Role defines what the user is allowed to do.
+type Role string
+
+const (
+	RoleRider    Role = "rider"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+	RoleDevice   Role = "device"
+	//Anyone allows the anonymous calls, it is used only in the policies.
+	Anyone Role = "anyone"
+)
+
+var ErrUnauthenticated = errors.New("authentication required")
+
+//ValidRole reports whether the role can be given to a user.
+func ValidRole(role Role) bool {
+	switch role {
+	case RoleRider, RoleOperator, RoleAdmin, RoleDevice:
+		return true
+	}
+	return false
+}
+
+//HasRole reports whether the user has one of the roles. Admins have all the roles.
+func (u *User) HasRole(roles ...Role) bool {
+	if u.Role == RoleAdmin {
+		return true
+	}
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+//Policy maps the routes or the gRPC methods to the roles allowed to call them.
+//Calls which aren't listed in the policy are allowed to the admins only.
+type Policy map[string][]Role
+
+//Allowed reports whether the user, nil for the anonymous call, can call the route or the method.
+func (p Policy) Allowed(key string, user *User) bool {
+	roles := p[key]
+	for _, role := range roles {
+		if role == Anyone {
+			return true
+		}
+	}
+	if user == nil {
+		return false
+	}
+	return user.HasRole(roles...)
+}
+
+//Authenticator returns the user of the token.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*User, error)
+}
+
+//DeviceAuthenticator authenticates the scooters by the shared device token
+//and passes the other tokens to the users authenticator. The token is the fallback for the scooters without
+//their certificates, it is taken from the environment and the empty token authenticates nobody.
+type DeviceAuthenticator struct {
+	DeviceToken string
+	Users       Authenticator
+}
+
+//Authenticate returns the device identity for the device token or the user of the session token.
+func (da *DeviceAuthenticator) Authenticate(ctx context.Context, token string) (*User, error) {
+	if da.DeviceToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(da.DeviceToken)) == 1 {
+		return &User{Role: RoleDevice}, nil
+	}
+	return da.Users.Authenticate(ctx, token)
+}
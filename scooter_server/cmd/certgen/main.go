@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"scooter_micro/auth"
+	"scooter_micro/tlsconfig"
+	"strings"
+)
+
+//certgen creates a local CA and the certificates for the development environment:
+//	go run ./cmd/certgen -dir ../certs -names scooter_server,order_service,scooter-1
+//The scooter certificates must name the scooter: the server doesn't identify the bare "scooter".
+func main() {
+	dir := flag.String("dir", "certs", "directory for the generated files")
+	names := flag.String("names", "scooter_server,order_service,scooter-1",
+		"comma-separated common names of the certificates")
+	flag.Parse()
+
+	commonNames := strings.Split(*names, ",")
+	for _, name := range commonNames {
+		_, ok := auth.DeviceScooterID(name)
+		if !ok && (name == auth.DeviceCommonName || strings.HasPrefix(name, auth.DeviceCommonName+"-")) {
+			log.Fatalf("certificate %q doesn't name the scooter, use %v-<id>", name, auth.DeviceCommonName)
+		}
+	}
+
+	err := tlsconfig.GenerateDevCerts(*dir, commonNames...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("certificates were written to %v\n", *dir)
+}
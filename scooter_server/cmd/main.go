@@ -4,21 +4,31 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/Shopify/sarama"
 	_ "github.com/lib/pq"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 	"log"
 	"net"
 	"net/http"
+	"scooter_micro/auth"
 	"scooter_micro/config"
+	"scooter_micro/fleet"
+	"scooter_micro/gateway"
+	"scooter_micro/maintenance"
 	"scooter_micro/proto"
 	"scooter_micro/repository"
 	"scooter_micro/routing"
 	"scooter_micro/routing/grpcserver"
 	"scooter_micro/routing/httpserver"
 	"scooter_micro/service"
+	"scooter_micro/telemetry"
+	"scooter_micro/tlsconfig"
+	"scooter_micro/transport"
 )
 
+const ClientID = "scooter_server"
+
 var scooterIdMap = make(map[uint64]proto.ScooterService_RegisterServer)
 var StructCh = make(chan *proto.ScooterClient)
 
@@ -37,9 +47,28 @@ func main() {
 	}
 	defer db.Close()
 
+	tlsConfig := tlsconfig.Config{CertFile: config.TLS_CERT_FILE, KeyFile: config.TLS_KEY_FILE,
+		CAFile: config.TLS_CA_FILE}
+	orderTLSConfig := tlsConfig
+	orderTLSConfig.ServerName = config.ORDER_TLS_SERVER_NAME
+	orderCredentials, err := tlsconfig.DialCredentials(orderTLSConfig)
+	if err != nil {
+		log.Panicf("%s: failed to load TLS credentials - %v", "scooter_micro", err)
+	}
+	serverCredentials, err := tlsconfig.ServerCredentials(tlsConfig)
+	if err != nil {
+		log.Panicf("%s: failed to load TLS credentials - %v", "scooter_micro", err)
+	}
+
 	scooterRepo := repository.NewScooterRepo(db)
+	//The order service accepts the scooter_server certificate or the service token from the environment.
+	orderOptions := []grpc.DialOption{grpc.WithTransportCredentials(orderCredentials)}
+	if config.SERVICE_TOKEN != "" {
+		orderOptions = append(orderOptions, grpc.WithPerRPCCredentials(auth.TokenCredentials{
+			Token: config.SERVICE_TOKEN, Insecure: !orderTLSConfig.Enabled() && orderTLSConfig.CAFile == ""}))
+	}
 	conn, err := grpc.DialContext(context.Background(), net.JoinHostPort("", config.ORDER_GRPC_PORT),
-		grpc.WithInsecure())
+		orderOptions...)
 	if err != nil {
 		log.Printf("gRPC connection to %v port failed. With: %v\n", config.ORDER_GRPC_PORT, err)
 	}
@@ -47,19 +76,85 @@ func main() {
 	log.Printf("gRPC connected port: %v.", config.ORDER_GRPC_PORT)
 
 	orderClient := proto.NewOrderServiceClient(conn)
-	scooterService := service.NewScooterService(scooterRepo, orderClient)
+	fleetEvents := fleet.NewPublisher(config.FLEET_EVENTS_TOPIC, config.LOW_BATTERY_THRESHOLD)
+	go fleetEvents.Connect(context.Background(), config.KAFKA_RETRY_INTERVAL, func() (sarama.AsyncProducer, error) {
+		err := transport.CreateTopic([]string{config.KAFKA_BROKER}, config.FLEET_EVENTS_TOPIC,
+			int32(config.KAFKA_PARTITIONS), 1)
+		if err != nil {
+			return nil, err
+		}
+		return transport.CreateProducer([]string{config.KAFKA_BROKER}, ClientID)
+	})
+
+	thresholds, err := maintenance.ParseThresholds(config.CHARGED_BATTERY, config.CHARGING_THRESHOLDS)
+	if err != nil {
+		log.Panicf("%s: failed to parse charging thresholds - %v", "scooter_micro", err)
+	}
+	taskService := service.NewTaskService(repository.NewTaskRepo(db), thresholds)
+
+	scooterService := service.NewScooterService(scooterRepo, orderClient, fleetEvents, taskService)
 	scooterList, err := scooterService.GetAllScooters(context.Background(), &proto.Request{})
 	if err != nil {
 		fmt.Println(err)
 	}
 
 	handler := routing.NewRouter(scooterService, StructCh)
+	rebalanceService := service.NewRebalanceService(repository.NewRebalanceRepo(db), scooterService, nil,
+		config.REBALANCE_MOVE_TIMEOUT)
+	analyticsService := service.NewAnalyticsService(repository.NewAnalyticsRepo(db), config.FORECAST_HISTORY,
+		int(config.FORECAST_WINDOW))
 
-	httpServer := httpserver.New(handler, StructCh, scooterService, httpserver.Port(config.HTTP_PORT))
+	validator := telemetry.NewValidator(telemetry.Limits{MaxSpeed: config.TELEMETRY_MAX_SPEED,
+		Tolerance: config.TELEMETRY_TOLERANCE})
+	httpServer := httpserver.New(handler, StructCh, scooterService, httpserver.Port(config.HTTP_PORT),
+		httpserver.Validator(validator), httpserver.Tasks(taskService), httpserver.Rebalancer(rebalanceService),
+		httpserver.Analytics(analyticsService))
+	rebalanceService.Presence = httpServer
+	rebalanceService.Streams = httpServer
+	go rebalanceService.RunExpiry(context.Background(), config.REBALANCE_EXPIRY_INTERVAL)
 	handler.HandleFunc("/scooter", httpServer.ScooterHandler)
+	handler.HandleFunc("/telemetry/rejections", httpServer.TelemetryRejectionsHandler).Methods("GET")
+	handler.HandleFunc("/admin/scooters/{scooterId}/commands", httpServer.CommandHandler).Methods("POST")
+
+	tripService := service.NewTripService(repository.NewTripRepo(db), scooterService, httpServer, validator,
+		config.PARKING_RADIUS)
+	go tripService.RunSettlement(context.Background(), config.TRIP_SETTLE_INTERVAL, config.TRIP_SETTLE_BATCH_SIZE)
+	routing.RegisterTripRoutes(handler, tripService)
+	routing.RegisterWalletRoutes(handler, orderClient)
+	routing.RegisterPromoRoutes(handler, orderClient)
+	routing.RegisterOrderRoutes(handler, orderClient)
+	routing.RegisterTaskRoutes(handler, taskService)
+	routing.RegisterMaintenanceRoutes(handler, service.NewMaintenanceService(repository.NewMaintenanceRepo(db),
+		int(config.GROUNDING_REPORTS)))
+	routing.RegisterRebalanceRoutes(handler, rebalanceService)
+	routing.RegisterAnalyticsRoutes(handler, analyticsService)
+	routing.RegisterReportRoutes(handler, service.NewReportService(repository.NewReportRepo(db),
+		config.HEATMAP_CELL_SIZE))
+	exportService := service.NewExportService(repository.NewExportRepo(db))
+	routing.RegisterExportRoutes(handler, exportService)
+	routing.RegisterAPIRoutes(handler, exportService, tripService, orderClient)
+
+	err = routing.RegisterGatewayRoutes(handler,
+		gateway.Service{Descriptor: proto.File_scooter_micro_proto.Services().ByName("ScooterService"),
+			Invoke: gateway.Local(&proto.ScooterService_ServiceDesc, httpServer)},
+		gateway.Service{Descriptor: proto.File_proto_order_micro_proto.Services().ByName("OrderService"),
+			Invoke: gateway.Remote(conn)})
+	if err != nil {
+		log.Panicf("%s: failed to register gateway routes - %v", "scooter_micro", err)
+	}
+
+	userService := service.NewUserService(repository.NewUserRepo(db), config.SESSION_TTL)
+	handler.Use(routing.AuthMiddleware(userService), routing.AuthorizeMiddleware(routing.HTTPPolicy))
+	routing.RegisterUserRoutes(handler, userService)
 
 	getIdFromStructInArray(scooterList, httpServer.ScooterIdMap)
-	grpcServer := grpcserver.NewGrpcServer()
+	if config.DEVICE_TOKEN == "" {
+		log.Println("DEVICE_TOKEN isn't set, the scooters are authenticated only by their certificates")
+	}
+	authenticator := &auth.DeviceAuthenticator{DeviceToken: config.DEVICE_TOKEN, Users: userService}
+	grpcServer := grpcserver.NewGrpcServer(grpc.Creds(serverCredentials),
+		grpc.UnaryInterceptor(auth.UnaryInterceptor(authenticator, grpcserver.Policy)),
+		grpc.StreamInterceptor(auth.StreamInterceptor(authenticator, grpcserver.Policy)))
 	proto.RegisterScooterServiceServer(grpcServer, httpServer)
 	reflection.Register(grpcServer)
 
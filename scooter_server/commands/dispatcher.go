@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scooter_micro/proto"
+	"sync"
+	"time"
+)
+
+const (
+	outboxSize     = 8
+	DefaultTimeout = 10 * time.Second
+	MaxTimeout     = time.Minute
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrBusy           = errors.New("scooter has too many pending commands")
+	ErrTimeout        = errors.New("scooter didn't acknowledge the command in time")
+)
+
+//Dispatcher queues the commands for the scooters and waits for their acknowledgements.
+type Dispatcher struct {
+	mu      sync.Mutex
+	nextID  uint64
+	outbox  map[uint64]chan *proto.ScooterClient
+	pending map[uint64]pendingCommand
+}
+
+//pendingCommand is the command which waits for the acknowledgement of its scooter.
+type pendingCommand struct {
+	scooterID uint64
+	ack       chan *proto.CommandAck
+}
+
+//NewDispatcher creates a new Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		outbox:  make(map[uint64]chan *proto.ScooterClient),
+		pending: make(map[uint64]pendingCommand),
+	}
+}
+
+//Outbox returns the channel with the messages which have to be sent to the scooter by its stream.
+func (d *Dispatcher) Outbox(scooterID uint64) chan *proto.ScooterClient {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch, ok := d.outbox[scooterID]
+	if !ok {
+		ch = make(chan *proto.ScooterClient, outboxSize)
+		d.outbox[scooterID] = ch
+	}
+	return ch
+}
+
+//Send queues the command for the scooter and waits until the scooter acknowledges it or the timeout expires.
+//The command which timed out stops being pending, so it is dropped from the queue instead of being delivered late.
+func (d *Dispatcher) Send(ctx context.Context, scooterID uint64, command *proto.Command,
+	timeout time.Duration) (*proto.CommandResult, error) {
+	if command == nil || command.Type == nil {
+		return nil, ErrUnknownCommand
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if timeout > MaxTimeout {
+		timeout = MaxTimeout
+	}
+
+	ack := make(chan *proto.CommandAck, 1)
+	d.mu.Lock()
+	d.nextID++
+	command.Id = d.nextID
+	d.pending[command.Id] = pendingCommand{scooterID: scooterID, ack: ack}
+	d.mu.Unlock()
+
+	defer func() {
+		d.mu.Lock()
+		delete(d.pending, command.Id)
+		d.mu.Unlock()
+	}()
+
+	select {
+	case d.Outbox(scooterID) <- &proto.ScooterClient{Id: scooterID, Command: command}:
+	default:
+		return nil, ErrBusy
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case a := <-ack:
+		return &proto.CommandResult{CommandID: command.Id, ScooterID: scooterID, Ok: a.Ok, Error: a.Error}, nil
+	case <-ctx.Done():
+		return nil, ErrTimeout
+	}
+}
+
+//Pending reports whether the Send call of the command still waits for its acknowledgement. The stream delivers
+//only the pending commands, the ones which timed out or were cancelled are dropped.
+func (d *Dispatcher) Pending(command *proto.Command) bool {
+	if command == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.pending[command.Id]
+	return ok
+}
+
+//Acknowledge passes the acknowledgement received from the stream of the scooter to the Send call which waits
+//for it. Acknowledgements of the commands which have already timed out or were sent to another scooter are ignored.
+func (d *Dispatcher) Acknowledge(scooterID uint64, ack *proto.CommandAck) {
+	d.mu.Lock()
+	pending, ok := d.pending[ack.CommandID]
+	d.mu.Unlock()
+
+	if ok && pending.scooterID == scooterID {
+		select {
+		case pending.ack <- ack:
+		default:
+		}
+	}
+}
+
+//Parse creates the command by its name. The value is the number of seconds for "beep"
+//and the speed in km/h for "set_speed_limit", other commands ignore it.
+func Parse(name string, value float64) (*proto.Command, error) {
+	command := &proto.Command{}
+	switch name {
+	case "lock":
+		command.Type = &proto.Command_Lock{Lock: &proto.Lock{}}
+	case "unlock":
+		command.Type = &proto.Command_Unlock{Unlock: &proto.Unlock{}}
+	case "beep":
+		command.Type = &proto.Command_Beep{Beep: &proto.Beep{Seconds: uint32(value)}}
+	case "end_trip":
+		command.Type = &proto.Command_EndTrip{EndTrip: &proto.EndTrip{}}
+	case "set_speed_limit":
+		if value < 0 {
+			return nil, fmt.Errorf("speed limit can't be negative: %v", value)
+		}
+		command.Type = &proto.Command_SetSpeedLimit{SetSpeedLimit: &proto.SetSpeedLimit{Speed: value}}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCommand, name)
+	}
+	return command, nil
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"scooter_micro/proto"
+	"testing"
+	"time"
+)
+
+func TestSendAcknowledged(t *testing.T) {
+	d := NewDispatcher()
+	command, err := Parse("lock", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		queued := <-d.Outbox(1)
+		if !d.Pending(queued.Command) {
+			t.Error("queued command isn't pending")
+		}
+		d.Acknowledge(1, &proto.CommandAck{CommandID: queued.Command.Id, Ok: true})
+	}()
+
+	result, err := d.Send(context.Background(), 1, command, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Ok || result.CommandID != command.Id {
+		t.Errorf("result = %v, want the acknowledged command %d", result, command.Id)
+	}
+	if d.Pending(command) {
+		t.Error("acknowledged command is still pending")
+	}
+}
+
+func TestAcknowledgeOfAnotherScooterIgnored(t *testing.T) {
+	d := NewDispatcher()
+	command, err := Parse("unlock", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		queued := <-d.Outbox(1)
+		d.Acknowledge(2, &proto.CommandAck{CommandID: queued.Command.Id, Ok: true})
+	}()
+
+	_, err = d.Send(context.Background(), 1, command, 50*time.Millisecond)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Send error = %v, want %v for the command acknowledged by another scooter", err, ErrTimeout)
+	}
+}
+
+func TestSendTimeoutDropsCommand(t *testing.T) {
+	d := NewDispatcher()
+	command, err := Parse("lock", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.Send(context.Background(), 1, command, time.Millisecond)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Send error = %v, want %v", err, ErrTimeout)
+	}
+
+	queued := <-d.Outbox(1)
+	if d.Pending(queued.Command) {
+		t.Error("timed out command is still pending, the stream would deliver it")
+	}
+}
+
+func TestSendBusy(t *testing.T) {
+	d := NewDispatcher()
+	for i := 0; i < outboxSize; i++ {
+		d.Outbox(1) <- &proto.ScooterClient{Id: 1}
+	}
+
+	command, err := Parse("beep", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.Send(context.Background(), 1, command, time.Second)
+	if !errors.Is(err, ErrBusy) {
+		t.Errorf("Send error = %v, want %v", err, ErrBusy)
+	}
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 var HTTP_PORT = getStringParameter("HTTP_PORT", "8085")
@@ -14,6 +17,30 @@ var GRPC_PORT = getStringParameter("GRPC_PORT", "9000")
 var ORDER_GRPC_PORT = getStringParameter("ORDER_GRPC_PORT", "9999")
 var MONO_TEMPLATES_PATH = getStringParameter("MONO_TEMPLATES_PATH", "../scooter_server/templates/")
 var KAFKA_BROKER = getStringParameter("KAFKA_BROKER", "localhost:9093")
+var KAFKA_PARTITIONS = getUintParameter("KAFKA_PARTITIONS", 3)
+var KAFKA_RETRY_INTERVAL = getDurationParameter("KAFKA_RETRY_INTERVAL", 10*time.Second)
+var FLEET_EVENTS_TOPIC = getStringParameter("FLEET_EVENTS_TOPIC", "fleet_events")
+var LOW_BATTERY_THRESHOLD = getFloatParameter("LOW_BATTERY_THRESHOLD", 20)
+var CHARGING_THRESHOLDS = getStringParameter("CHARGING_THRESHOLDS", "30,20,10")
+var CHARGED_BATTERY = getFloatParameter("CHARGED_BATTERY", 90)
+var GROUNDING_REPORTS = getUintParameter("GROUNDING_REPORTS", 3)
+var FORECAST_HISTORY = getDurationParameter("FORECAST_HISTORY", 28*24*time.Hour)
+var FORECAST_WINDOW = getUintParameter("FORECAST_WINDOW", 24)
+var HEATMAP_CELL_SIZE = getFloatParameter("HEATMAP_CELL_SIZE", 0.005)
+var TELEMETRY_MAX_SPEED = getFloatParameter("TELEMETRY_MAX_SPEED", 60)
+var TELEMETRY_TOLERANCE = getFloatParameter("TELEMETRY_TOLERANCE", 25)
+var PARKING_RADIUS = getFloatParameter("PARKING_RADIUS", 100)
+var TRIP_SETTLE_INTERVAL = getDurationParameter("TRIP_SETTLE_INTERVAL", 30*time.Second)
+var TRIP_SETTLE_BATCH_SIZE = getUintParameter("TRIP_SETTLE_BATCH_SIZE", 100)
+var REBALANCE_MOVE_TIMEOUT = getDurationParameter("REBALANCE_MOVE_TIMEOUT", 30*time.Minute)
+var REBALANCE_EXPIRY_INTERVAL = getDurationParameter("REBALANCE_EXPIRY_INTERVAL", time.Minute)
+var SESSION_TTL = getDurationParameter("SESSION_TTL", 24*time.Hour)
+var DEVICE_TOKEN = getStringParameter("DEVICE_TOKEN", "")
+var SERVICE_TOKEN = getStringParameter("SERVICE_TOKEN", "")
+var TLS_CERT_FILE = getStringParameter("TLS_CERT_FILE", "")
+var TLS_KEY_FILE = getStringParameter("TLS_KEY_FILE", "")
+var TLS_CA_FILE = getStringParameter("TLS_CA_FILE", "")
+var ORDER_TLS_SERVER_NAME = getStringParameter("ORDER_TLS_SERVER_NAME", "order_service")
 
 func getStringParameter(paramName, defaultValue string) string {
 	result, ok := os.LookupEnv(paramName)
@@ -22,3 +49,42 @@ func getStringParameter(paramName, defaultValue string) string {
 	}
 	return result
 }
+
+func getFloatParameter(paramName string, defaultValue float64) float64 {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
+
+func getUintParameter(paramName string, defaultValue uint64) uint64 {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
+
+func getDurationParameter(paramName string, defaultValue time.Duration) time.Duration {
+	value, ok := os.LookupEnv(paramName)
+	if !ok {
+		return defaultValue
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %v value %q, using %v: %v\n", paramName, value, defaultValue, err)
+		return defaultValue
+	}
+	return result
+}
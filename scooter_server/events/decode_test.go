@@ -0,0 +1,117 @@
+package events
+
+import (
+	"encoding/json"
+	"github.com/Shopify/sarama"
+	protobuf "google.golang.org/protobuf/proto"
+	"scooter_micro/proto"
+	"testing"
+	"time"
+)
+
+//sendStatus has the JSON tags of the generated SendStatus, the scooters published it with json.Marshal before
+//the envelopes. The order service doesn't have the scooter protos, so the copies of the test declare it.
+type sendStatus struct {
+	ScooterID     uint64  `json:"scooterID,omitempty"`
+	StationID     uint64  `json:"stationID,omitempty"`
+	Latitude      float64 `json:"latitude,omitempty"`
+	Longitude     float64 `json:"longitude,omitempty"`
+	BatteryRemain float64 `json:"batteryRemain,omitempty"`
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	sent := &sendStatus{ScooterID: 5, StationID: 2, Latitude: 48.42, Longitude: 35.02, BatteryRemain: 87.5}
+	marshalled, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value []byte
+	}{
+		{name: "json.Marshal of SendStatus", value: marshalled},
+		{name: "bytes of the old producers",
+			value: []byte(`{"scooterID":5,"stationID":2,"latitude":48.42,"longitude":35.02,"batteryRemain":87.5}`)},
+		{name: "unknown fields", value: []byte(`{"scooterID":5,"stationID":2,"latitude":48.42,"longitude":35.02,` +
+			`"batteryRemain":87.5,"sequence":7}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := Decode(&sarama.ConsumerMessage{Topic: "scooter", Partition: 1, Offset: 42,
+				Timestamp: timestamp, Value: tt.value})
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if envelope.EventType != TypeScooterStatusReported || envelope.SchemaVersion != LegacyVersion {
+				t.Errorf("Decode() = %v version %d, want %v version %d", envelope.EventType,
+					envelope.SchemaVersion, TypeScooterStatusReported, LegacyVersion)
+			}
+			if envelope.EventID != "scooter-1-42" || !envelope.OccurredAt.AsTime().Equal(timestamp) {
+				t.Errorf("Decode() event ID %q at %v, want %q at %v", envelope.EventID,
+					envelope.OccurredAt.AsTime(), "scooter-1-42", timestamp)
+			}
+
+			status := &proto.ScooterStatusReported{}
+			err = protobuf.Unmarshal(envelope.Payload, status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := &proto.ScooterStatusReported{ScooterID: 5, StationID: 2, Latitude: 48.42, Longitude: 35.02,
+				BatteryRemain: 87.5}
+			if !protobuf.Equal(status, want) {
+				t.Errorf("Decode() payload = %v, want %v", status, want)
+			}
+		})
+	}
+}
+
+func TestDecodeLegacyInvalid(t *testing.T) {
+	_, err := Decode(&sarama.ConsumerMessage{Value: []byte("scooter 5 is at 48.42, 35.02")})
+	if err == nil {
+		t.Error("Decode() of a message which isn't JSON succeeded")
+	}
+}
+
+func TestDecodeEnvelope(t *testing.T) {
+	envelope, err := New(TypeTripEnded, "scooter_server", &proto.ScooterStatusReported{ScooterID: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := Message("trips", "5", envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := message.Value.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var headers []*sarama.RecordHeader
+	for i := range message.Headers {
+		headers = append(headers, &message.Headers[i])
+	}
+	decoded, err := Decode(&sarama.ConsumerMessage{Headers: headers, Value: value})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !protobuf.Equal(decoded, envelope) {
+		t.Errorf("Decode() = %v, want %v", decoded, envelope)
+	}
+
+	envelope.SchemaVersion = SchemaVersions[TypeTripEnded] + 1
+	message, err = Message("trips", "5", envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err = message.Value.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Decode(&sarama.ConsumerMessage{Headers: headers, Value: value})
+	if err == nil {
+		t.Error("Decode() accepted the envelope of a newer schema version")
+	}
+}
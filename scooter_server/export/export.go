@@ -0,0 +1,126 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+//Scooter is the exported state of the scooter. The online scooter is connected to the server now.
+//The connectivity is known only for the scooters which have connected with their certificates,
+//Online is nil for the other scooters, they are "unknown".
+type Scooter struct {
+	ID            uint64  `json:"id"`
+	Model         string  `json:"model"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	BatteryRemain float64 `json:"batteryRemain"`
+	CanBeRent     bool    `json:"rentable"`
+	OutOfService  bool    `json:"outOfService"`
+	Online        *bool   `json:"online"`
+	StationID     uint64  `json:"stationId"`
+}
+
+//Station is the exported station with the number of the scooters parked at it.
+type Station struct {
+	ID        uint64  `json:"id"`
+	Name      string  `json:"name"`
+	IsActive  bool    `json:"isActive"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Scooters  int     `json:"scooters"`
+}
+
+//FeatureCollection is the GeoJSON collection of the features, see RFC 7946.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+//Feature is the GeoJSON feature with the point geometry.
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         uint64                 `json:"id"`
+	Geometry   Point                  `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+//Point is the GeoJSON point, its coordinates are the longitude and the latitude.
+type Point struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+func newFeature(id uint64, latitude, longitude float64, properties map[string]interface{}) Feature {
+	return Feature{Type: "Feature", ID: id, Properties: properties,
+		Geometry: Point{Type: "Point", Coordinates: [2]float64{longitude, latitude}}}
+}
+
+//ScootersGeoJSON returns the scooters as the features of the collection.
+func ScootersGeoJSON(scooters []Scooter) FeatureCollection {
+	collection := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(scooters))}
+	for _, s := range scooters {
+		collection.Features = append(collection.Features, newFeature(s.ID, s.Latitude, s.Longitude,
+			map[string]interface{}{
+				"model":         s.Model,
+				"batteryRemain": s.BatteryRemain,
+				"rentable":      s.CanBeRent,
+				"outOfService":  s.OutOfService,
+				"online":        s.Online,
+				"stationId":     s.StationID,
+			}))
+	}
+	return collection
+}
+
+//StationsGeoJSON returns the stations as the features of the collection.
+func StationsGeoJSON(stations []Station) FeatureCollection {
+	collection := FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(stations))}
+	for _, s := range stations {
+		collection.Features = append(collection.Features, newFeature(s.ID, s.Latitude, s.Longitude,
+			map[string]interface{}{
+				"name":     s.Name,
+				"isActive": s.IsActive,
+				"scooters": s.Scooters,
+			}))
+	}
+	return collection
+}
+
+//WriteScootersCSV writes the scooters as CSV with the header row.
+func WriteScootersCSV(w io.Writer, scooters []Scooter) error {
+	rows := [][]string{{"id", "model", "latitude", "longitude", "battery_remain", "rentable", "out_of_service",
+		"online", "station_id"}}
+	for _, s := range scooters {
+		rows = append(rows, []string{formatUint(s.ID), s.Model, formatFloat(s.Latitude), formatFloat(s.Longitude),
+			formatFloat(s.BatteryRemain), strconv.FormatBool(s.CanBeRent), strconv.FormatBool(s.OutOfService),
+			formatOptionalBool(s.Online), formatUint(s.StationID)})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+//WriteStationsCSV writes the stations as CSV with the header row.
+func WriteStationsCSV(w io.Writer, stations []Station) error {
+	rows := [][]string{{"id", "name", "is_active", "latitude", "longitude", "scooters"}}
+	for _, s := range stations {
+		rows = append(rows, []string{formatUint(s.ID), s.Name, strconv.FormatBool(s.IsActive),
+			formatFloat(s.Latitude), formatFloat(s.Longitude), strconv.Itoa(s.Scooters)})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+func formatUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+//formatOptionalBool formats the unknown value as the empty string.
+func formatOptionalBool(value *bool) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+//encode returns the collection as it is written to the client, decoded back to the generic JSON values.
+func encode(t *testing.T, collection FeatureCollection) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestScootersGeoJSON(t *testing.T) {
+	online := true
+	scooters := []Scooter{
+		{ID: 1, Model: "Xiaomi", Latitude: 53.9, Longitude: 27.56, BatteryRemain: 80.5, CanBeRent: true,
+			Online: &online, StationID: 3},
+		{ID: 2, Model: "Ninebot", Latitude: -33.87, Longitude: 151.21, OutOfService: true},
+	}
+
+	got := encode(t, ScootersGeoJSON(scooters))
+	want := map[string]interface{}{
+		"type": "FeatureCollection",
+		"features": []interface{}{
+			map[string]interface{}{
+				"type":     "Feature",
+				"id":       1.0,
+				"geometry": map[string]interface{}{"type": "Point", "coordinates": []interface{}{27.56, 53.9}},
+				"properties": map[string]interface{}{"model": "Xiaomi", "batteryRemain": 80.5, "rentable": true,
+					"outOfService": false, "online": true, "stationId": 3.0},
+			},
+			map[string]interface{}{
+				"type":     "Feature",
+				"id":       2.0,
+				"geometry": map[string]interface{}{"type": "Point", "coordinates": []interface{}{151.21, -33.87}},
+				"properties": map[string]interface{}{"model": "Ninebot", "batteryRemain": 0.0, "rentable": false,
+					"outOfService": true, "online": nil, "stationId": 0.0},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ScootersGeoJSON = %v, want %v", got, want)
+	}
+}
+
+func TestStationsGeoJSON(t *testing.T) {
+	stations := []Station{{ID: 4, Name: "Central", IsActive: true, Latitude: 53.9, Longitude: 27.56, Scooters: 6}}
+
+	got := encode(t, StationsGeoJSON(stations))
+	want := map[string]interface{}{
+		"type": "FeatureCollection",
+		"features": []interface{}{
+			map[string]interface{}{
+				"type":       "Feature",
+				"id":         4.0,
+				"geometry":   map[string]interface{}{"type": "Point", "coordinates": []interface{}{27.56, 53.9}},
+				"properties": map[string]interface{}{"name": "Central", "isActive": true, "scooters": 6.0},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StationsGeoJSON = %v, want %v", got, want)
+	}
+}
+
+func TestEmptyGeoJSON(t *testing.T) {
+	got := encode(t, ScootersGeoJSON(nil))
+	if features, ok := got["features"].([]interface{}); !ok || len(features) != 0 {
+		t.Errorf("features = %v, want the empty array", got["features"])
+	}
+}
+
+func TestWriteScootersCSV(t *testing.T) {
+	online, offline := true, false
+	scooters := []Scooter{
+		{ID: 1, Model: "Xiaomi", Latitude: 53.9, Longitude: 27.56, BatteryRemain: 80.5, CanBeRent: true,
+			Online: &online, StationID: 3},
+		{ID: 2, Model: "Ninebot, Max", Latitude: -33.87, Longitude: 151.21, OutOfService: true, Online: &offline},
+		{ID: 3, Model: "Segway"},
+	}
+
+	var buf bytes.Buffer
+	err := WriteScootersCSV(&buf, scooters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,model,latitude,longitude,battery_remain,rentable,out_of_service,online,station_id\n" +
+		"1,Xiaomi,53.9,27.56,80.5,true,false,true,3\n" +
+		"2,\"Ninebot, Max\",-33.87,151.21,0,false,true,false,0\n" +
+		"3,Segway,0,0,0,false,false,,0\n"
+	if buf.String() != want {
+		t.Errorf("WriteScootersCSV wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteStationsCSV(t *testing.T) {
+	stations := []Station{
+		{ID: 4, Name: "Central", IsActive: true, Latitude: 53.9, Longitude: 27.56, Scooters: 6},
+		{ID: 5, Name: "Depot", Latitude: 53.85, Longitude: 27.5},
+	}
+
+	var buf bytes.Buffer
+	err := WriteStationsCSV(&buf, stations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,is_active,latitude,longitude,scooters\n" +
+		"4,Central,true,53.9,27.56,6\n" +
+		"5,Depot,false,53.85,27.5,0\n"
+	if buf.String() != want {
+		t.Errorf("WriteStationsCSV wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
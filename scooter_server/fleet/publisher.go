@@ -0,0 +1,140 @@
+package fleet
+
+import (
+	"context"
+	"github.com/Shopify/sarama"
+	protobuf "google.golang.org/protobuf/proto"
+	"log"
+	"scooter_micro/events"
+	"scooter_micro/proto"
+	"scooter_micro/repository"
+	"scooter_micro/transport"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//Source is the source of the fleet events.
+const Source = "scooter_server"
+
+//Publisher publishes the fleet events to the dedicated topic. The events are keyed by the scooter ID,
+//so the events of a scooter are consumed in the order they were published.
+//Publishing never blocks and its failures are logged, they never fail the change which caused the event.
+//The events are dropped until the Publisher is connected to Kafka. The nil Publisher publishes nothing.
+type Publisher struct {
+	Topic      string
+	LowBattery float64
+
+	mu       sync.RWMutex
+	producer sarama.AsyncProducer
+}
+
+//NewPublisher creates a new Publisher. lowBattery is the battery charge below which the scooter
+//is reported as the scooter with the low battery.
+func NewPublisher(topic string, lowBattery float64) *Publisher {
+	return &Publisher{
+		Topic:      topic,
+		LowBattery: lowBattery,
+	}
+}
+
+//Connect creates the producer of the Publisher by calling connect every interval until it succeeds,
+//so the server starts and serves the scooters while Kafka is unavailable. It returns when ctx is done.
+func (p *Publisher) Connect(ctx context.Context, interval time.Duration,
+	connect func() (sarama.AsyncProducer, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		producer, err := connect()
+		if err == nil {
+			p.mu.Lock()
+			p.producer = producer
+			p.mu.Unlock()
+			log.Println("fleet: connected to kafka")
+			return
+		}
+		log.Printf("fleet: failed to connect to kafka, the events are dropped: %v\n", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//StatusChanged publishes the events of the scooter state changes made by the status update.
+func (p *Publisher) StatusChanged(scooterID uint64, previous, current repository.ScooterState) {
+	if p == nil {
+		return
+	}
+
+	if previous.CanBeRent != current.CanBeRent {
+		eventType := events.TypeScooterUnrentable
+		if current.CanBeRent {
+			eventType = events.TypeScooterRentable
+		}
+		p.publish(scooterID, eventType, &proto.ScooterRentableChanged{ScooterID: scooterID,
+			Rentable: current.CanBeRent, BatteryRemain: current.BatteryRemain})
+	}
+
+	if previous.BatteryRemain >= p.LowBattery && current.BatteryRemain < p.LowBattery {
+		p.publish(scooterID, events.TypeScooterBatteryLow, &proto.ScooterBatteryLow{ScooterID: scooterID,
+			BatteryRemain: current.BatteryRemain, Threshold: p.LowBattery})
+	}
+
+	if previous.StationID != current.StationID {
+		p.publish(scooterID, events.TypeScooterStationChanged, &proto.ScooterStationChanged{ScooterID: scooterID,
+			FromStationID: previous.StationID, ToStationID: current.StationID})
+	}
+}
+
+//ConnectivityChanged publishes the event of the scooter which has connected to the server or disconnected from it.
+func (p *Publisher) ConnectivityChanged(scooterID uint64, online bool) {
+	eventType := events.TypeScooterOffline
+	if online {
+		eventType = events.TypeScooterOnline
+	}
+	p.publish(scooterID, eventType, &proto.ScooterConnectivityChanged{ScooterID: scooterID, Online: online})
+}
+
+//TripStarted publishes the event of the started trip.
+func (p *Publisher) TripStarted(trip *repository.Trip) {
+	event := &proto.TripStarted{TripID: trip.ID, ScooterID: trip.ScooterID, UserID: trip.UserID,
+		OrderID: trip.OrderID}
+	if len(trip.Events) > 0 {
+		event.Latitude, event.Longitude = trip.Events[0].Latitude, trip.Events[0].Longitude
+	}
+	p.publish(trip.ScooterID, events.TypeTripStarted, event)
+}
+
+//TripEnded publishes the event of the trip ended at the station.
+func (p *Publisher) TripEnded(trip *repository.Trip, stationID uint64) {
+	event := &proto.TripEnded{TripID: trip.ID, ScooterID: trip.ScooterID, UserID: trip.UserID, StationID: stationID}
+	if len(trip.Events) > 0 {
+		last := trip.Events[len(trip.Events)-1]
+		event.Latitude, event.Longitude = last.Latitude, last.Longitude
+	}
+	p.publish(trip.ScooterID, events.TypeTripEnded, event)
+}
+
+func (p *Publisher) publish(scooterID uint64, eventType string, payload protobuf.Message) {
+	if p == nil {
+		return
+	}
+	p.mu.RLock()
+	producer := p.producer
+	p.mu.RUnlock()
+	if producer == nil {
+		return
+	}
+
+	envelope, err := events.New(eventType, Source, payload)
+	if err == nil {
+		err = transport.SendEvent(producer, p.Topic, strconv.FormatUint(scooterID, 10), envelope)
+	}
+	if err != nil {
+		log.Printf("fleet: failed to publish %v of scooter %d: %v\n", eventType, scooterID, err)
+	}
+}
@@ -0,0 +1,52 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"testing"
+	"time"
+)
+
+func TestPublisherDropsEventsUntilConnected(t *testing.T) {
+	p := NewPublisher("fleet_events", 20)
+	p.ConnectivityChanged(1, true)
+
+	producer := mocks.NewAsyncProducer(t, nil)
+	producer.ExpectInputAndSucceed()
+
+	attempts := 0
+	p.Connect(context.Background(), time.Millisecond, func() (sarama.AsyncProducer, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("kafka is unavailable")
+		}
+		return producer, nil
+	})
+	if attempts != 3 {
+		t.Errorf("connect is called %d times, want 3", attempts)
+	}
+
+	p.ConnectivityChanged(1, false)
+	err := producer.Close()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConnectStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := NewPublisher("fleet_events", 20)
+	p.Connect(ctx, time.Hour, func() (sarama.AsyncProducer, error) {
+		return nil, errors.New("kafka is unavailable")
+	})
+	p.ConnectivityChanged(1, true)
+}
+
+func TestNilPublisher(t *testing.T) {
+	var p *Publisher
+	p.ConnectivityChanged(1, true)
+}
@@ -0,0 +1,114 @@
+package forecast
+
+import (
+	"errors"
+	"time"
+)
+
+//MaxHours is the longest forecast, a week ahead.
+const MaxHours = 7 * 24
+
+//MaxSeriesHours is the longest series, a year of the hourly demand.
+const MaxSeriesHours = 366 * 24
+
+var ErrInvalidHours = errors.New("forecast hours must be from 1 to 168")
+
+//Demand is the number of the trips started at the station during the hour.
+type Demand struct {
+	StationID uint64    `json:"stationId"`
+	Hour      time.Time `json:"hour"`
+	Trips     int       `json:"trips"`
+}
+
+//Hour is the expected number of the trips started at the station during the hour. MovingAverage is the average
+//of the latest hours, Seasonal is the average of the same weekday and hour in the history.
+type Hour struct {
+	Hour          time.Time `json:"hour"`
+	MovingAverage float64   `json:"movingAverage"`
+	Seasonal      float64   `json:"seasonal"`
+}
+
+//Forecast is the hourly demand forecast of the station.
+type Forecast struct {
+	StationID uint64 `json:"stationId"`
+	Hours     []Hour `json:"hours"`
+}
+
+//Series is the hourly demand of the station from the Start hour, the hours without trips are zeros.
+type Series struct {
+	StationID uint64    `json:"stationId"`
+	Start     time.Time `json:"start"`
+	Trips     []int     `json:"trips"`
+}
+
+//NewSeries builds the series of the station from the hours of the demand in [from, to). The demand of other
+//stations and other hours is skipped. The longer period is cut to its latest MaxSeriesHours hours.
+func NewSeries(stationID uint64, demand []Demand, from, to time.Time) Series {
+	from, to = from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour)
+	if to.Sub(from) > MaxSeriesHours*time.Hour {
+		from = to.Add(-MaxSeriesHours * time.Hour)
+	}
+	series := Series{StationID: stationID, Start: from, Trips: make([]int, 0)}
+	if to.After(from) {
+		series.Trips = make([]int, int(to.Sub(from)/time.Hour))
+	}
+
+	for _, d := range demand {
+		i := int(d.Hour.UTC().Truncate(time.Hour).Sub(from) / time.Hour)
+		if d.StationID != stationID || d.Hour.Before(from) || i >= len(series.Trips) {
+			continue
+		}
+		series.Trips[i] += d.Trips
+	}
+	return series
+}
+
+//MovingAverage returns the average of the latest window hours of the series. The shorter series is averaged whole.
+func (s Series) MovingAverage(window int) float64 {
+	if window <= 0 || window > len(s.Trips) {
+		window = len(s.Trips)
+	}
+	if window == 0 {
+		return 0
+	}
+
+	sum := 0
+	for _, trips := range s.Trips[len(s.Trips)-window:] {
+		sum += trips
+	}
+	return float64(sum) / float64(window)
+}
+
+//Seasonal returns the average of the hours of the series which have the same weekday and hour as the given one.
+func (s Series) Seasonal(hour time.Time) float64 {
+	hour = hour.UTC()
+	sum, count := 0, 0
+	for i, trips := range s.Trips {
+		at := s.Start.Add(time.Duration(i) * time.Hour)
+		if at.Weekday() == hour.Weekday() && at.Hour() == hour.Hour() {
+			sum += trips
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+//NewForecast forecasts the demand of the hours following the series. The moving average is the same for all
+//the hours, the seasonal forecast follows the weekly pattern.
+func NewForecast(series Series, hours, window int) (Forecast, error) {
+	if hours < 1 || hours > MaxHours {
+		return Forecast{}, ErrInvalidHours
+	}
+
+	forecast := Forecast{StationID: series.StationID, Hours: make([]Hour, hours)}
+	movingAverage := series.MovingAverage(window)
+	next := series.Start.Add(time.Duration(len(series.Trips)) * time.Hour)
+	for i := range forecast.Hours {
+		hour := next.Add(time.Duration(i) * time.Hour)
+		forecast.Hours[i] = Hour{Hour: hour, MovingAverage: movingAverage, Seasonal: series.Seasonal(hour)}
+	}
+	return forecast, nil
+}
@@ -0,0 +1,103 @@
+package forecast
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var monday = time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+func TestNewSeries(t *testing.T) {
+	demand := []Demand{
+		{StationID: 1, Hour: monday.Add(30 * time.Minute), Trips: 2},
+		{StationID: 1, Hour: monday.Add(2 * time.Hour), Trips: 3},
+		{StationID: 2, Hour: monday, Trips: 7},
+		{StationID: 1, Hour: monday.Add(-time.Hour), Trips: 5},
+		{StationID: 1, Hour: monday.Add(4 * time.Hour), Trips: 5},
+	}
+
+	series := NewSeries(1, demand, monday, monday.Add(4*time.Hour))
+	want := []int{2, 0, 3, 0}
+	if len(series.Trips) != len(want) {
+		t.Fatalf("Trips = %v, want %v", series.Trips, want)
+	}
+	for i := range want {
+		if series.Trips[i] != want[i] {
+			t.Fatalf("Trips = %v, want %v", series.Trips, want)
+		}
+	}
+}
+
+func TestNewSeriesIsCut(t *testing.T) {
+	to := monday.Add(time.Hour)
+	tests := []struct {
+		name string
+		from time.Time
+	}{
+		{name: "two years", from: to.AddDate(-2, 0, 0)},
+		{name: "saturated duration", from: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := NewSeries(1, nil, tt.from, to)
+			if len(series.Trips) != MaxSeriesHours {
+				t.Errorf("series has %d hours, want %d", len(series.Trips), MaxSeriesHours)
+			}
+			if !series.Start.Equal(to.Add(-MaxSeriesHours * time.Hour)) {
+				t.Errorf("series starts at %v, want the latest hours", series.Start)
+			}
+		})
+	}
+}
+
+func TestNewSeriesOfEmptyPeriod(t *testing.T) {
+	series := NewSeries(1, nil, monday, monday.Add(-time.Hour))
+	if series.Trips == nil || len(series.Trips) != 0 {
+		t.Errorf("Trips = %v, want the empty slice", series.Trips)
+	}
+}
+
+func TestForecast(t *testing.T) {
+	//Two weeks with 7 trips every Monday at 8 and one trip every other hour.
+	series := Series{StationID: 1, Start: monday, Trips: make([]int, 2*7*24)}
+	for i := range series.Trips {
+		series.Trips[i] = 1
+		if i%(7*24) == 8 {
+			series.Trips[i] = 7
+		}
+	}
+
+	forecast, err := NewForecast(series, 9, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forecast.Hours) != 9 {
+		t.Fatalf("forecast has %d hours, want 9", len(forecast.Hours))
+	}
+	if !forecast.Hours[0].Hour.Equal(monday.AddDate(0, 0, 14)) {
+		t.Errorf("forecast starts at %v, want the hour after the series", forecast.Hours[0].Hour)
+	}
+	if got := forecast.Hours[8].Seasonal; got != 7 {
+		t.Errorf("seasonal forecast of Monday 8:00 = %v, want 7", got)
+	}
+	if got := forecast.Hours[7].Seasonal; got != 1 {
+		t.Errorf("seasonal forecast of Monday 7:00 = %v, want 1", got)
+	}
+	if got := forecast.Hours[0].MovingAverage; got != 1 {
+		t.Errorf("moving average of the last day = %v, want 1", got)
+	}
+	if got := series.MovingAverage(7 * 24); got != 1+6.0/(7*24) {
+		t.Errorf("moving average of the last week = %v, want %v", got, 1+6.0/(7*24))
+	}
+}
+
+func TestForecastHours(t *testing.T) {
+	for _, hours := range []int{0, MaxHours + 1} {
+		_, err := NewForecast(Series{Start: monday}, hours, 24)
+		if !errors.Is(err, ErrInvalidHours) {
+			t.Errorf("NewForecast(%d hours) error = %v, want %v", hours, err, ErrInvalidHours)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+//Rule is the HTTP route of the RPC, the path is relative to the gateway prefix. The path variables in braces
+//fill the request fields with the same JSON names, the query parameters and the JSON body fill the rest.
+//Only the single segment variables are supported, the whole JSON body is the request.
+type Rule struct {
+	Method string
+	Path   string
+}
+
+//Invoker calls the unary gRPC method by its full name, for example "/proto.ScooterService/GetAllScooters".
+type Invoker func(ctx context.Context, method string, in, out protobuf.Message) error
+
+//Service is the gRPC service served by the gateway.
+type Service struct {
+	Descriptor protoreflect.ServiceDescriptor
+	Invoke     Invoker
+}
+
+//Method is the unary RPC served by the gateway at its route.
+type Method struct {
+	Rule       Rule
+	FullMethod string
+	Input      protoreflect.MessageType
+	Output     protoreflect.MessageType
+	invoke     Invoker
+}
+
+//Remote invokes the methods through the client connection.
+func Remote(conn grpc.ClientConnInterface) Invoker {
+	return func(ctx context.Context, method string, in, out protobuf.Message) error {
+		return conn.Invoke(ctx, method, in, out)
+	}
+}
+
+//Local invokes the methods of the server implementation in the same process through the service description,
+//the way the gRPC server calls them. The user of the context is passed to the server as is.
+func Local(desc *grpc.ServiceDesc, server interface{}) Invoker {
+	handlers := make(map[string]func(srv interface{}, ctx context.Context, dec func(interface{}) error,
+		interceptor grpc.UnaryServerInterceptor) (interface{}, error))
+	for _, method := range desc.Methods {
+		handlers["/"+desc.ServiceName+"/"+method.MethodName] = method.Handler
+	}
+
+	return func(ctx context.Context, method string, in, out protobuf.Message) error {
+		handler, ok := handlers[method]
+		if !ok {
+			return status.Errorf(codes.Unimplemented, "method %s is not served", method)
+		}
+
+		decode := func(v interface{}) error {
+			protobuf.Merge(v.(protobuf.Message), in)
+			return nil
+		}
+		result, err := handler(server, ctx, decode, nil)
+		if err != nil {
+			return err
+		}
+		if message, ok := result.(protobuf.Message); ok && message.ProtoReflect().IsValid() {
+			protobuf.Merge(out, message)
+		}
+		return nil
+	}
+}
+
+//DefaultRule is the route of the RPC without the rule: POST /{Service}/{Method} with the request as the body.
+func DefaultRule(method protoreflect.MethodDescriptor) Rule {
+	return Rule{Method: http.MethodPost, Path: fmt.Sprintf("/%s/%s", method.Parent().Name(), method.Name())}
+}
+
+//HTTPRule returns the route of the RPC from its google.api.http option, the RPC without the option gets
+//the DefaultRule.
+func HTTPRule(method protoreflect.MethodDescriptor) Rule {
+	options, ok := method.Options().(*descriptorpb.MethodOptions)
+	if !ok || !protobuf.HasExtension(options, annotations.E_Http) {
+		return DefaultRule(method)
+	}
+
+	rule := protobuf.GetExtension(options, annotations.E_Http).(*annotations.HttpRule)
+	switch pattern := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		return Rule{Method: http.MethodGet, Path: pattern.Get}
+	case *annotations.HttpRule_Put:
+		return Rule{Method: http.MethodPut, Path: pattern.Put}
+	case *annotations.HttpRule_Post:
+		return Rule{Method: http.MethodPost, Path: pattern.Post}
+	case *annotations.HttpRule_Delete:
+		return Rule{Method: http.MethodDelete, Path: pattern.Delete}
+	case *annotations.HttpRule_Patch:
+		return Rule{Method: http.MethodPatch, Path: pattern.Patch}
+	case *annotations.HttpRule_Custom:
+		return Rule{Method: pattern.Custom.Kind, Path: pattern.Custom.Path}
+	}
+	return DefaultRule(method)
+}
+
+//Methods returns the unary RPCs of the services with their routes. The routes are the HTTPRule of the RPCs,
+//so every new RPC is served. The streaming RPCs are skipped.
+func Methods(services ...Service) ([]Method, error) {
+	var methods []Method
+	for _, service := range services {
+		descriptors := service.Descriptor.Methods()
+		for i := 0; i < descriptors.Len(); i++ {
+			descriptor := descriptors.Get(i)
+			if descriptor.IsStreamingClient() || descriptor.IsStreamingServer() {
+				continue
+			}
+
+			input, err := protoregistry.GlobalTypes.FindMessageByName(descriptor.Input().FullName())
+			if err != nil {
+				return nil, fmt.Errorf("input of %s: %w", descriptor.FullName(), err)
+			}
+			output, err := protoregistry.GlobalTypes.FindMessageByName(descriptor.Output().FullName())
+			if err != nil {
+				return nil, fmt.Errorf("output of %s: %w", descriptor.FullName(), err)
+			}
+
+			fullMethod := fmt.Sprintf("/%s/%s", service.Descriptor.FullName(), descriptor.Name())
+			methods = append(methods, Method{Rule: HTTPRule(descriptor), FullMethod: fullMethod, Input: input,
+				Output: output, invoke: service.Invoke})
+		}
+	}
+	return methods, nil
+}
+
+//Call decodes the request of the RPC and invokes it. The body is the JSON request, the path variables and
+//the query parameters override its fields. The errors of the request are InvalidArgument.
+func (m Method) Call(ctx context.Context, vars map[string]string, query url.Values,
+	body []byte) (protobuf.Message, error) {
+	in := m.Input.New().Interface()
+	if len(body) > 0 {
+		err := protojson.Unmarshal(body, in)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+	}
+
+	err := setFields(in, vars, query)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	out := m.Output.New().Interface()
+	err = m.invoke(ctx, m.FullMethod, in, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+//setFields sets the top-level fields of the message named by the keys of the path variables and the query
+//parameters, by their JSON or proto names. The repeated query parameters fill the repeated fields.
+func setFields(message protobuf.Message, vars map[string]string, query url.Values) error {
+	fields := message.ProtoReflect().Descriptor().Fields()
+	values := make(map[string]interface{})
+	add := func(key string, raw []string) error {
+		field := fields.ByJSONName(key)
+		if field == nil {
+			field = fields.ByName(protoreflect.Name(key))
+		}
+		if field == nil {
+			return fmt.Errorf("unknown field %q", key)
+		}
+
+		var parsed []interface{}
+		for _, value := range raw {
+			if field.Kind() != protoreflect.BoolKind {
+				parsed = append(parsed, value)
+				continue
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("field %q: %v", key, err)
+			}
+			parsed = append(parsed, b)
+		}
+
+		if field.IsList() {
+			values[field.JSONName()] = parsed
+		} else if len(parsed) > 0 {
+			values[field.JSONName()] = parsed[len(parsed)-1]
+		}
+		return nil
+	}
+
+	for key, value := range query {
+		err := add(key, value)
+		if err != nil {
+			return err
+		}
+	}
+	for key, value := range vars {
+		err := add(key, []string{value})
+		if err != nil {
+			return err
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	fieldsMessage := message.ProtoReflect().New().Interface()
+	err = protojson.Unmarshal(encoded, fieldsMessage)
+	if err != nil {
+		return err
+	}
+	protobuf.Merge(message, fieldsMessage)
+	return nil
+}
+
+//HTTPStatus returns the HTTP status of the gRPC code.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,266 @@
+package gateway
+
+import (
+	"context"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"net/http"
+	"net/url"
+	"scooter_micro/proto"
+	"testing"
+)
+
+//httpOption returns the method options with the google.api.http rule.
+func httpOption(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+	options := &descriptorpb.MethodOptions{}
+	protobuf.SetExtension(options, annotations.E_Http, rule)
+	return options
+}
+
+//testFile builds the file with the services of the tests, their messages are the ones of scooter_micro.proto.
+//The BrokenService takes the message which has no registered Go type.
+func testFile(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       protobuf.String("gateway_test.proto"),
+		Package:    protobuf.String("gatewaytest"),
+		Syntax:     protobuf.String("proto3"),
+		Dependency: []string{proto.File_scooter_micro_proto.Path()},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: protobuf.String("Unregistered")},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: protobuf.String("TestService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{Name: protobuf.String("GetScooter"), InputType: protobuf.String(".proto.ScooterID"),
+						OutputType: protobuf.String(".proto.Scooter"),
+						Options: httpOption(&annotations.HttpRule{
+							Pattern: &annotations.HttpRule_Get{Get: "/scooters/{id}"}})},
+					{Name: protobuf.String("SendStatus"), InputType: protobuf.String(".proto.SendStatus"),
+						OutputType: protobuf.String(".proto.Response"),
+						Options: httpOption(&annotations.HttpRule{
+							Pattern: &annotations.HttpRule_Post{Post: "/scooters/{scooterID}/status"},
+							Body:    "*"})},
+					{Name: protobuf.String("Rebalance"), InputType: protobuf.String(".proto.RebalanceRequest"),
+						OutputType: protobuf.String(".proto.RebalancePlan")},
+					{Name: protobuf.String("CheckScooter"), InputType: protobuf.String(".proto.ScooterID"),
+						OutputType: protobuf.String(".proto.Response"),
+						Options: httpOption(&annotations.HttpRule{Pattern: &annotations.HttpRule_Custom{
+							Custom: &annotations.CustomHttpPattern{Kind: http.MethodHead, Path: "/scooters/{id}"}}})},
+					{Name: protobuf.String("Register"), InputType: protobuf.String(".proto.ClientMessage"),
+						OutputType: protobuf.String(".proto.ScooterClient"), ClientStreaming: protobuf.Bool(true),
+						ServerStreaming: protobuf.Bool(true)},
+				},
+			},
+			{
+				Name: protobuf.String("BrokenService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{Name: protobuf.String("Get"), InputType: protobuf.String(".gatewaytest.Unregistered"),
+						OutputType: protobuf.String(".proto.Response")},
+				},
+			},
+		},
+	}
+
+	descriptor, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return descriptor
+}
+
+func TestMethods(t *testing.T) {
+	service := testFile(t).Services().ByName("TestService")
+	methods, err := Methods(Service{Descriptor: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Method{
+		{Rule: Rule{Method: "GET", Path: "/scooters/{id}"}, FullMethod: "/gatewaytest.TestService/GetScooter"},
+		{Rule: Rule{Method: "POST", Path: "/scooters/{scooterID}/status"},
+			FullMethod: "/gatewaytest.TestService/SendStatus"},
+		{Rule: Rule{Method: "POST", Path: "/TestService/Rebalance"}, FullMethod: "/gatewaytest.TestService/Rebalance"},
+		{Rule: Rule{Method: "HEAD", Path: "/scooters/{id}"}, FullMethod: "/gatewaytest.TestService/CheckScooter"},
+	}
+	if len(methods) != len(want) {
+		t.Fatalf("Methods returned %d methods, want %d without the streaming one", len(methods), len(want))
+	}
+	for i, method := range methods {
+		if method.Rule != want[i].Rule || method.FullMethod != want[i].FullMethod {
+			t.Errorf("method %d = %v %v, want %v %v", i, method.FullMethod, method.Rule, want[i].FullMethod,
+				want[i].Rule)
+		}
+	}
+	if name := methods[1].Input.Descriptor().FullName(); name != "proto.SendStatus" {
+		t.Errorf("input of SendStatus = %v, want proto.SendStatus", name)
+	}
+}
+
+func TestMethodsUnregisteredInput(t *testing.T) {
+	_, err := Methods(Service{Descriptor: testFile(t).Services().ByName("BrokenService")})
+	if err == nil {
+		t.Error("Methods returned no error for the input without the Go type")
+	}
+}
+
+func TestHTTPRuleOfScooterService(t *testing.T) {
+	methods := proto.File_scooter_micro_proto.Services().ByName("ScooterService").Methods()
+	rule := HTTPRule(methods.ByName("GetScooterById"))
+	if rule != (Rule{Method: "GET", Path: "/scooters/{id}"}) {
+		t.Errorf("HTTPRule(GetScooterById) = %v, want GET /scooters/{id}", rule)
+	}
+}
+
+func TestCall(t *testing.T) {
+	service := testFile(t).Services().ByName("TestService")
+	var received protobuf.Message
+	invoke := func(ctx context.Context, method string, in, out protobuf.Message) error {
+		received = in
+		return nil
+	}
+	methods, err := Methods(Service{Descriptor: service, Invoke: invoke})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendStatus, rebalance := methods[1], methods[2]
+
+	tests := []struct {
+		name   string
+		method Method
+		vars   map[string]string
+		query  url.Values
+		body   string
+		want   protobuf.Message
+	}{
+		{name: "body", method: sendStatus, body: `{"scooterID": 1, "latitude": 2.5}`,
+			want: &proto.SendStatus{ScooterID: 1, Latitude: 2.5}},
+		{name: "path variable overrides body", method: sendStatus, vars: map[string]string{"scooterID": "7"},
+			body: `{"scooterID": 1, "stationID": 2}`, want: &proto.SendStatus{ScooterID: 7, StationID: 2}},
+		{name: "query", method: sendStatus, query: url.Values{"stationID": {"3"}, "batteryRemain": {"40.5"}},
+			want: &proto.SendStatus{StationID: 3, BatteryRemain: 40.5}},
+		{name: "path variable overrides query", method: sendStatus, vars: map[string]string{"scooterID": "7"},
+			query: url.Values{"scooterID": {"5"}}, want: &proto.SendStatus{ScooterID: 7}},
+		{name: "bool query", method: rebalance, query: url.Values{"dryRun": {"true"}},
+			want: &proto.RebalanceRequest{DryRun: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received = nil
+			_, err := test.method.Call(context.Background(), test.vars, test.query, []byte(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !protobuf.Equal(received, test.want) {
+				t.Errorf("request = %v, want %v", received, test.want)
+			}
+		})
+	}
+}
+
+func TestCallInvalidRequest(t *testing.T) {
+	service := testFile(t).Services().ByName("TestService")
+	invoked := false
+	invoke := func(ctx context.Context, method string, in, out protobuf.Message) error {
+		invoked = true
+		return nil
+	}
+	methods, err := Methods(Service{Descriptor: service, Invoke: invoke})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendStatus, rebalance := methods[1], methods[2]
+
+	tests := []struct {
+		name   string
+		method Method
+		vars   map[string]string
+		query  url.Values
+		body   string
+	}{
+		{name: "invalid body", method: sendStatus, body: `{"scooterID":`},
+		{name: "unknown field", method: sendStatus, query: url.Values{"color": {"red"}}},
+		{name: "invalid number", method: sendStatus, vars: map[string]string{"scooterID": "abc"}},
+		{name: "invalid bool", method: rebalance, query: url.Values{"dryRun": {"maybe"}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			invoked = false
+			_, err := test.method.Call(context.Background(), test.vars, test.query, []byte(test.body))
+			if status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Call error = %v, want InvalidArgument", err)
+			}
+			if invoked {
+				t.Error("invalid request was invoked")
+			}
+		})
+	}
+}
+
+func TestCallReturnsInvokeError(t *testing.T) {
+	service := testFile(t).Services().ByName("TestService")
+	invoke := func(ctx context.Context, method string, in, out protobuf.Message) error {
+		if method == "/gatewaytest.TestService/GetScooter" {
+			out.(*proto.Scooter).Id = in.(*proto.ScooterID).Id
+			return nil
+		}
+		return status.Error(codes.NotFound, "scooter not found")
+	}
+	methods, err := Methods(Service{Descriptor: service, Invoke: invoke})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := methods[0].Call(context.Background(), map[string]string{"id": "4"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scooter, ok := out.(*proto.Scooter); !ok || scooter.Id != 4 {
+		t.Errorf("GetScooter output = %v, want the scooter 4", out)
+	}
+
+	_, err = methods[3].Call(context.Background(), map[string]string{"id": "4"}, nil, nil)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Call error = %v, want the NotFound of the invoker", err)
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.OutOfRange, http.StatusBadRequest},
+		{codes.Unauthenticated, http.StatusUnauthorized},
+		{codes.PermissionDenied, http.StatusForbidden},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.Aborted, http.StatusConflict},
+		{codes.FailedPrecondition, http.StatusConflict},
+		{codes.ResourceExhausted, http.StatusTooManyRequests},
+		{codes.Canceled, 499},
+		{codes.Unimplemented, http.StatusNotImplemented},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unknown, http.StatusInternalServerError},
+		{codes.DataLoss, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		if got := HTTPStatus(test.code); got != test.want {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", test.code, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Kinds of the maintenance tasks.
+const (
+	KindCharging = "charging"
+	KindRepair   = "repair"
+)
+
+//States of the maintenance tasks. The open task waits for the operator, the assigned one is taken by
+//the operator, done and cancelled tasks are closed.
+const (
+	StateOpen      = "open"
+	StateAssigned  = "assigned"
+	StateDone      = "done"
+	StateCancelled = "cancelled"
+)
+
+//Severities of the damage reports. The major damage opens the repair task with the high priority, the scooter
+//is taken out of service by the operator or by several independent major damage reports.
+const (
+	SeverityMinor = "minor"
+	SeverityMajor = "major"
+)
+
+var (
+	ErrInvalidThresholds = errors.New("invalid charging thresholds")
+	ErrInvalidSeverity   = errors.New("severity must be minor or major")
+)
+
+//Thresholds define when the scooter needs charging. Every crossed level raises the priority of the charging task,
+//the task is done when the battery is charged up to the Charged level.
+type Thresholds struct {
+	Levels  []float64
+	Charged float64
+}
+
+//NewThresholds creates the thresholds with the levels sorted from the highest to the lowest.
+func NewThresholds(charged float64, levels ...float64) (Thresholds, error) {
+	if len(levels) == 0 {
+		return Thresholds{}, fmt.Errorf("%w: no levels", ErrInvalidThresholds)
+	}
+
+	sorted := append([]float64{}, levels...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+	if sorted[0] >= charged {
+		return Thresholds{}, fmt.Errorf("%w: level %v is not below the charged level %v", ErrInvalidThresholds,
+			sorted[0], charged)
+	}
+	return Thresholds{Levels: sorted, Charged: charged}, nil
+}
+
+//ParseThresholds creates the thresholds from the comma separated levels, for example "30,15,5".
+func ParseThresholds(charged float64, levels string) (Thresholds, error) {
+	var parsed []float64
+	for _, level := range strings.Split(levels, ",") {
+		value, err := strconv.ParseFloat(strings.TrimSpace(level), 64)
+		if err != nil {
+			return Thresholds{}, fmt.Errorf("%w: %v", ErrInvalidThresholds, err)
+		}
+		parsed = append(parsed, value)
+	}
+	return NewThresholds(charged, parsed...)
+}
+
+//Priority returns the number of the levels the battery charge is at or below. Zero means no charging is needed.
+func (t Thresholds) Priority(batteryRemain float64) int {
+	priority := 0
+	for _, level := range t.Levels {
+		if batteryRemain <= level {
+			priority++
+		}
+	}
+	return priority
+}
+
+//Crossed reports whether the battery has dropped to a level it wasn't at before.
+func (t Thresholds) Crossed(previous, current float64) bool {
+	return t.Priority(current) > t.Priority(previous)
+}
+
+//Restored reports whether the battery has been charged up to the Charged level.
+func (t Thresholds) Restored(previous, current float64) bool {
+	return previous < t.Charged && current >= t.Charged
+}
+
+//RepairPriority returns the priority of the repair task opened by the damage report of the severity.
+func RepairPriority(severity string) (int, error) {
+	switch severity {
+	case SeverityMinor:
+		return 1, nil
+	case SeverityMajor:
+		return 2, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrInvalidSeverity, severity)
+}
+
+//Grounds reports whether the number of the riders who independently reported the major damage is enough
+//to take the scooter out of service. The zero threshold leaves it to the operators.
+func Grounds(reporters, threshold int) bool {
+	return threshold > 0 && reporters >= threshold
+}
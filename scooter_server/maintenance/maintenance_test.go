@@ -0,0 +1,69 @@
+package maintenance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRepairPriority(t *testing.T) {
+	minor, err := RepairPriority(SeverityMinor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	major, err := RepairPriority(SeverityMajor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if major <= minor {
+		t.Errorf("major damage priority %d isn't above the minor one %d", major, minor)
+	}
+
+	_, err = RepairPriority("scratch")
+	if !errors.Is(err, ErrInvalidSeverity) {
+		t.Errorf("RepairPriority error = %v, want %v", err, ErrInvalidSeverity)
+	}
+}
+
+func TestGrounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		reporters int
+		threshold int
+		want      bool
+	}{
+		{name: "one rider", reporters: 1, threshold: 3},
+		{name: "enough riders", reporters: 3, threshold: 3, want: true},
+		{name: "more riders", reporters: 4, threshold: 3, want: true},
+		{name: "left to operators", reporters: 10, threshold: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Grounds(tt.reporters, tt.threshold)
+			if got != tt.want {
+				t.Errorf("Grounds(%d, %d) = %v, want %v", tt.reporters, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds(90, "10, 30,20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := thresholds.Priority(25); got != 1 {
+		t.Errorf("Priority(25) = %d, want 1", got)
+	}
+	if got := thresholds.Priority(5); got != 3 {
+		t.Errorf("Priority(5) = %d, want 3", got)
+	}
+	if !thresholds.Restored(80, 90) || thresholds.Restored(90, 95) {
+		t.Error("Restored doesn't report the crossing of the charged level only")
+	}
+
+	_, err = ParseThresholds(90, "95")
+	if !errors.Is(err, ErrInvalidThresholds) {
+		t.Errorf("ParseThresholds error = %v, want %v", err, ErrInvalidThresholds)
+	}
+}
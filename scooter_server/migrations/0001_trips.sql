@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS trips
+(
+    id              SERIAL PRIMARY KEY,
+    user_id         INT         NOT NULL DEFAULT 0,
+    scooter_id      INT         NOT NULL REFERENCES scooters (id),
+    state           VARCHAR(16) NOT NULL,
+    status_start_id INT REFERENCES scooter_statuses_in_rent (id),
+    status_end_id   INT REFERENCES scooter_statuses_in_rent (id),
+    order_id        INT,
+    started_at      TIMESTAMP   NOT NULL DEFAULT now(),
+    ended_at        TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS trips_one_open_per_scooter ON trips (scooter_id) WHERE state <> 'ended';
+
+CREATE TABLE IF NOT EXISTS trip_events
+(
+    id        SERIAL PRIMARY KEY,
+    trip_id   INT              NOT NULL REFERENCES trips (id),
+    state     VARCHAR(16)      NOT NULL,
+    date_time TIMESTAMP        NOT NULL DEFAULT now(),
+    latitude  DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS trip_events_trip_id ON trip_events (trip_id);
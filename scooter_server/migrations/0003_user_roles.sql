@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'rider'
+        CHECK (role IN ('rider', 'operator', 'admin', 'device'));
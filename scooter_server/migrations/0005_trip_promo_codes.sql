@@ -0,0 +1,2 @@
+ALTER TABLE trips
+    ADD COLUMN IF NOT EXISTS promo_code VARCHAR(32) NOT NULL DEFAULT '';
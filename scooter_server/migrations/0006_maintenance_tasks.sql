@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS maintenance_tasks
+(
+    id             SERIAL PRIMARY KEY,
+    scooter_id     INT              NOT NULL REFERENCES scooters (id),
+    kind           VARCHAR(16)      NOT NULL,
+    state          VARCHAR(16)      NOT NULL DEFAULT 'open',
+    priority       INT              NOT NULL DEFAULT 0,
+    battery_remain DOUBLE PRECISION NOT NULL DEFAULT 0,
+    station_id     INT,
+    assignee_id    INT REFERENCES users (id),
+    created_at     TIMESTAMP        NOT NULL DEFAULT now(),
+    assigned_at    TIMESTAMP,
+    closed_at      TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS maintenance_tasks_one_open_per_kind ON maintenance_tasks (scooter_id, kind)
+    WHERE state IN ('open', 'assigned');
+
+CREATE INDEX IF NOT EXISTS maintenance_tasks_queue ON maintenance_tasks (priority DESC, created_at)
+    WHERE state IN ('open', 'assigned');
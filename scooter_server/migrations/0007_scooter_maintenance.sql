@@ -0,0 +1,27 @@
+ALTER TABLE scooters
+    ADD COLUMN IF NOT EXISTS out_of_service BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS damage_reports
+(
+    id          SERIAL PRIMARY KEY,
+    scooter_id  INT         NOT NULL REFERENCES scooters (id),
+    reporter_id INT         NOT NULL REFERENCES users (id),
+    severity    VARCHAR(16) NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    task_id     INT REFERENCES maintenance_tasks (id),
+    created_at  TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS damage_reports_scooter_id ON damage_reports (scooter_id);
+
+CREATE TABLE IF NOT EXISTS scooter_service_changes
+(
+    id             SERIAL PRIMARY KEY,
+    scooter_id     INT       NOT NULL REFERENCES scooters (id),
+    out_of_service BOOLEAN   NOT NULL,
+    reason         TEXT      NOT NULL DEFAULT '',
+    actor_id       INT,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS scooter_service_changes_scooter_id ON scooter_service_changes (scooter_id);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS station_targets
+(
+    station_id INT PRIMARY KEY REFERENCES scooter_stations (id),
+    target     INT       NOT NULL CHECK (target >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
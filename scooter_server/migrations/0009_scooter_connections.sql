@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS scooter_connections
+(
+    id              SERIAL PRIMARY KEY,
+    scooter_id      INT       NOT NULL REFERENCES scooters (id),
+    connected_at    TIMESTAMP NOT NULL DEFAULT now(),
+    disconnected_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS scooter_connections_scooter_id ON scooter_connections (scooter_id);
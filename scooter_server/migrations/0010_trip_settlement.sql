@@ -0,0 +1,5 @@
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS settled_at TIMESTAMP;
+
+UPDATE trips SET settled_at = ended_at WHERE state = 'ended' AND settled_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS trips_unsettled ON trips (id) WHERE state = 'ended' AND settled_at IS NULL;
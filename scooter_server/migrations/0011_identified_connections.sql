@@ -0,0 +1,3 @@
+-- The connections were recorded for the arbitrary scooters bound to the streams without the certificates,
+-- they can't be told apart from the connections of the identified scooters.
+DELETE FROM scooter_connections;
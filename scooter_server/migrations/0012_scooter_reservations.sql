@@ -0,0 +1,8 @@
+-- The scooters reserved for the moves between the stations, the reservation of the scooter which hasn't reached
+-- its new station until it expires is released.
+CREATE TABLE IF NOT EXISTS scooter_reservations
+(
+    scooter_id INT PRIMARY KEY REFERENCES scooters (id),
+    station_id INT       NOT NULL REFERENCES scooter_stations (id),
+    expires_at TIMESTAMP NOT NULL
+);
@@ -32,6 +32,12 @@ type ScooterServiceClient interface {
 	CreateScooterStatusInRent(ctx context.Context, in *ScooterID, opts ...grpc.CallOption) (*ScooterStatusInRent, error)
 	GetStationByID(ctx context.Context, in *StationID, opts ...grpc.CallOption) (*Station, error)
 	GetAllStations(ctx context.Context, in *Request, opts ...grpc.CallOption) (*StationList, error)
+	SendCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResult, error)
+	GetTasks(ctx context.Context, in *TaskFilter, opts ...grpc.CallOption) (*TaskList, error)
+	AssignTask(ctx context.Context, in *TaskAssignment, opts ...grpc.CallOption) (*Task, error)
+	CompleteTask(ctx context.Context, in *TaskID, opts ...grpc.CallOption) (*Task, error)
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalancePlan, error)
+	GetDemandForecast(ctx context.Context, in *ForecastRequest, opts ...grpc.CallOption) (*DemandForecast, error)
 }
 
 type scooterServiceClient struct {
@@ -179,6 +185,60 @@ func (c *scooterServiceClient) GetAllStations(ctx context.Context, in *Request,
 	return out, nil
 }
 
+func (c *scooterServiceClient) SendCommand(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResult, error) {
+	out := new(CommandResult)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/SendCommand", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scooterServiceClient) GetTasks(ctx context.Context, in *TaskFilter, opts ...grpc.CallOption) (*TaskList, error) {
+	out := new(TaskList)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/GetTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scooterServiceClient) AssignTask(ctx context.Context, in *TaskAssignment, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/AssignTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scooterServiceClient) CompleteTask(ctx context.Context, in *TaskID, opts ...grpc.CallOption) (*Task, error) {
+	out := new(Task)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/CompleteTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scooterServiceClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalancePlan, error) {
+	out := new(RebalancePlan)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/Rebalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scooterServiceClient) GetDemandForecast(ctx context.Context, in *ForecastRequest, opts ...grpc.CallOption) (*DemandForecast, error) {
+	out := new(DemandForecast)
+	err := c.cc.Invoke(ctx, "/proto.ScooterService/GetDemandForecast", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ScooterServiceServer is the server API for ScooterService service.
 // All implementations must embed UnimplementedScooterServiceServer
 // for forward compatibility
@@ -193,6 +253,12 @@ type ScooterServiceServer interface {
 	CreateScooterStatusInRent(context.Context, *ScooterID) (*ScooterStatusInRent, error)
 	GetStationByID(context.Context, *StationID) (*Station, error)
 	GetAllStations(context.Context, *Request) (*StationList, error)
+	SendCommand(context.Context, *CommandRequest) (*CommandResult, error)
+	GetTasks(context.Context, *TaskFilter) (*TaskList, error)
+	AssignTask(context.Context, *TaskAssignment) (*Task, error)
+	CompleteTask(context.Context, *TaskID) (*Task, error)
+	Rebalance(context.Context, *RebalanceRequest) (*RebalancePlan, error)
+	GetDemandForecast(context.Context, *ForecastRequest) (*DemandForecast, error)
 	mustEmbedUnimplementedScooterServiceServer()
 }
 
@@ -230,6 +296,24 @@ func (UnimplementedScooterServiceServer) GetStationByID(context.Context, *Statio
 func (UnimplementedScooterServiceServer) GetAllStations(context.Context, *Request) (*StationList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllStations not implemented")
 }
+func (UnimplementedScooterServiceServer) SendCommand(context.Context, *CommandRequest) (*CommandResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendCommand not implemented")
+}
+func (UnimplementedScooterServiceServer) GetTasks(context.Context, *TaskFilter) (*TaskList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTasks not implemented")
+}
+func (UnimplementedScooterServiceServer) AssignTask(context.Context, *TaskAssignment) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignTask not implemented")
+}
+func (UnimplementedScooterServiceServer) CompleteTask(context.Context, *TaskID) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteTask not implemented")
+}
+func (UnimplementedScooterServiceServer) Rebalance(context.Context, *RebalanceRequest) (*RebalancePlan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rebalance not implemented")
+}
+func (UnimplementedScooterServiceServer) GetDemandForecast(context.Context, *ForecastRequest) (*DemandForecast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDemandForecast not implemented")
+}
 func (UnimplementedScooterServiceServer) mustEmbedUnimplementedScooterServiceServer() {}
 
 // UnsafeScooterServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -439,6 +523,114 @@ func _ScooterService_GetAllStations_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ScooterService_SendCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).SendCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/SendCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).SendCommand(ctx, req.(*CommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScooterService_GetTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).GetTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/GetTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).GetTasks(ctx, req.(*TaskFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScooterService_AssignTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskAssignment)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).AssignTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/AssignTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).AssignTask(ctx, req.(*TaskAssignment))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScooterService_CompleteTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).CompleteTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/CompleteTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).CompleteTask(ctx, req.(*TaskID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScooterService_Rebalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).Rebalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/Rebalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).Rebalance(ctx, req.(*RebalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScooterService_GetDemandForecast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForecastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScooterServiceServer).GetDemandForecast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.ScooterService/GetDemandForecast",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScooterServiceServer).GetDemandForecast(ctx, req.(*ForecastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ScooterService_ServiceDesc is the grpc.ServiceDesc for ScooterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -478,6 +670,30 @@ var ScooterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllStations",
 			Handler:    _ScooterService_GetAllStations_Handler,
 		},
+		{
+			MethodName: "SendCommand",
+			Handler:    _ScooterService_SendCommand_Handler,
+		},
+		{
+			MethodName: "GetTasks",
+			Handler:    _ScooterService_GetTasks_Handler,
+		},
+		{
+			MethodName: "AssignTask",
+			Handler:    _ScooterService_AssignTask_Handler,
+		},
+		{
+			MethodName: "CompleteTask",
+			Handler:    _ScooterService_CompleteTask_Handler,
+		},
+		{
+			MethodName: "Rebalance",
+			Handler:    _ScooterService_Rebalance_Handler,
+		},
+		{
+			MethodName: "GetDemandForecast",
+			Handler:    _ScooterService_GetDemandForecast_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -0,0 +1,172 @@
+package rebalance
+
+import (
+	"math"
+	"scooter_micro/telemetry"
+	"sort"
+)
+
+//Scooter is the scooter which can be moved by the planner.
+type Scooter struct {
+	ID            uint64
+	BatteryRemain float64
+}
+
+//Station is the station with its available scooters. The station without the target keeps its scooters
+//and gets no more.
+type Station struct {
+	ID        uint64
+	Latitude  float64
+	Longitude float64
+	Target    int
+	HasTarget bool
+	Scooters  []Scooter
+}
+
+//Move is the job to move the scooter from one station to another. Failure is the reason the move
+//wasn't dispatched, it is empty for the planned and the dispatched moves.
+type Move struct {
+	ScooterID     uint64  `json:"scooterId"`
+	FromStationID uint64  `json:"fromStationId"`
+	ToStationID   uint64  `json:"toStationId"`
+	Distance      float64 `json:"distance"`
+	Failure       string  `json:"failure,omitempty"`
+}
+
+//Plan is the list of the moves which bring the stations as close to their targets as possible.
+//The distances are in meters.
+type Plan struct {
+	Moves         []Move  `json:"moves"`
+	TotalDistance float64 `json:"totalDistance"`
+}
+
+//NewPlan plans the moves from the stations over their targets to the stations under their targets.
+//The number of the moves is the smaller of the total surplus and the total deficit, the moves are chosen
+//to minimize the total distance. The scooters with the most charged batteries are moved first.
+func NewPlan(stations []Station) Plan {
+	var surplus, deficit []int
+	for i, station := range stations {
+		if !station.HasTarget {
+			continue
+		}
+		switch {
+		case len(station.Scooters) > station.Target:
+			surplus = append(surplus, i)
+		case len(station.Scooters) < station.Target:
+			deficit = append(deficit, i)
+		}
+	}
+
+	plan := Plan{Moves: []Move{}}
+	if len(surplus) == 0 || len(deficit) == 0 {
+		return plan
+	}
+
+	//The network is source -> surplus stations -> deficit stations -> sink.
+	source, sink := 0, len(surplus)+len(deficit)+1
+	network := newNetwork(sink + 1)
+	for i, s := range surplus {
+		network.addEdge(source, 1+i, len(stations[s].Scooters)-stations[s].Target, 0)
+		for j, d := range deficit {
+			network.addEdge(1+i, 1+len(surplus)+j, math.MaxInt32, distance(stations[s], stations[d]))
+		}
+	}
+	for j, d := range deficit {
+		network.addEdge(1+len(surplus)+j, sink, stations[d].Target-len(stations[d].Scooters), 0)
+	}
+	network.minCostFlow(source, sink)
+
+	for i, s := range surplus {
+		from := stations[s]
+		scooters := append([]Scooter{}, from.Scooters...)
+		sort.SliceStable(scooters, func(a, b int) bool {
+			return scooters[a].BatteryRemain > scooters[b].BatteryRemain
+		})
+
+		for _, e := range network.edges[1+i] {
+			if e.to <= len(surplus) || e.to == sink || e.flow <= 0 {
+				continue
+			}
+			to := stations[deficit[e.to-1-len(surplus)]]
+			for k := 0; k < e.flow; k++ {
+				plan.Moves = append(plan.Moves, Move{ScooterID: scooters[0].ID, FromStationID: from.ID,
+					ToStationID: to.ID, Distance: e.cost})
+				plan.TotalDistance += e.cost
+				scooters = scooters[1:]
+			}
+		}
+	}
+	return plan
+}
+
+func distance(from, to Station) float64 {
+	return telemetry.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+}
+
+type edge struct {
+	to, reverse    int
+	capacity, flow int
+	cost           float64
+}
+
+//network is the flow network solved by the successive shortest paths.
+type network struct {
+	edges [][]*edge
+}
+
+func newNetwork(nodes int) *network {
+	return &network{edges: make([][]*edge, nodes)}
+}
+
+func (n *network) addEdge(from, to, capacity int, cost float64) {
+	n.edges[from] = append(n.edges[from], &edge{to: to, reverse: len(n.edges[to]), capacity: capacity, cost: cost})
+	n.edges[to] = append(n.edges[to], &edge{to: from, reverse: len(n.edges[from]) - 1, cost: -cost})
+}
+
+//minCostFlow pushes the maximum flow from the source to the sink along the cheapest paths.
+//Bellman-Ford is used because the residual edges have negative costs.
+func (n *network) minCostFlow(source, sink int) {
+	const epsilon = 1e-9
+	for {
+		dist := make([]float64, len(n.edges))
+		previous := make([]*edge, len(n.edges))
+		for i := range dist {
+			dist[i] = math.Inf(1)
+		}
+		dist[source] = 0
+
+		for updated := true; updated; {
+			updated = false
+			for node, edges := range n.edges {
+				if math.IsInf(dist[node], 1) {
+					continue
+				}
+				for _, e := range edges {
+					if e.capacity-e.flow > 0 && dist[node]+e.cost < dist[e.to]-epsilon {
+						dist[e.to] = dist[node] + e.cost
+						previous[e.to] = e
+						updated = true
+					}
+				}
+			}
+		}
+		if math.IsInf(dist[sink], 1) {
+			return
+		}
+
+		push := math.MaxInt32
+		for node := sink; node != source; {
+			e := previous[node]
+			if e.capacity-e.flow < push {
+				push = e.capacity - e.flow
+			}
+			node = n.edges[e.to][e.reverse].to
+		}
+		for node := sink; node != source; {
+			e := previous[node]
+			e.flow += push
+			n.edges[e.to][e.reverse].flow -= push
+			node = n.edges[e.to][e.reverse].to
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package rebalance
+
+import (
+	"math"
+	"testing"
+)
+
+func scooters(batteries ...float64) []Scooter {
+	list := make([]Scooter, len(batteries))
+	for i, battery := range batteries {
+		list[i] = Scooter{ID: uint64(i + 1), BatteryRemain: battery}
+	}
+	return list
+}
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		stations []Station
+		want     []Move
+	}{
+		{name: "no stations"},
+		{name: "no targets", stations: []Station{
+			{ID: 1, Scooters: scooters(80, 90)},
+			{ID: 2, Latitude: 0.01},
+		}},
+		{name: "no deficit", stations: []Station{
+			{ID: 1, Target: 1, HasTarget: true, Scooters: scooters(80, 90)},
+			{ID: 2, Latitude: 0.01, Target: 0, HasTarget: true},
+		}},
+		{name: "station without target gets no scooters", stations: []Station{
+			{ID: 1, Target: 0, HasTarget: true, Scooters: scooters(80)},
+			{ID: 2, Latitude: 0.01},
+		}},
+		{name: "nearest deficit", stations: []Station{
+			{ID: 1, Target: 0, HasTarget: true, Scooters: scooters(80)},
+			{ID: 2, Latitude: 0.1, Target: 1, HasTarget: true},
+			{ID: 3, Latitude: 0.01, Target: 1, HasTarget: true},
+		}, want: []Move{{ScooterID: 1, FromStationID: 1, ToStationID: 3}}},
+		{name: "most charged first", stations: []Station{
+			{ID: 1, Target: 1, HasTarget: true, Scooters: scooters(40, 95, 70)},
+			{ID: 2, Latitude: 0.01, Target: 2, HasTarget: true},
+		}, want: []Move{
+			{ScooterID: 2, FromStationID: 1, ToStationID: 2},
+			{ScooterID: 3, FromStationID: 1, ToStationID: 2},
+		}},
+		{name: "surplus split between deficits", stations: []Station{
+			{ID: 1, Target: 1, HasTarget: true, Scooters: scooters(50, 60, 70)},
+			{ID: 2, Latitude: 0.01, Target: 1, HasTarget: true},
+			{ID: 3, Latitude: 0.1, Target: 3, HasTarget: true, Scooters: []Scooter{{ID: 10}, {ID: 11}}},
+		}, want: []Move{
+			{ScooterID: 3, FromStationID: 1, ToStationID: 2},
+			{ScooterID: 2, FromStationID: 1, ToStationID: 3},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := NewPlan(tt.stations)
+			if len(plan.Moves) != len(tt.want) {
+				t.Fatalf("NewPlan() moves = %+v, want %+v", plan.Moves, tt.want)
+			}
+
+			var total float64
+			for _, move := range plan.Moves {
+				total += move.Distance
+			}
+			if math.Abs(plan.TotalDistance-total) > 1e-6 {
+				t.Errorf("TotalDistance = %v, want the sum of the moves %v", plan.TotalDistance, total)
+			}
+
+			for _, want := range tt.want {
+				if !containsMove(plan.Moves, want) {
+					t.Errorf("NewPlan() moves = %+v, want the move %+v", plan.Moves, want)
+				}
+			}
+		})
+	}
+}
+
+func containsMove(moves []Move, want Move) bool {
+	for _, move := range moves {
+		if move.ScooterID == want.ScooterID && move.FromStationID == want.FromStationID &&
+			move.ToStationID == want.ToStationID {
+			return true
+		}
+	}
+	return false
+}
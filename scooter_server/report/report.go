@@ -0,0 +1,205 @@
+package report
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCellSize = errors.New("heatmap cell size must be positive")
+
+//Point is the place where the trip has started or ended.
+type Point struct {
+	Latitude  float64
+	Longitude float64
+	End       bool
+}
+
+//Tile is the cell of the heatmap grid with the number of the trips started and ended in it. Latitude and Longitude
+//are the south-west corner of the cell.
+type Tile struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Starts    int     `json:"starts"`
+	Ends      int     `json:"ends"`
+}
+
+//Heatmap is the grid of the tiles with the trips, the empty tiles are skipped. CellSize is in degrees.
+type Heatmap struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	CellSize float64   `json:"cellSize"`
+	Tiles    []Tile    `json:"tiles"`
+}
+
+//NewHeatmap bins the points into the grid cells of the size, the tiles are sorted from the south-west.
+func NewHeatmap(points []Point, cellSize float64) (Heatmap, error) {
+	if cellSize <= 0 || math.IsNaN(cellSize) || math.IsInf(cellSize, 0) {
+		return Heatmap{}, ErrInvalidCellSize
+	}
+
+	type cell struct{ row, col int64 }
+	tiles := make(map[cell]*Tile)
+	for _, point := range points {
+		c := cell{row: int64(math.Floor(point.Latitude / cellSize)), col: int64(math.Floor(point.Longitude / cellSize))}
+		tile, ok := tiles[c]
+		if !ok {
+			tile = &Tile{Latitude: float64(c.row) * cellSize, Longitude: float64(c.col) * cellSize}
+			tiles[c] = tile
+		}
+		if point.End {
+			tile.Ends++
+		} else {
+			tile.Starts++
+		}
+	}
+
+	heatmap := Heatmap{CellSize: cellSize, Tiles: make([]Tile, 0, len(tiles))}
+	for _, tile := range tiles {
+		heatmap.Tiles = append(heatmap.Tiles, *tile)
+	}
+	sort.Slice(heatmap.Tiles, func(i, j int) bool {
+		if heatmap.Tiles[i].Latitude != heatmap.Tiles[j].Latitude {
+			return heatmap.Tiles[i].Latitude < heatmap.Tiles[j].Latitude
+		}
+		return heatmap.Tiles[i].Longitude < heatmap.Tiles[j].Longitude
+	})
+	return heatmap, nil
+}
+
+//Interval is the period of time, the zero End means the period hasn't ended yet.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+//Usage is the history of the scooter: the periods it was rented and connected to the server.
+//The connections are recorded only for the scooters identified by their certificates, Identified is false
+//for the scooter which hadn't connected before the end of the period.
+type Usage struct {
+	ScooterID   uint64
+	Identified  bool
+	Rentals     []Interval
+	Connections []Interval
+}
+
+//Utilization is the time of the scooter in the period split into the minutes rented, idle and offline.
+//The rented scooter is never counted offline, the idle one is connected, but not rented.
+//The idle and offline minutes of the scooter which isn't identified are unknown, they are nil.
+type Utilization struct {
+	ScooterID      uint64   `json:"scooterId"`
+	RentedMinutes  float64  `json:"rentedMinutes"`
+	IdleMinutes    *float64 `json:"idleMinutes"`
+	OfflineMinutes *float64 `json:"offlineMinutes"`
+}
+
+//NewUtilization splits the period [from, to) of the scooter by its usage.
+func NewUtilization(usage Usage, from, to time.Time) Utilization {
+	utilization := Utilization{ScooterID: usage.ScooterID}
+	var idle, offline float64
+	if usage.Identified {
+		utilization.IdleMinutes, utilization.OfflineMinutes = &idle, &offline
+	}
+	if !to.After(from) {
+		return utilization
+	}
+
+	rented := length(merge(usage.Rentals, from, to))
+	online := length(merge(append(append([]Interval{}, usage.Rentals...), usage.Connections...), from, to))
+	utilization.RentedMinutes = rented.Minutes()
+	idle = (online - rented).Minutes()
+	offline = (to.Sub(from) - online).Minutes()
+	return utilization
+}
+
+//merge clips the intervals to [from, to) and joins the overlapping ones. The unfinished interval ends at to.
+func merge(intervals []Interval, from, to time.Time) []Interval {
+	var clipped []Interval
+	for _, interval := range intervals {
+		if interval.End.IsZero() || interval.End.After(to) {
+			interval.End = to
+		}
+		if interval.Start.Before(from) {
+			interval.Start = from
+		}
+		if interval.End.After(interval.Start) {
+			clipped = append(clipped, interval)
+		}
+	}
+	sort.Slice(clipped, func(i, j int) bool {
+		return clipped[i].Start.Before(clipped[j].Start)
+	})
+
+	var merged []Interval
+	for _, interval := range clipped {
+		last := len(merged) - 1
+		if last >= 0 && !interval.Start.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+func length(intervals []Interval) time.Duration {
+	var total time.Duration
+	for _, interval := range intervals {
+		total += interval.End.Sub(interval.Start)
+	}
+	return total
+}
+
+//WriteHeatmapCSV writes the tiles of the heatmap as CSV with the header row.
+func WriteHeatmapCSV(w io.Writer, heatmap Heatmap) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"latitude", "longitude", "starts", "ends"})
+	if err != nil {
+		return err
+	}
+	for _, tile := range heatmap.Tiles {
+		err = writer.Write([]string{formatFloat(tile.Latitude), formatFloat(tile.Longitude), strconv.Itoa(tile.Starts),
+			strconv.Itoa(tile.Ends)})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//WriteUtilizationCSV writes the utilization of the scooters as CSV with the header row.
+func WriteUtilizationCSV(w io.Writer, utilization []Utilization) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"scooter_id", "rented_minutes", "idle_minutes", "offline_minutes"})
+	if err != nil {
+		return err
+	}
+	for _, u := range utilization {
+		err = writer.Write([]string{strconv.FormatUint(u.ScooterID, 10), formatFloat(u.RentedMinutes),
+			formatOptionalFloat(u.IdleMinutes), formatOptionalFloat(u.OfflineMinutes)})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//formatOptionalFloat formats the unknown value as the empty string.
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return formatFloat(*value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
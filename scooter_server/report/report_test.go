@@ -0,0 +1,147 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var from = time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return from.Add(time.Duration(minutes) * time.Minute)
+}
+
+func TestNewUtilization(t *testing.T) {
+	tests := []struct {
+		name        string
+		usage       Usage
+		wantRented  float64
+		wantIdle    float64
+		wantOffline float64
+	}{
+		{name: "never connected", usage: Usage{Identified: true}, wantOffline: 60},
+		{name: "rented and connected", usage: Usage{Identified: true,
+			Rentals:     []Interval{{Start: at(10), End: at(20)}},
+			Connections: []Interval{{Start: at(0), End: at(30)}}},
+			wantRented: 10, wantIdle: 20, wantOffline: 30},
+		{name: "rented while offline", usage: Usage{Identified: true,
+			Rentals: []Interval{{Start: at(-10), End: at(15)}}},
+			wantRented: 15, wantOffline: 45},
+		{name: "connection not closed", usage: Usage{Identified: true,
+			Connections: []Interval{{Start: at(50)}, {Start: at(40), End: at(55)}}},
+			wantIdle: 20, wantOffline: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewUtilization(tt.usage, from, at(60))
+			if got.RentedMinutes != tt.wantRented {
+				t.Errorf("RentedMinutes = %v, want %v", got.RentedMinutes, tt.wantRented)
+			}
+			if got.IdleMinutes == nil || *got.IdleMinutes != tt.wantIdle {
+				t.Errorf("IdleMinutes = %v, want %v", got.IdleMinutes, tt.wantIdle)
+			}
+			if got.OfflineMinutes == nil || *got.OfflineMinutes != tt.wantOffline {
+				t.Errorf("OfflineMinutes = %v, want %v", got.OfflineMinutes, tt.wantOffline)
+			}
+		})
+	}
+}
+
+func TestNewUtilizationOfUnidentifiedScooter(t *testing.T) {
+	got := NewUtilization(Usage{Rentals: []Interval{{Start: at(0), End: at(30)}}}, from, at(60))
+	if got.RentedMinutes != 30 {
+		t.Errorf("RentedMinutes = %v, want 30", got.RentedMinutes)
+	}
+	if got.IdleMinutes != nil || got.OfflineMinutes != nil {
+		t.Errorf("connectivity of the unidentified scooter is reported: %v, %v", got.IdleMinutes, got.OfflineMinutes)
+	}
+}
+
+func TestNewHeatmap(t *testing.T) {
+	points := []Point{
+		{Latitude: 0.2, Longitude: 0.3},
+		{Latitude: 0.4, Longitude: 0.1, End: true},
+		{Latitude: 0.7, Longitude: 0.2},
+		{Latitude: 0.5, Longitude: 0.5, End: true},
+		{Latitude: -0.2, Longitude: -0.3},
+		{Latitude: -0.2, Longitude: 0.3, End: true},
+		{Latitude: -0.5, Longitude: -0.5},
+	}
+
+	heatmap, err := NewHeatmap(points, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Tile{
+		{Latitude: -0.5, Longitude: -0.5, Starts: 2},
+		{Latitude: -0.5, Longitude: 0, Ends: 1},
+		{Latitude: 0, Longitude: 0, Starts: 1, Ends: 1},
+		{Latitude: 0.5, Longitude: 0, Starts: 1},
+		{Latitude: 0.5, Longitude: 0.5, Ends: 1},
+	}
+	if !reflect.DeepEqual(heatmap.Tiles, want) {
+		t.Errorf("tiles = %v, want %v", heatmap.Tiles, want)
+	}
+	if heatmap.CellSize != 0.5 {
+		t.Errorf("CellSize = %v, want 0.5", heatmap.CellSize)
+	}
+}
+
+func TestNewHeatmapWithoutPoints(t *testing.T) {
+	heatmap, err := NewHeatmap(nil, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heatmap.Tiles == nil || len(heatmap.Tiles) != 0 {
+		t.Errorf("tiles = %v, want the empty tiles", heatmap.Tiles)
+	}
+}
+
+func TestNewHeatmapInvalidCellSize(t *testing.T) {
+	for _, cellSize := range []float64{0, -0.5, math.NaN(), math.Inf(1)} {
+		_, err := NewHeatmap([]Point{{Latitude: 1, Longitude: 1}}, cellSize)
+		if !errors.Is(err, ErrInvalidCellSize) {
+			t.Errorf("NewHeatmap with the cell size %v returned %v, want %v", cellSize, err, ErrInvalidCellSize)
+		}
+	}
+}
+
+func TestWriteHeatmapCSV(t *testing.T) {
+	heatmap := Heatmap{CellSize: 0.5, Tiles: []Tile{
+		{Latitude: -0.5, Longitude: 27.5, Starts: 2},
+		{Latitude: 53.5, Longitude: 0, Starts: 1, Ends: 3},
+	}}
+
+	var buf bytes.Buffer
+	err := WriteHeatmapCSV(&buf, heatmap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "latitude,longitude,starts,ends\n-0.5,27.5,2,0\n53.5,0,1,3\n"
+	if buf.String() != want {
+		t.Errorf("WriteHeatmapCSV wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteUtilizationCSV(t *testing.T) {
+	idle, offline := 20.5, 0.0
+	utilization := []Utilization{
+		{ScooterID: 1, RentedMinutes: 10, IdleMinutes: &idle, OfflineMinutes: &offline},
+		{ScooterID: 2, RentedMinutes: 30},
+	}
+
+	var buf bytes.Buffer
+	err := WriteUtilizationCSV(&buf, utilization)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "scooter_id,rented_minutes,idle_minutes,offline_minutes\n1,10,20.5,0\n2,30,,\n"
+	if buf.String() != want {
+		t.Errorf("WriteUtilizationCSV wrote %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"scooter_micro/forecast"
+	"time"
+)
+
+//AnalyticsRepository the interface which implemented by functions which aggregate the trip history.
+type AnalyticsRepository interface {
+	GetHourlyDemand(ctx context.Context, stationID uint64, from, to time.Time) (map[uint64][]forecast.Demand, error)
+}
+
+type AnalyticsRepo struct {
+	db *sql.DB
+}
+
+func NewAnalyticsRepo(db *sql.DB) *AnalyticsRepo {
+	return &AnalyticsRepo{db: db}
+}
+
+//GetHourlyDemand returns the number of the trips started at every station during every hour in [from, to).
+//The trips are the completed and the refunded orders, each one is counted at the station nearest to the place
+//the scooter was rented at. Every selected station is in the result, even the one without trips.
+//Zero stationID selects all the stations.
+func (ar *AnalyticsRepo) GetHourlyDemand(ctx context.Context, stationID uint64, from,
+	to time.Time) (map[uint64][]forecast.Demand, error) {
+	demand := make(map[uint64][]forecast.Demand)
+	querySQL := `SELECT id FROM scooter_stations WHERE $1 = 0 OR id = $1`
+	rows, err := ar.db.QueryContext(ctx, querySQL, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var id uint64
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		demand[id] = []forecast.Demand{}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if stationID != 0 && len(demand) == 0 {
+		return nil, ErrStationNotFound
+	}
+
+	querySQL = `SELECT st.id, date_trunc('hour', sr.date_time) AS hour, COUNT(*)
+					FROM orders AS o
+					JOIN scooter_statuses_in_rent AS sr ON sr.id = o.status_start_id
+					CROSS JOIN LATERAL (SELECT id FROM scooter_stations
+						ORDER BY (latitude - sr.latitude) ^ 2 +
+							((longitude - sr.longitude) * cos(radians(sr.latitude))) ^ 2
+						LIMIT 1) AS st
+					WHERE ` + tripOrdersCondition + ` AND sr.date_time >= $1 AND sr.date_time < $2
+						AND ($3 = 0 OR st.id = $3)
+					GROUP BY st.id, hour
+					ORDER BY st.id, hour`
+	hours, err := ar.db.QueryContext(ctx, querySQL, from.UTC(), to.UTC(), stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := hours.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for hours.Next() {
+		var d forecast.Demand
+		err = hours.Scan(&d.StationID, &d.Hour, &d.Trips)
+		if err != nil {
+			return nil, err
+		}
+		d.Hour = d.Hour.UTC()
+		demand[d.StationID] = append(demand[d.StationID], d)
+	}
+	return demand, hours.Err()
+}
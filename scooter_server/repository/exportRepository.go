@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"scooter_micro/export"
+)
+
+//ExportRepository the interface which implemented by functions which read the state of the fleet for the export.
+type ExportRepository interface {
+	GetScooters(ctx context.Context) ([]export.Scooter, error)
+	GetScooter(ctx context.Context, id uint64) (*export.Scooter, error)
+	GetStationScooters(ctx context.Context, stationID uint64) ([]export.Scooter, error)
+	GetStations(ctx context.Context) ([]export.Station, error)
+	GetStation(ctx context.Context, id uint64) (*export.Station, error)
+}
+
+type ExportRepo struct {
+	db *sql.DB
+}
+
+func NewExportRepo(db *sql.DB) *ExportRepo {
+	return &ExportRepo{db: db}
+}
+
+//selectScooterSQL selects the current state of the scooters. The scooter is online while its connection
+//to the server is open, the scooters out of service can't be rented. The connections are recorded only
+//for the identified scooters, the connectivity of the scooter which has never connected is NULL.
+const selectScooterSQL = `SELECT s.id, sm.model_name, ss.latitude, ss.longitude, ss.battery_remain,
+								ss.can_be_rent AND NOT s.out_of_service, s.out_of_service,
+								CASE WHEN EXISTS(SELECT 1 FROM scooter_connections WHERE scooter_id = s.id)
+									THEN EXISTS(SELECT 1 FROM scooter_connections
+										WHERE scooter_id = s.id AND disconnected_at IS NULL) END,
+								COALESCE(ss.station_id, 0)
+							FROM scooters AS s
+							JOIN scooter_models AS sm ON sm.id = s.model_id
+							JOIN scooter_statuses AS ss ON ss.scooter_id = s.id`
+
+const selectStationSQL = `SELECT st.id, st.name, st.is_active, st.latitude, st.longitude, COUNT(ss.scooter_id)
+							FROM scooter_stations AS st
+							LEFT JOIN scooter_statuses AS ss ON ss.station_id = st.id`
+
+//GetScooters returns the current state of all the scooters.
+func (er *ExportRepo) GetScooters(ctx context.Context) ([]export.Scooter, error) {
+	return er.queryScooters(ctx, selectScooterSQL+` ORDER BY s.id`)
+}
+
+//GetScooter returns the current state of the scooter by its ID.
+func (er *ExportRepo) GetScooter(ctx context.Context, id uint64) (*export.Scooter, error) {
+	scooters, err := er.queryScooters(ctx, selectScooterSQL+` WHERE s.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(scooters) == 0 {
+		return nil, ErrScooterNotFound
+	}
+	return &scooters[0], nil
+}
+
+//GetStationScooters returns the current state of the scooters parked at the station.
+func (er *ExportRepo) GetStationScooters(ctx context.Context, stationID uint64) ([]export.Scooter, error) {
+	_, err := er.GetStation(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	return er.queryScooters(ctx, selectScooterSQL+` WHERE ss.station_id = $1 ORDER BY s.id`, stationID)
+}
+
+//GetStations returns all the stations with the number of the scooters parked at them.
+func (er *ExportRepo) GetStations(ctx context.Context) ([]export.Station, error) {
+	return er.queryStations(ctx, selectStationSQL+` GROUP BY st.id ORDER BY st.id`)
+}
+
+//GetStation returns the station by its ID with the number of the scooters parked at it.
+func (er *ExportRepo) GetStation(ctx context.Context, id uint64) (*export.Station, error) {
+	stations, err := er.queryStations(ctx, selectStationSQL+` WHERE st.id = $1 GROUP BY st.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(stations) == 0 {
+		return nil, ErrStationNotFound
+	}
+	return &stations[0], nil
+}
+
+func (er *ExportRepo) queryScooters(ctx context.Context, querySQL string, args ...interface{}) ([]export.Scooter,
+	error) {
+	rows, err := er.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	scooters := []export.Scooter{}
+	for rows.Next() {
+		var s export.Scooter
+		var online sql.NullBool
+		err = rows.Scan(&s.ID, &s.Model, &s.Latitude, &s.Longitude, &s.BatteryRemain, &s.CanBeRent, &s.OutOfService,
+			&online, &s.StationID)
+		if err != nil {
+			return nil, err
+		}
+		if online.Valid {
+			s.Online = &online.Bool
+		}
+		scooters = append(scooters, s)
+	}
+	return scooters, rows.Err()
+}
+
+func (er *ExportRepo) queryStations(ctx context.Context, querySQL string, args ...interface{}) ([]export.Station,
+	error) {
+	rows, err := er.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	stations := []export.Station{}
+	for rows.Next() {
+		var s export.Station
+		err = rows.Scan(&s.ID, &s.Name, &s.IsActive, &s.Latitude, &s.Longitude, &s.Scooters)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"scooter_micro/maintenance"
+	"time"
+)
+
+var ErrScooterNotFound = errors.New("scooter not found")
+
+//DamageReport is the damage of the scooter reported by the rider or the operator.
+type DamageReport struct {
+	ID          uint64    `json:"id"`
+	ScooterID   uint64    `json:"scooterId"`
+	ReporterID  uint64    `json:"reporterId"`
+	Severity    string    `json:"severity"`
+	Description string    `json:"description"`
+	TaskID      uint64    `json:"taskId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+//ServiceChange is the record of the scooter taken out of service or returned to it.
+type ServiceChange struct {
+	ID           uint64    `json:"id"`
+	ScooterID    uint64    `json:"scooterId"`
+	OutOfService bool      `json:"outOfService"`
+	Reason       string    `json:"reason"`
+	ActorID      uint64    `json:"actorId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+//ScooterHistory is the maintenance history of the scooter, the newest records first.
+type ScooterHistory struct {
+	ScooterID      uint64           `json:"scooterId"`
+	OutOfService   bool             `json:"outOfService"`
+	Reports        []*DamageReport  `json:"reports"`
+	Tasks          []*Task          `json:"tasks"`
+	ServiceChanges []*ServiceChange `json:"serviceChanges"`
+}
+
+//MaintenanceRepository the interface which implemented by functions which store the damage reports
+//and the service state of the scooters.
+type MaintenanceRepository interface {
+	ReportDamage(ctx context.Context, report *DamageReport, outOfService bool, groundingReports int) (*DamageReport,
+		*Task, error)
+	SetOutOfService(ctx context.Context, scooterID uint64, outOfService bool, reason string, actorID uint64) error
+	GetHistory(ctx context.Context, scooterID uint64) (*ScooterHistory, error)
+}
+
+type MaintenanceRepo struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepo(db *sql.DB) *MaintenanceRepo {
+	return &MaintenanceRepo{db: db}
+}
+
+//ReportDamage saves the report and opens the repair task of the scooter, or raises the priority of the open one.
+//The scooter is taken out of service in the same transaction if outOfService is set or groundingReports
+//different reporters have reported the major damage while the repair task is open.
+func (mr *MaintenanceRepo) ReportDamage(ctx context.Context, report *DamageReport, outOfService bool,
+	groundingReports int) (*DamageReport, *Task, error) {
+	priority, err := maintenance.RepairPriority(report.Severity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	err = lockScooter(ctx, tx, report.ScooterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	task, err := openTask(ctx, tx, &Task{ScooterID: report.ScooterID, Kind: maintenance.KindRepair,
+		Priority: priority})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report.TaskID = task.ID
+	querySQL := `INSERT INTO damage_reports(scooter_id, reporter_id, severity, description, task_id)
+					VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, querySQL, report.ScooterID, report.ReporterID, report.Severity, report.Description,
+		report.TaskID).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reason := fmt.Sprintf("damage report %d", report.ID)
+	if !outOfService && report.Severity == maintenance.SeverityMajor {
+		var reporters int
+		querySQL = `SELECT COUNT(DISTINCT reporter_id) FROM damage_reports WHERE task_id = $1 AND severity = $2`
+		err = tx.QueryRowContext(ctx, querySQL, task.ID, maintenance.SeverityMajor).Scan(&reporters)
+		if err != nil {
+			return nil, nil, err
+		}
+		outOfService = maintenance.Grounds(reporters, groundingReports)
+		reason = fmt.Sprintf("%d major damage reports, the last is %d", reporters, report.ID)
+	}
+
+	if outOfService {
+		_, err = setOutOfService(ctx, tx, report.ScooterID, true, reason, report.ReporterID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return report, task, tx.Commit()
+}
+
+//SetOutOfService takes the scooter out of service or returns it to service and records the change.
+//Setting the current value changes nothing.
+func (mr *MaintenanceRepo) SetOutOfService(ctx context.Context, scooterID uint64, outOfService bool, reason string,
+	actorID uint64) error {
+	tx, err := mr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockScooter(ctx, tx, scooterID)
+	if err != nil {
+		return err
+	}
+
+	_, err = setOutOfService(ctx, tx, scooterID, outOfService, reason, actorID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//GetHistory returns the damage reports, the maintenance tasks and the service changes of the scooter.
+func (mr *MaintenanceRepo) GetHistory(ctx context.Context, scooterID uint64) (*ScooterHistory, error) {
+	history := &ScooterHistory{ScooterID: scooterID, Reports: []*DamageReport{}, ServiceChanges: []*ServiceChange{}}
+	err := mr.db.QueryRowContext(ctx, `SELECT out_of_service FROM scooters WHERE id = $1`,
+		scooterID).Scan(&history.OutOfService)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScooterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history.Tasks, err = queryTasks(ctx, mr.db, selectTaskSQL+` WHERE scooter_id = $1 ORDER BY id DESC`, scooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	querySQL := `SELECT id, scooter_id, reporter_id, severity, description, task_id, created_at
+					FROM damage_reports
+					WHERE scooter_id = $1
+					ORDER BY id DESC`
+	rows, err := mr.db.QueryContext(ctx, querySQL, scooterID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		report := &DamageReport{}
+		var taskID sql.NullInt64
+		err = rows.Scan(&report.ID, &report.ScooterID, &report.ReporterID, &report.Severity, &report.Description,
+			&taskID, &report.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		report.TaskID = uint64(taskID.Int64)
+		history.Reports = append(history.Reports, report)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	querySQL = `SELECT id, scooter_id, out_of_service, reason, actor_id, created_at
+					FROM scooter_service_changes
+					WHERE scooter_id = $1
+					ORDER BY id DESC`
+	changes, err := mr.db.QueryContext(ctx, querySQL, scooterID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := changes.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for changes.Next() {
+		change := &ServiceChange{}
+		var actorID sql.NullInt64
+		err = changes.Scan(&change.ID, &change.ScooterID, &change.OutOfService, &change.Reason, &actorID,
+			&change.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		change.ActorID = uint64(actorID.Int64)
+		history.ServiceChanges = append(history.ServiceChanges, change)
+	}
+	return history, changes.Err()
+}
+
+//lockScooter locks the scooter row, so the service changes of the scooter are made one at a time.
+func lockScooter(ctx context.Context, tx *sql.Tx, scooterID uint64) error {
+	var id uint64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM scooters WHERE id = $1 FOR UPDATE`, scooterID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrScooterNotFound
+	}
+	return err
+}
+
+//setOutOfService changes the service flag of the scooter and records the change. It reports whether the flag
+//has been changed.
+func setOutOfService(ctx context.Context, tx *sql.Tx, scooterID uint64, outOfService bool, reason string,
+	actorID uint64) (bool, error) {
+	result, err := tx.ExecContext(ctx, `UPDATE scooters SET out_of_service = $1 WHERE id = $2 AND out_of_service <> $1`,
+		outOfService, scooterID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	querySQL := `INSERT INTO scooter_service_changes(scooter_id, out_of_service, reason, actor_id)
+					VALUES ($1, $2, $3, NULLIF($4, 0))`
+	_, err = tx.ExecContext(ctx, querySQL, scooterID, outOfService, reason, actorID)
+	return err == nil, err
+}
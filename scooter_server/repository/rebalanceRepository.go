@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"scooter_micro/rebalance"
+	"time"
+)
+
+const foreignKeyViolation = "23503"
+
+var ErrStationNotFound = errors.New("station not found")
+
+//RebalanceRepository the interface which implemented by functions which read the occupancy of the stations
+//and store their target levels.
+type RebalanceRepository interface {
+	GetOccupancy(ctx context.Context) ([]rebalance.Station, error)
+	SetTarget(ctx context.Context, stationID uint64, target int) error
+	ReserveScooter(ctx context.Context, scooterID, stationID uint64, ttl time.Duration) (bool, error)
+	ReleaseScooter(ctx context.Context, scooterID uint64) error
+	ReleaseExpired(ctx context.Context) (map[uint64]ScooterState, error)
+}
+
+type RebalanceRepo struct {
+	db *sql.DB
+}
+
+func NewRebalanceRepo(db *sql.DB) *RebalanceRepo {
+	return &RebalanceRepo{db: db}
+}
+
+//GetOccupancy returns the stations with their targets and the scooters parked at them which can be moved:
+//rentable, in service and without an open trip. Inactive stations have zero target, so their scooters are moved out.
+func (rr *RebalanceRepo) GetOccupancy(ctx context.Context) ([]rebalance.Station, error) {
+	querySQL := `SELECT st.id, st.latitude, st.longitude, st.is_active, t.target
+					FROM scooter_stations AS st
+					LEFT JOIN station_targets AS t ON t.station_id = st.id
+					ORDER BY st.id`
+	rows, err := rr.db.QueryContext(ctx, querySQL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	var stations []rebalance.Station
+	index := make(map[uint64]int)
+	for rows.Next() {
+		var station rebalance.Station
+		var active bool
+		var target sql.NullInt64
+		err = rows.Scan(&station.ID, &station.Latitude, &station.Longitude, &active, &target)
+		if err != nil {
+			return nil, err
+		}
+		station.Target, station.HasTarget = int(target.Int64), target.Valid
+		if !active {
+			station.Target, station.HasTarget = 0, true
+		}
+		index[station.ID] = len(stations)
+		stations = append(stations, station)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	querySQL = `SELECT ss.scooter_id, ss.station_id, ss.battery_remain
+					FROM scooter_statuses AS ss
+					JOIN scooters AS s ON s.id = ss.scooter_id
+					WHERE ss.can_be_rent AND NOT s.out_of_service AND ss.station_id IS NOT NULL
+						AND NOT EXISTS(SELECT 1 FROM trips WHERE scooter_id = ss.scooter_id AND state <> $1)
+					ORDER BY ss.scooter_id`
+	scooters, err := rr.db.QueryContext(ctx, querySQL, TripEnded)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := scooters.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for scooters.Next() {
+		var scooter rebalance.Scooter
+		var stationID uint64
+		err = scooters.Scan(&scooter.ID, &stationID, &scooter.BatteryRemain)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[stationID]; ok {
+			stations[i].Scooters = append(stations[i].Scooters, scooter)
+		}
+	}
+	return stations, scooters.Err()
+}
+
+//SetTarget sets the number of the scooters the station should have.
+func (rr *RebalanceRepo) SetTarget(ctx context.Context, stationID uint64, target int) error {
+	querySQL := `INSERT INTO station_targets(station_id, target) VALUES ($1, $2)
+					ON CONFLICT (station_id) DO UPDATE SET target = EXCLUDED.target, updated_at = now()`
+	_, err := rr.db.ExecContext(ctx, querySQL, stationID, target)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return ErrStationNotFound
+	}
+	return err
+}
+
+//ReserveScooter makes the rentable scooter parked at the station unrentable while it is moved to another station,
+//so it is neither rented nor moved again. It reports false when the scooter isn't rentable at the station anymore.
+//The reservation ends when the scooter reports its status at the new station, or it is released by ReleaseExpired
+//when the scooter is still at the station after the ttl.
+func (rr *RebalanceRepo) ReserveScooter(ctx context.Context, scooterID, stationID uint64,
+	ttl time.Duration) (bool, error) {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	querySQL := `UPDATE scooter_statuses SET can_be_rent = false
+					WHERE scooter_id = $1 AND station_id = $2 AND can_be_rent`
+	result, err := tx.ExecContext(ctx, querySQL, scooterID, stationID)
+	if err != nil {
+		return false, err
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil || reserved != 1 {
+		return false, err
+	}
+
+	querySQL = `INSERT INTO scooter_reservations(scooter_id, station_id, expires_at)
+					VALUES ($1, $2, now() + $3 * interval '1 second')
+					ON CONFLICT (scooter_id) DO UPDATE
+						SET station_id = EXCLUDED.station_id, expires_at = EXCLUDED.expires_at`
+	_, err = tx.ExecContext(ctx, querySQL, scooterID, stationID, ttl.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+//ReleaseScooter ends the reservation of the scooter which wasn't dispatched, it can be rented again
+//if its battery allows it.
+func (rr *RebalanceRepo) ReleaseScooter(ctx context.Context, scooterID uint64) error {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	querySQL := `DELETE FROM scooter_reservations WHERE scooter_id = $1`
+	_, err = tx.ExecContext(ctx, querySQL, scooterID)
+	if err != nil {
+		return err
+	}
+
+	querySQL = `UPDATE scooter_statuses SET can_be_rent = battery_remain > $2 WHERE scooter_id = $1`
+	_, err = tx.ExecContext(ctx, querySQL, scooterID, minRentBattery)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//ReleaseExpired deletes the expired reservations and makes the scooters which are still unrentable at the station
+//they were reserved at rentable again if their battery allows it. The scooters which have reached their new station
+//are not changed. It returns the released scooters by their IDs with their states.
+func (rr *RebalanceRepo) ReleaseExpired(ctx context.Context) (map[uint64]ScooterState, error) {
+	querySQL := `WITH expired AS (
+					DELETE FROM scooter_reservations WHERE expires_at < now()
+					RETURNING scooter_id, station_id)
+				UPDATE scooter_statuses AS ss SET can_be_rent = ss.battery_remain > $1
+					FROM expired
+					WHERE ss.scooter_id = expired.scooter_id AND ss.station_id = expired.station_id
+						AND NOT ss.can_be_rent
+					RETURNING ss.scooter_id, ss.can_be_rent, ss.station_id, ss.battery_remain`
+	rows, err := rr.db.QueryContext(ctx, querySQL, minRentBattery)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	released := make(map[uint64]ScooterState)
+	for rows.Next() {
+		var scooterID uint64
+		var state ScooterState
+		err = rows.Scan(&scooterID, &state.CanBeRent, &state.StationID, &state.BatteryRemain)
+		if err != nil {
+			return nil, err
+		}
+		released[scooterID] = state
+	}
+	return released, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"scooter_micro/report"
+	"time"
+)
+
+//tripOrdersCondition selects the orders of the trips which have taken place, the refunded trips included.
+const tripOrdersCondition = `o.state IN ('completed', 'refunded')`
+
+//ReportRepository the interface which implemented by functions which read the trips and the connections
+//of the scooters for the reports.
+type ReportRepository interface {
+	GetTripPoints(ctx context.Context, from, to time.Time) ([]report.Point, error)
+	GetUsage(ctx context.Context, from, to time.Time) ([]report.Usage, error)
+}
+
+type ReportRepo struct {
+	db *sql.DB
+}
+
+func NewReportRepo(db *sql.DB) *ReportRepo {
+	return &ReportRepo{db: db}
+}
+
+//GetTripPoints returns the places where the trips of the orders have started or ended during [from, to).
+func (rr *ReportRepo) GetTripPoints(ctx context.Context, from, to time.Time) ([]report.Point, error) {
+	querySQL := `SELECT sr.latitude, sr.longitude, sr.id = o.status_end_id
+					FROM orders AS o
+					JOIN scooter_statuses_in_rent AS sr ON sr.id IN (o.status_start_id, o.status_end_id)
+					WHERE ` + tripOrdersCondition + ` AND sr.date_time >= $1 AND sr.date_time < $2`
+	rows, err := rr.db.QueryContext(ctx, querySQL, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	points := []report.Point{}
+	for rows.Next() {
+		var point report.Point
+		err = rows.Scan(&point.Latitude, &point.Longitude, &point.End)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+//GetUsage returns the rentals and the connections of every scooter which overlap [from, to). The rentals are
+//the trips of the orders, the connections are recorded when the identified scooters connect to the server.
+func (rr *ReportRepo) GetUsage(ctx context.Context, from, to time.Time) ([]report.Usage, error) {
+	var usage []report.Usage
+	index := make(map[uint64]int)
+	rows, err := rr.db.QueryContext(ctx, `SELECT s.id, EXISTS(SELECT 1 FROM scooter_connections
+												WHERE scooter_id = s.id AND connected_at < $1)
+											FROM scooters AS s ORDER BY s.id`, to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var scooterID uint64
+		var identified bool
+		err = rows.Scan(&scooterID, &identified)
+		if err != nil {
+			return nil, err
+		}
+		index[scooterID] = len(usage)
+		usage = append(usage, report.Usage{ScooterID: scooterID, Identified: identified})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	querySQL := `SELECT o.scooter_id, s.date_time, e.date_time
+					FROM orders AS o
+					JOIN scooter_statuses_in_rent AS s ON s.id = o.status_start_id
+					LEFT JOIN scooter_statuses_in_rent AS e ON e.id = o.status_end_id
+					WHERE ` + tripOrdersCondition + ` AND s.date_time < $2
+						AND (e.date_time IS NULL OR e.date_time > $1)`
+	err = rr.queryIntervals(ctx, querySQL, from, to, func(scooterID uint64, interval report.Interval) {
+		if i, ok := index[scooterID]; ok {
+			usage[i].Rentals = append(usage[i].Rentals, interval)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	querySQL = `SELECT scooter_id, connected_at, disconnected_at
+					FROM scooter_connections
+					WHERE connected_at < $2 AND (disconnected_at IS NULL OR disconnected_at > $1)`
+	err = rr.queryIntervals(ctx, querySQL, from, to, func(scooterID uint64, interval report.Interval) {
+		if i, ok := index[scooterID]; ok {
+			usage[i].Connections = append(usage[i].Connections, interval)
+		}
+	})
+	return usage, err
+}
+
+//queryIntervals runs the query of the scooter intervals in [from, to) and passes every row to add.
+func (rr *ReportRepo) queryIntervals(ctx context.Context, querySQL string, from, to time.Time,
+	add func(scooterID uint64, interval report.Interval)) error {
+	rows, err := rr.db.QueryContext(ctx, querySQL, from.UTC(), to.UTC())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var scooterID uint64
+		var interval report.Interval
+		var end sql.NullTime
+		err = rows.Scan(&scooterID, &interval.Start, &end)
+		if err != nil {
+			return err
+		}
+		interval.Start = interval.Start.UTC()
+		if end.Valid {
+			interval.End = end.Time.UTC()
+		}
+		add(scooterID, interval)
+	}
+	return rows.Err()
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"log"
@@ -17,9 +18,18 @@ type ScooterRepository interface {
 	GetScooterById(context context.Context, id *proto.ScooterID) (*proto.Scooter, error)
 	GetScooterStatus(context context.Context, id *proto.ScooterID) (*proto.ScooterStatus, error)
 	SendCurrentStatus(context context.Context, status *proto.SendStatus) (*proto.Response, error)
+	UpdateScooterStatus(ctx context.Context, status *proto.SendStatus) (previous, current ScooterState, err error)
 	CreateScooterStatusInRent(context context.Context, id *proto.ScooterID) (*proto.ScooterStatusInRent, error)
 	GetStationById(ctx context.Context,id *proto.StationID) (*proto.Station, error)
 	GetAllStations(ctx context.Context, request *proto.Request) (*proto.StationList, error)
+	SetConnected(ctx context.Context, scooterID uint64, online bool) error
+}
+
+//ScooterState is the part of the scooter status whose changes are published as the fleet events.
+type ScooterState struct {
+	CanBeRent     bool
+	StationID     uint64
+	BatteryRemain float64
 }
 
 type ScooterRepo struct {
@@ -30,11 +40,12 @@ func NewScooterRepo(db *sql.DB) *ScooterRepo {
 	return &ScooterRepo{db: db}
 }
 
-//GetAllScooters - returns a list of all scooters into a database.
+//GetAllScooters - returns a list of all scooters into a database. The scooters out of service can't be rented.
 func (scr *ScooterRepo) GetAllScooters(ctx context.Context, request *proto.Request) (*proto.ScooterList, error) {
 	scooterList := &proto.ScooterList{}
 
-	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain, ss.can_be_rent
+	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain,
+						ss.can_be_rent AND NOT s.out_of_service, s.out_of_service
 					FROM scooters as s 
 					JOIN scooter_models as sm 
 					ON s.model_id=sm.id 
@@ -56,7 +67,7 @@ func (scr *ScooterRepo) GetAllScooters(ctx context.Context, request *proto.Reque
 	for rows.Next() {
 		var scooter proto.Scooter
 		err := rows.Scan(&scooter.Id, &scooter.MaxWeight, &scooter.ScooterModel, &scooter.BatteryRemain,
-			&scooter.CanBeRent)
+			&scooter.CanBeRent, &scooter.OutOfService)
 		if err != nil {
 			fmt.Println(err)
 			return nil, err
@@ -96,10 +107,12 @@ func (scr *ScooterRepo) GetAllStations(ctx context.Context, request *proto.Reque
 }
 
 //GetAllScootersByStationID - returns a list of scooters on the chosen station by its ID.
+//The scooters out of service can't be rented.
 func (scr *ScooterRepo) GetAllScootersByStationID(ctx context.Context, id *proto.StationID) (*proto.ScooterList, error) {
 	scooterList := &proto.ScooterList{}
 
-	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain, ss.can_be_rent
+	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain,
+						ss.can_be_rent AND NOT s.out_of_service, s.out_of_service
 					FROM scooters as s 
 					JOIN scooter_models as sm 
 					ON s.model_id=sm.id 
@@ -121,7 +134,7 @@ func (scr *ScooterRepo) GetAllScootersByStationID(ctx context.Context, id *proto
 	for rows.Next() {
 		var scooter proto.Scooter
 		err := rows.Scan(&scooter.Id, &scooter.MaxWeight, &scooter.ScooterModel, &scooter.BatteryRemain,
-			&scooter.CanBeRent)
+			&scooter.CanBeRent, &scooter.OutOfService)
 		if err != nil {
 			return nil, err
 		}
@@ -130,10 +143,11 @@ func (scr *ScooterRepo) GetAllScootersByStationID(ctx context.Context, id *proto
 	return scooterList, nil
 }
 
-//GetScooterById returns exact scooter by its ID.
+//GetScooterById returns exact scooter by its ID. The scooter out of service can't be rented.
 func (scr *ScooterRepo) GetScooterById(ctx context.Context, id *proto.ScooterID) (*proto.Scooter, error) {
 	scooter := &proto.Scooter{}
-	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain, ss.can_be_rent
+	querySQL := `SELECT s.id, sm.max_weight, sm.model_name, ss.battery_remain,
+						ss.can_be_rent AND NOT s.out_of_service, s.out_of_service
 					FROM scooters as s 
 					JOIN scooter_models as sm 
 					ON s.model_id=sm.id 
@@ -142,7 +156,8 @@ func (scr *ScooterRepo) GetScooterById(ctx context.Context, id *proto.ScooterID)
 					WHERE s.id=$1`
 
 	row := scr.db.QueryRowContext(ctx, querySQL, id.Id)
-	err := row.Scan(&scooter.Id, &scooter.MaxWeight, &scooter.ScooterModel, &scooter.BatteryRemain, &scooter.CanBeRent)
+	err := row.Scan(&scooter.Id, &scooter.MaxWeight, &scooter.ScooterModel, &scooter.BatteryRemain, &scooter.CanBeRent,
+		&scooter.OutOfService)
 	if err != nil {
 		return nil, err
 	}
@@ -218,10 +233,7 @@ func (scr *ScooterRepo) CreateScooterStatusInRent(ctx context.Context,
 
 //SendCurrentStatus updates ScooterStatus with given parameters.
 func (scr *ScooterRepo) SendCurrentStatus(ctx context.Context, status *proto.SendStatus) (*proto.Response, error) {
-	var canBeRent bool
-	if status.BatteryRemain > 10 {
-		canBeRent = true
-	}
+	canBeRent := canBeRent(status.BatteryRemain)
 
 	querySQL := `UPDATE scooter_statuses 
 					SET latitude=$1, longitude=$2, battery_remain=$3, can_be_rent=$4, station_id=$5
@@ -239,4 +251,71 @@ func (scr *ScooterRepo) SendCurrentStatus(ctx context.Context, status *proto.Sen
 	}()
 	fmt.Printf("Current coordinates were written to the Database.\n")
 	return &proto.Response{}, err
-}
\ No newline at end of file
+}
+
+//UpdateScooterStatus updates ScooterStatus like SendCurrentStatus and returns the state of the scooter before
+//and after the update. The status row is locked, so concurrent updates see each other's changes.
+//The unknown scooter is not updated and its states are equal.
+func (scr *ScooterRepo) UpdateScooterStatus(ctx context.Context, status *proto.SendStatus) (previous,
+	current ScooterState, err error) {
+	current = ScooterState{CanBeRent: canBeRent(status.BatteryRemain), StationID: uint64(status.StationID),
+		BatteryRemain: status.BatteryRemain}
+
+	querySQL := `UPDATE scooter_statuses AS ss
+					SET latitude=$1, longitude=$2, battery_remain=$3, can_be_rent=$4, station_id=$5
+					FROM (SELECT scooter_id, can_be_rent, station_id, battery_remain
+							FROM scooter_statuses
+							WHERE scooter_id=$6
+							FOR UPDATE) AS old
+					WHERE ss.scooter_id = old.scooter_id
+					RETURNING old.can_be_rent, COALESCE(old.station_id, 0), old.battery_remain`
+	err = scr.db.QueryRowContext(ctx, querySQL, status.Latitude, status.Longitude, status.BatteryRemain,
+		current.CanBeRent, status.StationID, status.ScooterID).Scan(&previous.CanBeRent, &previous.StationID,
+		&previous.BatteryRemain)
+	if errors.Is(err, sql.ErrNoRows) {
+		return current, current, nil
+	}
+	return previous, current, err
+}
+
+//SetScooterPosition saves the position and the battery charge reported by the scooter during the trip,
+//the station and the rent availability of the scooter are not changed.
+func (scr *ScooterRepo) SetScooterPosition(ctx context.Context, scooterID uint64, latitude, longitude,
+	batteryRemain float64) error {
+	querySQL := `UPDATE scooter_statuses SET latitude=$1, longitude=$2, battery_remain=$3 WHERE scooter_id=$4`
+	_, err := scr.db.ExecContext(ctx, querySQL, latitude, longitude, batteryRemain, scooterID)
+	return err
+}
+
+//SetConnected records the connection of the identified scooter to the server or its disconnection. The connection which
+//wasn't closed, for example by the restart of the server, is closed when the scooter connects again.
+func (scr *ScooterRepo) SetConnected(ctx context.Context, scooterID uint64, online bool) error {
+	tx, err := scr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	querySQL := `UPDATE scooter_connections SET disconnected_at = now()
+					WHERE scooter_id = $1 AND disconnected_at IS NULL`
+	_, err = tx.ExecContext(ctx, querySQL, scooterID)
+	if err != nil {
+		return err
+	}
+
+	if online {
+		_, err = tx.ExecContext(ctx, `INSERT INTO scooter_connections(scooter_id) VALUES ($1)`, scooterID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+//minRentBattery is the battery charge the scooter must exceed to be rented.
+const minRentBattery = 10
+
+//canBeRent reports whether the scooter with the battery charge can be rented.
+func canBeRent(batteryRemain float64) bool {
+	return batteryRemain > minRentBattery
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"scooter_micro/maintenance"
+	"time"
+)
+
+var (
+	ErrTaskNotFound = errors.New("maintenance task not found")
+	ErrTaskClosed   = errors.New("maintenance task is already closed")
+)
+
+//Task is the maintenance task of the scooter which is done by the operators.
+type Task struct {
+	ID            uint64     `json:"id"`
+	ScooterID     uint64     `json:"scooterId"`
+	Kind          string     `json:"kind"`
+	State         string     `json:"state"`
+	Priority      int        `json:"priority"`
+	BatteryRemain float64    `json:"batteryRemain"`
+	StationID     uint64     `json:"stationId,omitempty"`
+	AssigneeID    uint64     `json:"assigneeId,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	AssignedAt    *time.Time `json:"assignedAt,omitempty"`
+	ClosedAt      *time.Time `json:"closedAt,omitempty"`
+}
+
+//TaskFilter selects the tasks of the queue, zero fields match all the tasks.
+//Empty State selects the tasks which are not closed.
+type TaskFilter struct {
+	State      string
+	Kind       string
+	StationID  uint64
+	AssigneeID uint64
+}
+
+//TaskRepository the interface which implemented by functions which store the maintenance tasks.
+type TaskRepository interface {
+	OpenTask(ctx context.Context, task *Task) (*Task, error)
+	CloseTasks(ctx context.Context, scooterID uint64, kind string) ([]*Task, error)
+	GetTask(ctx context.Context, id uint64) (*Task, error)
+	ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error)
+	AssignTask(ctx context.Context, id, stationID, assigneeID uint64) (*Task, error)
+	CompleteTask(ctx context.Context, id uint64) (*Task, error)
+	CancelTask(ctx context.Context, id uint64) (*Task, error)
+}
+
+//querier is implemented by both the database and the transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type TaskRepo struct {
+	db *sql.DB
+}
+
+func NewTaskRepo(db *sql.DB) *TaskRepo {
+	return &TaskRepo{db: db}
+}
+
+const selectTaskSQL = `SELECT id, scooter_id, kind, state, priority, battery_remain, station_id, assignee_id,
+							created_at, assigned_at, closed_at
+						FROM maintenance_tasks`
+
+const returningTaskSQL = `RETURNING id, scooter_id, kind, state, priority, battery_remain, station_id,
+							assignee_id, created_at, assigned_at, closed_at`
+
+//OpenTask creates the task of its kind for the scooter. If the scooter already has such a task which isn't closed,
+//that task gets the higher of the priorities and the current battery charge.
+func (tr *TaskRepo) OpenTask(ctx context.Context, task *Task) (*Task, error) {
+	return openTask(ctx, tr.db, task)
+}
+
+func openTask(ctx context.Context, q querier, task *Task) (*Task, error) {
+	querySQL := `INSERT INTO maintenance_tasks(scooter_id, kind, state, priority, battery_remain, station_id)
+					VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0))
+					ON CONFLICT (scooter_id, kind) WHERE state IN ('open', 'assigned')
+					DO UPDATE SET priority = GREATEST(maintenance_tasks.priority, EXCLUDED.priority),
+						battery_remain = EXCLUDED.battery_remain
+					` + returningTaskSQL
+	return scanTask(q.QueryRowContext(ctx, querySQL, task.ScooterID, task.Kind, maintenance.StateOpen,
+		task.Priority, task.BatteryRemain, task.StationID))
+}
+
+//CloseTasks marks the tasks of the kind of the scooter which are not closed as done and returns them.
+func (tr *TaskRepo) CloseTasks(ctx context.Context, scooterID uint64, kind string) ([]*Task, error) {
+	querySQL := `UPDATE maintenance_tasks SET state = $1, closed_at = now()
+					WHERE scooter_id = $2 AND kind = $3 AND state IN ('open', 'assigned')
+					` + returningTaskSQL
+	return queryTasks(ctx, tr.db, querySQL, maintenance.StateDone, scooterID, kind)
+}
+
+//GetTask returns the task by its ID.
+func (tr *TaskRepo) GetTask(ctx context.Context, id uint64) (*Task, error) {
+	task, err := scanTask(tr.db.QueryRowContext(ctx, selectTaskSQL+` WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	return task, err
+}
+
+//ListTasks returns the task queue: the tasks with the highest priority first, then the oldest ones.
+func (tr *TaskRepo) ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	querySQL := selectTaskSQL + `
+					WHERE (($1 = '' AND state IN ('open', 'assigned')) OR state = $1)
+						AND ($2 = '' OR kind = $2)
+						AND ($3 = 0 OR station_id = $3)
+						AND ($4 = 0 OR assignee_id = $4)
+					ORDER BY priority DESC, created_at, id`
+	return queryTasks(ctx, tr.db, querySQL, filter.State, filter.Kind, filter.StationID, filter.AssigneeID)
+}
+
+//AssignTask assigns the task which is not closed to the station and the operator. Zero IDs keep the current values.
+func (tr *TaskRepo) AssignTask(ctx context.Context, id, stationID, assigneeID uint64) (*Task, error) {
+	querySQL := `UPDATE maintenance_tasks
+					SET state = $1, assigned_at = now(),
+						station_id = COALESCE(NULLIF($2, 0), station_id),
+						assignee_id = COALESCE(NULLIF($3, 0), assignee_id)
+					WHERE id = $4 AND state IN ('open', 'assigned')
+					` + returningTaskSQL
+	return changeTask(ctx, tr.db, id, querySQL, maintenance.StateAssigned, stationID, assigneeID, id)
+}
+
+//CompleteTask marks the task which is not closed as done. The scooter is returned to service when its last
+//repair task is done.
+func (tr *TaskRepo) CompleteTask(ctx context.Context, id uint64) (*Task, error) {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	task, err := closeTask(ctx, tx, id, maintenance.StateDone)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Kind == maintenance.KindRepair {
+		var repairing bool
+		querySQL := `SELECT EXISTS(SELECT 1 FROM maintenance_tasks
+						WHERE scooter_id = $1 AND kind = $2 AND state IN ('open', 'assigned'))`
+		err = tx.QueryRowContext(ctx, querySQL, task.ScooterID, maintenance.KindRepair).Scan(&repairing)
+		if err != nil {
+			return nil, err
+		}
+		if !repairing {
+			_, err = setOutOfService(ctx, tx, task.ScooterID, false, fmt.Sprintf("repair task %d is done", task.ID),
+				task.AssigneeID)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return task, tx.Commit()
+}
+
+//CancelTask marks the task which is not closed as cancelled. Cancelling the repair task doesn't return
+//the scooter to service, it is done by the operator.
+func (tr *TaskRepo) CancelTask(ctx context.Context, id uint64) (*Task, error) {
+	return closeTask(ctx, tr.db, id, maintenance.StateCancelled)
+}
+
+//closeTask moves the task which is not closed to the closed state.
+func closeTask(ctx context.Context, q querier, id uint64, state string) (*Task, error) {
+	querySQL := `UPDATE maintenance_tasks SET state = $1, closed_at = now()
+					WHERE id = $2 AND state IN ('open', 'assigned')
+					` + returningTaskSQL
+	return changeTask(ctx, q, id, querySQL, state, id)
+}
+
+//changeTask runs the update of the task which is not closed and tells the missing task from the closed one.
+func changeTask(ctx context.Context, q querier, id uint64, querySQL string, args ...interface{}) (*Task, error) {
+	task, err := scanTask(q.QueryRowContext(ctx, querySQL, args...))
+	if !errors.Is(err, sql.ErrNoRows) {
+		return task, err
+	}
+
+	_, err = scanTask(q.QueryRowContext(ctx, selectTaskSQL+` WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, ErrTaskClosed
+}
+
+func queryTasks(ctx context.Context, q querier, querySQL string, args ...interface{}) ([]*Task, error) {
+	tasks := []*Task{}
+	rows, err := q.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func scanTask(row scanner) (*Task, error) {
+	task := &Task{}
+	var stationID, assigneeID sql.NullInt64
+	var assignedAt, closedAt sql.NullTime
+
+	err := row.Scan(&task.ID, &task.ScooterID, &task.Kind, &task.State, &task.Priority, &task.BatteryRemain,
+		&stationID, &assigneeID, &task.CreatedAt, &assignedAt, &closedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	task.StationID = uint64(stationID.Int64)
+	task.AssigneeID = uint64(assigneeID.Int64)
+	if assignedAt.Valid {
+		task.AssignedAt = &assignedAt.Time
+	}
+	if closedAt.Valid {
+		task.ClosedAt = &closedAt.Time
+	}
+	return task, nil
+}
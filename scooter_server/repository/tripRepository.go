@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TripActive = "active"
+	TripPaused = "paused"
+	TripEnded  = "ended"
+)
+
+var (
+	ErrTripNotFound     = errors.New("trip not found")
+	ErrTripStateChanged = errors.New("trip state has been changed by another request")
+)
+
+//Trip is a rent of the scooter by the rider which can be paused and resumed until it is ended.
+type Trip struct {
+	ID            uint64      `json:"id"`
+	UserID        uint64      `json:"userId"`
+	ScooterID     uint64      `json:"scooterId"`
+	State         string      `json:"state"`
+	StatusStartID uint64      `json:"statusStartId"`
+	StatusEndID   uint64      `json:"statusEndId,omitempty"`
+	OrderID       uint64      `json:"orderId,omitempty"`
+	HoldID        uint64      `json:"holdId,omitempty"`
+	PromoCode     string      `json:"promoCode,omitempty"`
+	StartedAt     time.Time   `json:"startedAt"`
+	EndedAt       *time.Time  `json:"endedAt,omitempty"`
+	SettledAt     *time.Time  `json:"settledAt,omitempty"`
+	Events        []TripEvent `json:"events"`
+}
+
+//TripEvent is a recorded state transition of the trip.
+type TripEvent struct {
+	State     string    `json:"state"`
+	DateTime  time.Time `json:"dateTime"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+}
+
+//TripRepository the interface which implemented by functions which store the trips.
+type TripRepository interface {
+	CreateTrip(ctx context.Context, trip *Trip, event TripEvent) (*Trip, error)
+	GetTrip(ctx context.Context, id uint64) (*Trip, error)
+	ListTrips(ctx context.Context, userID uint64) ([]*Trip, error)
+	HasOpenTrip(ctx context.Context, scooterID uint64) (bool, error)
+	ChangeTripState(ctx context.Context, trip *Trip, from string, event TripEvent) error
+	SettleTrip(ctx context.Context, tripID, orderID uint64) error
+	ListUnsettledTrips(ctx context.Context, limit uint64) ([]uint64, error)
+}
+
+type TripRepo struct {
+	db *sql.DB
+}
+
+func NewTripRepo(db *sql.DB) *TripRepo {
+	return &TripRepo{db: db}
+}
+
+//CreateTrip inserts the trip together with its first event.
+func (tr *TripRepo) CreateTrip(ctx context.Context, trip *Trip, event TripEvent) (*Trip, error) {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	querySQL := `INSERT INTO trips(user_id, scooter_id, state, status_start_id, started_at, hold_id, promo_code,
+					order_id)
+					VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7, NULLIF($8, 0)) RETURNING id`
+	err = tx.QueryRowContext(ctx, querySQL, trip.UserID, trip.ScooterID, event.State, trip.StatusStartID,
+		event.DateTime, trip.HoldID, trip.PromoCode, trip.OrderID).Scan(&trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = insertTripEvent(ctx, tx, trip.ID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	trip.State = event.State
+	trip.StartedAt = event.DateTime
+	trip.Events = append(trip.Events, event)
+	return trip, tx.Commit()
+}
+
+//GetTrip returns the trip with its events by the trip ID.
+func (tr *TripRepo) GetTrip(ctx context.Context, id uint64) (*Trip, error) {
+	querySQL := `SELECT id, user_id, scooter_id, state, status_start_id, status_end_id, order_id, hold_id, promo_code,
+						started_at, ended_at, settled_at
+					FROM trips
+					WHERE id = $1`
+	trip, err := scanTrip(tr.db.QueryRowContext(ctx, querySQL, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTripNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	querySQL = `SELECT state, date_time, latitude, longitude FROM trip_events WHERE trip_id = $1 ORDER BY id`
+	rows, err := tr.db.QueryContext(ctx, querySQL, id)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var event TripEvent
+		err = rows.Scan(&event.State, &event.DateTime, &event.Latitude, &event.Longitude)
+		if err != nil {
+			return nil, err
+		}
+		trip.Events = append(trip.Events, event)
+	}
+	return trip, rows.Err()
+}
+
+//ListTrips returns the trips of the user without their events, the newest first. Zero userID lists all the trips.
+func (tr *TripRepo) ListTrips(ctx context.Context, userID uint64) ([]*Trip, error) {
+	trips := []*Trip{}
+	querySQL := `SELECT id, user_id, scooter_id, state, status_start_id, status_end_id, order_id, hold_id, promo_code,
+						started_at, ended_at, settled_at
+					FROM trips
+					WHERE $1 = 0 OR user_id = $1
+					ORDER BY id DESC`
+	rows, err := tr.db.QueryContext(ctx, querySQL, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		trip, err := scanTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+	return trips, rows.Err()
+}
+
+//HasOpenTrip reports whether the scooter has a trip which is not ended.
+func (tr *TripRepo) HasOpenTrip(ctx context.Context, scooterID uint64) (bool, error) {
+	var exists bool
+	querySQL := `SELECT EXISTS(SELECT 1 FROM trips WHERE scooter_id = $1 AND state <> $2)`
+	err := tr.db.QueryRowContext(ctx, querySQL, scooterID, TripEnded).Scan(&exists)
+	return exists, err
+}
+
+//ChangeTripState moves the trip from the given state to the state of the event and records the event.
+//The ended trip also gets its end status and end time.
+func (tr *TripRepo) ChangeTripState(ctx context.Context, trip *Trip, from string, event TripEvent) error {
+	tx, err := tr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var endedAt sql.NullTime
+	var statusEndID sql.NullInt64
+	if event.State == TripEnded {
+		endedAt = sql.NullTime{Time: event.DateTime, Valid: true}
+		statusEndID = sql.NullInt64{Int64: int64(trip.StatusEndID), Valid: true}
+	}
+
+	querySQL := `UPDATE trips SET state = $1, ended_at = $2, status_end_id = $3 WHERE id = $4 AND state = $5`
+	result, err := tx.ExecContext(ctx, querySQL, event.State, endedAt, statusEndID, trip.ID, from)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrTripStateChanged
+	}
+
+	err = insertTripEvent(ctx, tx, trip.ID, event)
+	if err != nil {
+		return err
+	}
+
+	trip.State = event.State
+	if endedAt.Valid {
+		trip.EndedAt = &endedAt.Time
+	}
+	trip.Events = append(trip.Events, event)
+	return tx.Commit()
+}
+
+//SettleTrip links the ended trip to its order and marks it settled, so the trip isn't billed again.
+func (tr *TripRepo) SettleTrip(ctx context.Context, tripID, orderID uint64) error {
+	querySQL := `UPDATE trips SET order_id = $1, settled_at = now() WHERE id = $2`
+	_, err := tr.db.ExecContext(ctx, querySQL, orderID, tripID)
+	return err
+}
+
+//ListUnsettledTrips returns the IDs of the ended trips which haven't been billed yet, the oldest first.
+func (tr *TripRepo) ListUnsettledTrips(ctx context.Context, limit uint64) ([]uint64, error) {
+	var ids []uint64
+	querySQL := `SELECT id FROM trips WHERE state = $1 AND settled_at IS NULL ORDER BY id LIMIT $2`
+	rows, err := tr.db.QueryContext(ctx, querySQL, TripEnded, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			fmt.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var id uint64
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTrip(row scanner) (*Trip, error) {
+	trip := &Trip{}
+	var statusEndID, orderID, holdID sql.NullInt64
+	var endedAt, settledAt sql.NullTime
+
+	err := row.Scan(&trip.ID, &trip.UserID, &trip.ScooterID, &trip.State, &trip.StatusStartID, &statusEndID,
+		&orderID, &holdID, &trip.PromoCode, &trip.StartedAt, &endedAt, &settledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	trip.StatusEndID = uint64(statusEndID.Int64)
+	trip.OrderID = uint64(orderID.Int64)
+	trip.HoldID = uint64(holdID.Int64)
+	if endedAt.Valid {
+		trip.EndedAt = &endedAt.Time
+	}
+	if settledAt.Valid {
+		trip.SettledAt = &settledAt.Time
+	}
+	return trip, nil
+}
+
+func insertTripEvent(ctx context.Context, tx *sql.Tx, tripID uint64, event TripEvent) error {
+	querySQL := `INSERT INTO trip_events(trip_id, state, date_time, latitude, longitude) VALUES ($1, $2, $3, $4, $5)`
+	_, err := tx.ExecContext(ctx, querySQL, tripID, event.State, event.DateTime, event.Latitude, event.Longitude)
+	return err
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+const uniqueViolation = "23505"
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrUserExists      = errors.New("user with this email already exists")
+	ErrSessionNotFound = errors.New("session not found or expired")
+)
+
+//User is the registered user of the scooter service.
+type User struct {
+	ID           uint64    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+//UserRepository the interface which implemented by functions which store the users and their sessions.
+type UserRepository interface {
+	CreateUser(ctx context.Context, email, passwordHash string) (*User, error)
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	CreateSession(ctx context.Context, tokenHash string, userID uint64, expiresAt time.Time) error
+	GetSessionUser(ctx context.Context, tokenHash string) (*User, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	SetRole(ctx context.Context, userID uint64, role string) error
+}
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+//CreateUser inserts a new user with the hashed password.
+func (ur *UserRepo) CreateUser(ctx context.Context, email, passwordHash string) (*User, error) {
+	user := &User{Email: email, PasswordHash: passwordHash}
+	querySQL := `INSERT INTO users(email, password_hash) VALUES ($1, $2) RETURNING id, role, created_at`
+	err := ur.db.QueryRowContext(ctx, querySQL, email, passwordHash).Scan(&user.ID, &user.Role, &user.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return nil, ErrUserExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+//GetUserByEmail returns the user by the email.
+func (ur *UserRepo) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	user := &User{}
+	querySQL := `SELECT id, email, password_hash, role, created_at FROM users WHERE email = $1`
+	err := ur.db.QueryRowContext(ctx, querySQL, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role,
+		&user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+//CreateSession stores the session of the user by the hash of its token.
+func (ur *UserRepo) CreateSession(ctx context.Context, tokenHash string, userID uint64, expiresAt time.Time) error {
+	querySQL := `INSERT INTO sessions(token_hash, user_id, expires_at) VALUES ($1, $2, $3)`
+	_, err := ur.db.ExecContext(ctx, querySQL, tokenHash, userID, expiresAt)
+	return err
+}
+
+//GetSessionUser returns the owner of the session which is not expired.
+func (ur *UserRepo) GetSessionUser(ctx context.Context, tokenHash string) (*User, error) {
+	user := &User{}
+	querySQL := `SELECT u.id, u.email, u.password_hash, u.role, u.created_at
+					FROM sessions as s
+					JOIN users as u
+					ON s.user_id=u.id
+					WHERE s.token_hash=$1 AND s.expires_at > now()`
+	err := ur.db.QueryRowContext(ctx, querySQL, tokenHash).Scan(&user.ID, &user.Email, &user.PasswordHash,
+		&user.Role, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+//DeleteSession removes the session, so its token can't be used anymore.
+func (ur *UserRepo) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := ur.db.ExecContext(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+//SetRole changes the role of the user.
+func (ur *UserRepo) SetRole(ctx context.Context, userID uint64, role string) error {
+	result, err := ur.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/forecast"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+	"time"
+)
+
+//defaultPeriod is the period of the history returned without the "from" query parameter.
+const defaultPeriod = 7 * 24 * time.Hour
+
+//maxPeriod is the longest period of the history and the reports, the same as the longest demand series.
+const maxPeriod = forecast.MaxSeriesHours * time.Hour
+
+type analyticsHandler struct {
+	analyticsService *service.AnalyticsService
+}
+
+//RegisterAnalyticsRoutes adds the routes of the station demand and its forecast to the router. They are used
+//by the operators to size the stations.
+func RegisterAnalyticsRoutes(router *mux.Router, analyticsService *service.AnalyticsService) {
+	handler := &analyticsHandler{analyticsService: analyticsService}
+	router.HandleFunc(`/admin/demand`, requireUser(handler.getDemand)).Methods("GET")
+	router.HandleFunc(`/admin/demand/forecast`, requireUser(handler.getForecast)).Methods("GET")
+}
+
+//getDemand writes the hourly demand of the stations selected by the "stationId" query parameter in the period
+//from the "from" to the "to" query parameters.
+func (h *analyticsHandler) getDemand(w http.ResponseWriter, r *http.Request) {
+	stationID, ok := queryStationID(w, r)
+	if !ok {
+		return
+	}
+
+	from, to, ok := queryPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	series, err := h.analyticsService.GetDemand(r.Context(), stationID, from, to)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+//getForecast writes the demand forecast of the stations selected by the "stationId" query parameter
+//for the next "hours", 24 hours by default.
+func (h *analyticsHandler) getForecast(w http.ResponseWriter, r *http.Request) {
+	stationID, ok := queryStationID(w, r)
+	if !ok {
+		return
+	}
+
+	hours := 24
+	if value := r.URL.Query().Get("hours"); value != "" {
+		var err error
+		hours, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	forecasts, err := h.analyticsService.Forecast(r.Context(), stationID, hours)
+	if err != nil {
+		writeAnalyticsError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, forecasts)
+}
+
+//queryPeriod parses the period from the "from" to the "to" RFC 3339 times. The default period is the week
+//ending at the current hour or at the "to" time, the period is at most a year. The error is written to the response.
+func queryPeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	query := r.URL.Query()
+	to := time.Now().UTC().Truncate(time.Hour)
+	var err error
+	if value := query.Get("to"); value != "" {
+		to, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	from := to.Add(-defaultPeriod)
+	if value := query.Get("from"); value != "" {
+		from, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	if !to.After(from) {
+		http.Error(w, "the period must end after it starts", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	if to.Sub(from) > maxPeriod {
+		http.Error(w, fmt.Sprintf("the period can't be longer than %d days", maxPeriod/(24*time.Hour)),
+			http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+//queryStationID parses the optional "stationId" query parameter, zero selects all the stations.
+//The error is written to the response.
+func queryStationID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	value := r.URL.Query().Get("stationId")
+	if value == "" {
+		return 0, true
+	}
+	stationID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return stationID, true
+}
+
+func writeAnalyticsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, forecast.ErrInvalidHours):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrStationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQueryPeriod(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "default week", query: "", want: true},
+		{name: "year", query: "?from=2021-01-10T00:00:00Z&to=2022-01-10T00:00:00Z", want: true},
+		{name: "empty", query: "?from=2022-01-10T00:00:00Z&to=2022-01-10T00:00:00Z"},
+		{name: "longer than a year", query: "?from=2020-01-10T00:00:00Z&to=2022-01-10T00:00:00Z"},
+		{name: "saturated duration", query: "?from=0001-01-01T00:00:00Z&to=9999-12-31T00:00:00Z"},
+		{name: "invalid time", query: "?from=yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			_, _, got := queryPeriod(recorder, httptest.NewRequest(http.MethodGet, "/admin/demand"+tt.query, nil))
+			if got != tt.want {
+				t.Errorf("queryPeriod(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			if !got && recorder.Code != http.StatusBadRequest {
+				t.Errorf("rejected period status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
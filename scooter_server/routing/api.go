@@ -0,0 +1,522 @@
+package routing
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	protobuf "google.golang.org/protobuf/proto"
+	"mime"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/export"
+	"scooter_micro/proto"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+	"strings"
+)
+
+//apiPrefix is the prefix of the versioned REST API routes.
+const apiPrefix = "/api/v1"
+
+//openAPI is the OpenAPI 3 document of the API routes, it is checked against the router by the tests.
+//go:embed openapi.json
+var openAPI []byte
+
+//Media types produced and consumed by the API.
+const (
+	jsonType    = "application/json"
+	geoJSONType = "application/geo+json"
+	csvType     = "text/csv"
+)
+
+//apiError is the error of the API request. The code is the snake case name of the HTTP status,
+//for example "not_found".
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+//apiErrorBody is the body of every API error response.
+type apiErrorBody struct {
+	Error apiError `json:"error"`
+}
+
+//badRequestError is the error of the invalid request parameters or body.
+type badRequestError struct {
+	err error
+}
+
+func (e badRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e badRequestError) Unwrap() error {
+	return e.err
+}
+
+type apiHandler struct {
+	exportService *service.ExportService
+	tripService   *service.TripService
+	order         proto.OrderServiceClient
+}
+
+//RegisterAPIRoutes adds the versioned REST API of the scooters, the stations, the trips and the orders
+//to the router. The API always answers with JSON, the errors have the apiErrorBody form. The scooter and
+//the station lists are also served as GeoJSON and CSV by the Accept header.
+func RegisterAPIRoutes(router *mux.Router, exportService *service.ExportService, tripService *service.TripService,
+	order proto.OrderServiceClient) {
+	handler := &apiHandler{exportService: exportService, tripService: tripService, order: order}
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "route not found")
+	})
+	api.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+	api.Use(apiMiddleware)
+
+	api.HandleFunc(`/openapi.json`, serveOpenAPI).Methods("GET")
+	api.HandleFunc(`/scooters`, requireUser(handler.listScooters)).Methods("GET")
+	api.HandleFunc(`/scooters/{`+scooterIDKey+`}`, requireUser(produceJSON(handler.getScooter))).Methods("GET")
+	api.HandleFunc(`/stations`, requireUser(handler.listStations)).Methods("GET")
+	api.HandleFunc(`/stations/{`+stationIDKey+`}`, requireUser(produceJSON(handler.getStation))).Methods("GET")
+	api.HandleFunc(`/stations/{`+stationIDKey+`}/scooters`, requireUser(handler.listStationScooters)).
+		Methods("GET")
+	api.HandleFunc(`/trips`, requireUser(produceJSON(handler.startTrip))).Methods("POST")
+	api.HandleFunc(`/trips`, requireUser(produceJSON(handler.listTrips))).Methods("GET")
+	api.HandleFunc(`/trips/{`+tripIDKey+`}`, requireUser(produceJSON(handler.getTrip))).Methods("GET")
+	api.HandleFunc(`/trips/{`+tripIDKey+`}/pause`, requireUser(produceJSON(handler.pauseTrip))).Methods("POST")
+	api.HandleFunc(`/trips/{`+tripIDKey+`}/resume`, requireUser(produceJSON(handler.resumeTrip))).Methods("POST")
+	api.HandleFunc(`/trips/{`+tripIDKey+`}/end`, requireUser(produceJSON(handler.endTrip))).Methods("POST")
+	api.HandleFunc(`/orders/{`+orderIDKey+`}`, requireUser(produceJSON(handler.getOrder))).Methods("GET")
+	api.HandleFunc(`/orders/{`+orderIDKey+`}/receipt`, requireUser(produceJSON(handler.getReceipt))).
+		Methods("GET")
+	api.HandleFunc(`/orders/{`+orderIDKey+`}/dispute`, requireUser(produceJSON(handler.disputeOrder))).
+		Methods("POST")
+}
+
+//apiMiddleware rejects the request bodies which are not JSON.
+func apiMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if r.ContentLength != 0 && r.Method != http.MethodGet {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != jsonType {
+				writeAPIError(w, http.StatusUnsupportedMediaType, "request body must be "+jsonType)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+//serveOpenAPI writes the OpenAPI document of the API.
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", jsonType)
+	_, err := w.Write(openAPI)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//produceJSON rejects the requests which don't accept JSON.
+func produceJSON(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := negotiate(w, r, jsonType); ok {
+			next(w, r)
+		}
+	}
+}
+
+func (h *apiHandler) listScooters(w http.ResponseWriter, r *http.Request) {
+	mediaType, ok := negotiate(w, r, jsonType, geoJSONType, csvType)
+	if !ok {
+		return
+	}
+
+	scooters, err := h.exportService.GetScooters(r.Context())
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeScooters(w, mediaType, scooters)
+}
+
+func (h *apiHandler) getScooter(w http.ResponseWriter, r *http.Request) {
+	scooterID, err := routeID(r, scooterIDKey)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	scooter, err := h.exportService.GetScooter(r.Context(), scooterID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scooter)
+}
+
+func (h *apiHandler) listStations(w http.ResponseWriter, r *http.Request) {
+	mediaType, ok := negotiate(w, r, jsonType, geoJSONType, csvType)
+	if !ok {
+		return
+	}
+
+	stations, err := h.exportService.GetStations(r.Context())
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	switch mediaType {
+	case geoJSONType:
+		w.Header().Set("Content-Type", geoJSONType)
+		err = json.NewEncoder(w).Encode(export.StationsGeoJSON(stations))
+	case csvType:
+		w.Header().Set("Content-Type", csvType+"; charset=utf-8")
+		err = export.WriteStationsCSV(w, stations)
+	default:
+		writeJSON(w, http.StatusOK, stations)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func (h *apiHandler) getStation(w http.ResponseWriter, r *http.Request) {
+	stationID, err := routeID(r, stationIDKey)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	station, err := h.exportService.GetStation(r.Context(), stationID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, station)
+}
+
+func (h *apiHandler) listStationScooters(w http.ResponseWriter, r *http.Request) {
+	mediaType, ok := negotiate(w, r, jsonType, geoJSONType, csvType)
+	if !ok {
+		return
+	}
+
+	stationID, err := routeID(r, stationIDKey)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	scooters, err := h.exportService.GetStationScooters(r.Context(), stationID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeScooters(w, mediaType, scooters)
+}
+
+func (h *apiHandler) startTrip(w http.ResponseWriter, r *http.Request) {
+	var request startTripRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeAPIErr(w, badRequestError{err: err})
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	trip, err := h.tripService.StartTrip(r.Context(), user.ID, request.ScooterID, request.PromoCode)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, trip)
+}
+
+//listTrips writes the trips of the rider, the operators see all the trips.
+func (h *apiHandler) listTrips(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	userID := user.ID
+	if user.HasRole(auth.RoleOperator) {
+		userID = 0
+	}
+
+	trips, err := h.tripService.ListTrips(r.Context(), userID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trips)
+}
+
+func (h *apiHandler) getTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.GetTrip)
+}
+
+func (h *apiHandler) pauseTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.PauseTrip)
+}
+
+func (h *apiHandler) resumeTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.ResumeTrip)
+}
+
+func (h *apiHandler) endTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.EndTrip)
+}
+
+//handleTrip checks that the user can access the trip from the route, calls the trip action
+//and writes the resulting trip. The trip of another rider is reported as missing.
+func (h *apiHandler) handleTrip(w http.ResponseWriter, r *http.Request, action tripAction) {
+	tripID, err := routeID(r, tripIDKey)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	trip, err := h.tripService.GetTrip(r.Context(), tripID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	if trip.UserID != user.ID && !user.HasRole(auth.RoleOperator) {
+		writeAPIErr(w, repository.ErrTripNotFound)
+		return
+	}
+
+	trip, err = action(r.Context(), tripID)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trip)
+}
+
+func (h *apiHandler) getOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.ownOrder(w, r)
+	if !ok {
+		return
+	}
+	writeProtoJSON(w, http.StatusOK, order)
+}
+
+//getReceipt writes the JSON receipt of the order.
+func (h *apiHandler) getReceipt(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.ownOrder(w, r)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.order.GetReceipt(r.Context(), &proto.OrderID{Id: order.Id})
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", jsonType)
+	fmt.Fprint(w, receipt.Json)
+}
+
+func (h *apiHandler) disputeOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.ownOrder(w, r)
+	if !ok {
+		return
+	}
+
+	var request orderChangeRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeAPIErr(w, badRequestError{err: err})
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	order, err = h.order.DisputeOrder(r.Context(), &proto.OrderChange{OrderID: order.Id,
+		Actor: fmt.Sprintf("user:%d", user.ID), Reason: request.Reason})
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	writeProtoJSON(w, http.StatusOK, order)
+}
+
+//ownOrder returns the order from the route if the user can access it, otherwise it writes the error.
+//The order of another rider is reported as missing.
+func (h *apiHandler) ownOrder(w http.ResponseWriter, r *http.Request) (*proto.Order, bool) {
+	orderID, err := routeID(r, orderIDKey)
+	if err != nil {
+		writeAPIErr(w, err)
+		return nil, false
+	}
+
+	order, err := h.order.GetOrder(r.Context(), &proto.OrderID{Id: orderID})
+	if err != nil {
+		writeAPIErr(w, err)
+		return nil, false
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	if order.UserID != user.ID && !user.HasRole(auth.RoleOperator) {
+		writeAPIError(w, http.StatusNotFound, "order not found")
+		return nil, false
+	}
+	return order, true
+}
+
+type tripAction func(ctx context.Context, tripID uint64) (*repository.Trip, error)
+
+//routeID parses the ID from the route variable.
+func routeID(r *http.Request, key string) (uint64, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)[key], 10, 64)
+	if err != nil {
+		return 0, badRequestError{err: fmt.Errorf("invalid %s: %w", key, err)}
+	}
+	return id, nil
+}
+
+func writeScooters(w http.ResponseWriter, mediaType string, scooters []export.Scooter) {
+	var err error
+	switch mediaType {
+	case geoJSONType:
+		w.Header().Set("Content-Type", geoJSONType)
+		err = json.NewEncoder(w).Encode(export.ScootersGeoJSON(scooters))
+	case csvType:
+		w.Header().Set("Content-Type", csvType+"; charset=utf-8")
+		err = export.WriteScootersCSV(w, scooters)
+	default:
+		writeJSON(w, http.StatusOK, scooters)
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//writeProtoJSON writes the protobuf message as JSON with all its fields, even the zero ones.
+func writeProtoJSON(w http.ResponseWriter, code int, m protobuf.Message) {
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(m)
+	if err != nil {
+		writeAPIErr(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", jsonType)
+	w.WriteHeader(code)
+	_, err = w.Write(body)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//negotiate returns the offered media type the client accepts best by the Accept header. The first offer is
+//preferred on ties and without the header. The 406 error is written if no offer is acceptable.
+func negotiate(w http.ResponseWriter, r *http.Request, offers ...string) (string, bool) {
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return offers[0], true
+	}
+
+	best, bestQuality := "", 0.0
+	for _, offer := range offers {
+		if quality := acceptQuality(header, offer); quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	if bestQuality == 0 {
+		writeAPIError(w, http.StatusNotAcceptable, "acceptable media types: "+strings.Join(offers, ", "))
+		return "", false
+	}
+	return best, true
+}
+
+//acceptQuality returns the quality of the most specific media range of the Accept header which matches
+//the media type.
+func acceptQuality(header, mediaType string) float64 {
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(header, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		s := -1
+		switch {
+		case mediaRange == mediaType:
+			s = 2
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+			s = 1
+		case mediaRange == "*/*":
+			s = 0
+		}
+		if s > specificity {
+			quality, specificity = q, s
+		}
+	}
+	return quality
+}
+
+//apiErrorStatus returns the HTTP status and the message of the error. The internal errors are not exposed.
+func apiErrorStatus(err error) (int, string) {
+	var badRequest badRequestError
+	var code int
+	message := err.Error()
+	switch {
+	case errors.As(err, &badRequest):
+		code = http.StatusBadRequest
+	case errors.Is(err, repository.ErrScooterNotFound), errors.Is(err, repository.ErrStationNotFound),
+		errors.Is(err, sql.ErrNoRows):
+		code = http.StatusNotFound
+	default:
+		if _, ok := status.FromError(err); ok {
+			code, message = orderErrorStatus(err)
+		} else {
+			code = tripErrorStatus(err)
+		}
+	}
+
+	if code == http.StatusInternalServerError {
+		fmt.Println(err)
+		message = "internal server error"
+	}
+	return code, message
+}
+
+//writeAPIErr writes the error with the status it maps to.
+func writeAPIErr(w http.ResponseWriter, err error) {
+	code, message := apiErrorStatus(err)
+	writeAPIError(w, code, message)
+}
+
+//writeAPIError writes the JSON error body with the status.
+func writeAPIError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, apiErrorBody{Error: apiError{Status: code, Message: message,
+		Code: strings.ReplaceAll(strings.ToLower(http.StatusText(code)), " ", "_")}})
+}
+
+//writeError writes the error as the JSON error body for the API and the gateway routes and as the plain text
+//for the others.
+func writeError(w http.ResponseWriter, r *http.Request, code int, message string) {
+	if strings.HasPrefix(r.URL.Path, apiPrefix+"/") || strings.HasPrefix(r.URL.Path, gatewayPrefix+"/") {
+		writeAPIError(w, code, message)
+		return
+	}
+	http.Error(w, message, code)
+}
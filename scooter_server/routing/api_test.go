@@ -0,0 +1,168 @@
+package routing
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"testing"
+)
+
+func TestAcceptQuality(t *testing.T) {
+	tests := []struct {
+		header    string
+		mediaType string
+		want      float64
+	}{
+		{header: "application/json", mediaType: jsonType, want: 1},
+		{header: "text/csv", mediaType: jsonType, want: 0},
+		{header: "text/csv;q=0.5", mediaType: csvType, want: 0.5},
+		{header: "text/*;q=0.3", mediaType: csvType, want: 0.3},
+		{header: "*/*;q=0.1", mediaType: geoJSONType, want: 0.1},
+		{header: "text/*;q=0.3, text/csv;q=0.7, */*;q=0.1", mediaType: csvType, want: 0.7},
+		{header: "text/*;q=0.3, text/csv;q=0.7, */*;q=0.1", mediaType: jsonType, want: 0.1},
+		{header: "*/*, text/csv;q=0", mediaType: csvType, want: 0},
+		{header: "text/csv;q=high, application/json", mediaType: csvType, want: 0},
+		{header: "invalid, application/json", mediaType: jsonType, want: 1},
+		{header: "application/*", mediaType: geoJSONType, want: 1},
+		{header: "application/*", mediaType: csvType, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := acceptQuality(tt.header, tt.mediaType); got != tt.want {
+			t.Errorf("acceptQuality(%q, %q) = %v, want %v", tt.header, tt.mediaType, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		ok     bool
+	}{
+		{name: "no header", accept: "", want: jsonType, ok: true},
+		{name: "any", accept: "*/*", want: jsonType, ok: true},
+		{name: "exact", accept: "text/csv", want: csvType, ok: true},
+		{name: "best quality", accept: "application/json;q=0.4, application/geo+json;q=0.9", want: geoJSONType,
+			ok: true},
+		{name: "first offer on tie", accept: "application/geo+json, application/json", want: jsonType, ok: true},
+		{name: "wildcard subtype", accept: "text/*", want: csvType, ok: true},
+		{name: "specific range excludes", accept: "application/*, application/json;q=0", want: geoJSONType,
+			ok: true},
+		{name: "not acceptable", accept: "text/html", ok: false},
+		{name: "all refused", accept: "*/*;q=0", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, apiPrefix+"/scooters", nil)
+			if tt.accept != "" {
+				request.Header.Set("Accept", tt.accept)
+			}
+
+			got, ok := negotiate(recorder, request, jsonType, geoJSONType, csvType)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("negotiate(%q) = %q, %v, want %q, %v", tt.accept, got, ok, tt.want, tt.ok)
+			}
+			if !ok && recorder.Code != http.StatusNotAcceptable {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusNotAcceptable)
+			}
+			if ok && recorder.Body.Len() != 0 {
+				t.Errorf("negotiate wrote %q for the acceptable type", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestRouteID(t *testing.T) {
+	tests := []struct {
+		value string
+		want  uint64
+		ok    bool
+	}{
+		{value: "42", want: 42, ok: true},
+		{value: "abc"},
+		{value: "-1"},
+		{value: ""},
+		{value: "18446744073709551616"},
+	}
+
+	for _, tt := range tests {
+		request := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": tt.value})
+		got, err := routeID(request, "id")
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("routeID(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+		if err != nil {
+			if code, _ := apiErrorStatus(err); code != http.StatusBadRequest {
+				t.Errorf("status of the invalid ID %q = %d, want %d", tt.value, code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+func TestAPIErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		message string
+	}{
+		{name: "bad request", err: badRequestError{err: errors.New("invalid id")}, code: http.StatusBadRequest,
+			message: "invalid id"},
+		{name: "scooter not found", err: repository.ErrScooterNotFound, code: http.StatusNotFound},
+		{name: "station not found", err: repository.ErrStationNotFound, code: http.StatusNotFound},
+		{name: "no rows", err: sql.ErrNoRows, code: http.StatusNotFound},
+		{name: "wrapped no rows", err: fmt.Errorf("scooter 5: %w", sql.ErrNoRows), code: http.StatusNotFound},
+		{name: "trip not found", err: repository.ErrTripNotFound, code: http.StatusNotFound},
+		{name: "payment required", err: service.ErrPaymentRequired, code: http.StatusPaymentRequired},
+		{name: "invalid transition", err: service.ErrInvalidTransition, code: http.StatusConflict},
+		{name: "order not found", err: status.Error(codes.NotFound, "order not found"), code: http.StatusNotFound,
+			message: "order not found"},
+		{name: "insufficient funds", err: status.Error(codes.Aborted, "insufficient funds"),
+			code: http.StatusPaymentRequired, message: "insufficient funds"},
+		{name: "order service unavailable", err: status.Error(codes.Unavailable, "connection refused"),
+			code: http.StatusServiceUnavailable, message: "connection refused"},
+		{name: "order internal", err: status.Error(codes.Internal, "pq: deadlock"),
+			code: http.StatusInternalServerError, message: "internal server error"},
+		{name: "internal", err: errors.New("pq: connection reset"), code: http.StatusInternalServerError,
+			message: "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, message := apiErrorStatus(tt.err)
+			if code != tt.code {
+				t.Errorf("status = %d, want %d", code, tt.code)
+			}
+			if tt.message != "" && message != tt.message {
+				t.Errorf("message = %q, want %q", message, tt.message)
+			}
+		})
+	}
+}
+
+func TestWriteAPIErr(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writeAPIErr(recorder, sql.ErrNoRows)
+
+	var body apiErrorBody
+	err := json.NewDecoder(recorder.Body).Decode(&body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != http.StatusNotFound || body.Error.Status != http.StatusNotFound ||
+		body.Error.Code != "not_found" {
+		t.Errorf("writeAPIErr wrote %d %+v, want the not_found error", recorder.Code, body.Error)
+	}
+}
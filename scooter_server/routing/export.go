@@ -0,0 +1,94 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/export"
+	"scooter_micro/service"
+)
+
+type exportHandler struct {
+	exportService *service.ExportService
+}
+
+//RegisterExportRoutes adds the routes which export the scooters and the stations to the router. They are used
+//by the operators to load the fleet into the GIS tools and the spreadsheets.
+func RegisterExportRoutes(router *mux.Router, exportService *service.ExportService) {
+	handler := &exportHandler{exportService: exportService}
+	router.HandleFunc(`/admin/export/scooters`, requireUser(handler.exportScooters)).Methods("GET")
+	router.HandleFunc(`/admin/export/stations`, requireUser(handler.exportStations)).Methods("GET")
+}
+
+//exportScooters writes the scooters in the format from the "format" query parameter: "csv" or "geojson"
+//which is the default.
+func (h *exportHandler) exportScooters(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if !knownExportFormat(w, format) {
+		return
+	}
+
+	scooters, err := h.exportService.GetScooters(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		writeCSVHeader(w, "scooters.csv")
+		err = export.WriteScootersCSV(w, scooters)
+	} else {
+		writeGeoJSONHeader(w, "scooters.geojson")
+		err = json.NewEncoder(w).Encode(export.ScootersGeoJSON(scooters))
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//exportStations writes the stations in the format from the "format" query parameter: "csv" or "geojson"
+//which is the default.
+func (h *exportHandler) exportStations(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if !knownExportFormat(w, format) {
+		return
+	}
+
+	stations, err := h.exportService.GetStations(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		writeCSVHeader(w, "stations.csv")
+		err = export.WriteStationsCSV(w, stations)
+	} else {
+		writeGeoJSONHeader(w, "stations.geojson")
+		err = json.NewEncoder(w).Encode(export.StationsGeoJSON(stations))
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//knownExportFormat writes the error of the unknown format.
+func knownExportFormat(w http.ResponseWriter, format string) bool {
+	switch format {
+	case "", "geojson", "csv":
+		return true
+	}
+	http.Error(w, "unknown export format", http.StatusBadRequest)
+	return false
+}
+
+func writeCSVHeader(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+}
+
+func writeGeoJSONHeader(w http.ResponseWriter, filename string) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+}
@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/gateway"
+	"scooter_micro/routing/grpcserver"
+)
+
+//gatewayPrefix is the prefix of the routes of the gRPC methods.
+const gatewayPrefix = "/gateway"
+
+const orderServiceMethod = "/proto.OrderService/"
+
+//orderPolicy lists the roles allowed to call the OrderService methods through the gateway. The gateway doesn't
+//check who owns the orders and the wallets, so only the operators can read and change them. The methods used by
+//the trips, the top-ups, the passes and the promos are left to the admins.
+var orderPolicy = auth.Policy{
+	orderServiceMethod + "GetOrder":     operators,
+	orderServiceMethod + "GetReceipt":   operators,
+	orderServiceMethod + "CancelOrder":  operators,
+	orderServiceMethod + "RefundOrder":  operators,
+	orderServiceMethod + "DisputeOrder": operators,
+	orderServiceMethod + "GetBalance":   operators,
+	orderServiceMethod + "GetPasses":    operators,
+	orderServiceMethod + "GetPassPlans": riders,
+}
+
+//GatewayPolicy lists the roles allowed to call the gRPC methods through the gateway by their full names.
+//The ScooterService methods follow the gRPC policy.
+var GatewayPolicy = mergePolicies(grpcserver.Policy, orderPolicy)
+
+type gatewayHandler struct {
+	method gateway.Method
+}
+
+//RegisterGatewayRoutes serves the unary methods of the services as JSON under the gateway prefix at the routes
+//of their google.api.http options. Every route is named by the full method name, AuthorizeMiddleware checks it
+//by GatewayPolicy.
+func RegisterGatewayRoutes(router *mux.Router, services ...gateway.Service) error {
+	methods, err := gateway.Methods(services...)
+	if err != nil {
+		return err
+	}
+
+	routes := router.PathPrefix(gatewayPrefix).Subrouter()
+	routes.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, "route not found")
+	})
+	routes.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+	routes.Use(apiMiddleware)
+
+	for _, method := range methods {
+		handler := &gatewayHandler{method: method}
+		routes.HandleFunc(method.Rule.Path, requireUser(produceJSON(handler.call))).Methods(method.Rule.Method).
+			Name(method.FullMethod)
+	}
+	return nil
+}
+
+func (h *gatewayHandler) call(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	out, err := h.method.Call(r.Context(), mux.Vars(r), r.URL.Query(), body)
+	if err != nil {
+		writeGatewayError(w, err)
+		return
+	}
+	writeProtoJSON(w, http.StatusOK, out)
+}
+
+//writeGatewayError writes the gRPC status with the HTTP status it maps to, the other errors are written
+//as the API errors.
+func writeGatewayError(w http.ResponseWriter, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		writeAPIErr(w, err)
+		return
+	}
+
+	code, message := gateway.HTTPStatus(st.Code()), st.Message()
+	if code == http.StatusInternalServerError {
+		writeAPIErr(w, err)
+		return
+	}
+	writeAPIError(w, code, message)
+}
+
+//mergePolicies returns the policy with the entries of all the policies, the later ones win.
+func mergePolicies(policies ...auth.Policy) auth.Policy {
+	merged := make(auth.Policy)
+	for _, policy := range policies {
+		for key, roles := range policy {
+			merged[key] = roles
+		}
+	}
+	return merged
+}
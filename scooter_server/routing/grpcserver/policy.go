@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"scooter_micro/auth"
+)
+
+const scooterService = "/proto.ScooterService/"
+
+var (
+	devices = []auth.Role{auth.RoleDevice}
+	readers = []auth.Role{auth.RoleRider, auth.RoleOperator, auth.RoleDevice}
+)
+
+//Policy lists the roles allowed to call every ScooterService method. Only the scooters can push the telemetry,
+//only the operators can send the remote commands and work with the maintenance tasks.
+var Policy = auth.Policy{
+	scooterService + "Register":                  devices,
+	scooterService + "Receive":                   devices,
+	scooterService + "SendCurrentStatus":         devices,
+	scooterService + "CreateScooterStatusInRent": devices,
+	scooterService + "GetAllScooters":            readers,
+	scooterService + "GetAllScootersByStationID": readers,
+	scooterService + "GetScooterById":            readers,
+	scooterService + "GetScooterStatus":          readers,
+	scooterService + "GetStationByID":            readers,
+	scooterService + "GetAllStations":            readers,
+	scooterService + "SendCommand":               {auth.RoleOperator},
+	scooterService + "GetTasks":                  {auth.RoleOperator},
+	scooterService + "AssignTask":                {auth.RoleOperator},
+	scooterService + "CompleteTask":              {auth.RoleOperator},
+	scooterService + "Rebalance":                 {auth.RoleOperator},
+	scooterService + "GetDemandForecast":         {auth.RoleOperator},
+}
@@ -9,8 +9,8 @@ import (
 )
 
 //NewGrpcServer creates a new gRPC server on port 8080.
-func NewGrpcServer() *grpc.Server{
-	grpcServer := grpc.NewServer()
+func NewGrpcServer(opts ...grpc.ServerOption) *grpc.Server{
+	grpcServer := grpc.NewServer(opts...)
 	listener, err := net.Listen("tcp", net.JoinHostPort("", config.GRPC_PORT))
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,42 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"scooter_micro/forecast"
+	"scooter_micro/proto"
+	"scooter_micro/repository"
+)
+
+//GetDemandForecast forecasts the hourly demand of the stations. It is used by the rebalancing and the dashboards
+//to size the stations.
+func (s *Server) GetDemandForecast(ctx context.Context, request *proto.ForecastRequest) (*proto.DemandForecast,
+	error) {
+	if s.analytics == nil {
+		return nil, status.Error(codes.Unimplemented, "demand forecasts are not served")
+	}
+
+	forecasts, err := s.analytics.Forecast(ctx, request.StationID, int(request.Hours))
+	switch {
+	case errors.Is(err, forecast.ErrInvalidHours):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, repository.ErrStationNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result := &proto.DemandForecast{}
+	for _, f := range forecasts {
+		station := &proto.StationForecast{StationID: f.StationID}
+		for _, hour := range f.Hours {
+			station.Hours = append(station.Hours, &proto.HourForecast{Hour: timestamppb.New(hour.Hour),
+				MovingAverage: hour.MovingAverage, Seasonal: hour.Seasonal})
+		}
+		result.Stations = append(result.Stations, station)
+	}
+	return result, nil
+}
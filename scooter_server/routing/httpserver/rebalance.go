@@ -0,0 +1,28 @@
+package httpserver
+
+import (
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"scooter_micro/proto"
+)
+
+//Rebalance plans the moves of the scooters between the stations and dispatches them unless it is a dry run.
+func (s *Server) Rebalance(ctx context.Context, request *proto.RebalanceRequest) (*proto.RebalancePlan, error) {
+	if s.rebalancer == nil {
+		return nil, status.Error(codes.Unimplemented, "rebalancing is not served")
+	}
+
+	plan, err := s.rebalancer.Rebalance(ctx, request.DryRun)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result := &proto.RebalancePlan{TotalDistance: plan.TotalDistance, Dispatched: !request.DryRun}
+	for _, move := range plan.Moves {
+		result.Moves = append(result.Moves, &proto.MoveJob{ScooterID: move.ScooterID,
+			FromStationID: move.FromStationID, ToStationID: move.ToStationID, Distance: move.Distance,
+			Failure: move.Failure})
+	}
+	return result, nil
+}
@@ -4,14 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/gorilla/mux"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log"
 	"net"
 	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/commands"
+	"scooter_micro/gateway"
 	"scooter_micro/proto"
 	"scooter_micro/service"
+	"scooter_micro/telemetry"
+	"strconv"
+	"sync"
 	"time"
 )
 
@@ -21,6 +29,14 @@ const (
 	defaultIdleTimeout     = 30 * time.Second
 	defaultShutdownTimeout = 3 * time.Second
 	defaultAddr            = ":8085"
+	defaultMaxSpeed        = 60
+	defaultTolerance       = 25
+	deliverTimeout         = 5 * time.Second
+)
+
+var (
+	ErrStreamBusy = errors.New("stream of the scooter has too many queued messages")
+	ErrNoStream   = errors.New("no stream has taken the trip")
 )
 
 //Client is a client's struct who connects to the "scooter-run" page.
@@ -40,7 +56,15 @@ type Server struct {
 	codes           map[int]int
 	in              chan *proto.ClientMessage
 	StructureCh     chan *proto.ScooterClient
+	trips           chan *proto.ScooterClient
 	ScooterIdMap    map[uint64]proto.ScooterService_RegisterServer
+	streamMu        sync.Mutex
+	validator       *telemetry.Validator
+	sequencer       *telemetry.Sequencer
+	commands        *commands.Dispatcher
+	tasks           *service.TaskService
+	rebalancer      *service.RebalanceService
+	analytics       *service.AnalyticsService
 	proto.UnimplementedScooterServiceServer
 	ScooterService *service.ScooterService
 }
@@ -67,8 +91,13 @@ func New(handler http.Handler, structure chan *proto.ScooterClient, scooterServi
 		codes:           make(map[int]int),
 		in:              make(chan *proto.ClientMessage),
 		StructureCh:     structure,
+		trips:           make(chan *proto.ScooterClient),
 		ScooterIdMap:    make(map[uint64]proto.ScooterService_RegisterServer),
 		ScooterService:  scooterService,
+		validator: telemetry.NewValidator(telemetry.Limits{MaxSpeed: defaultMaxSpeed,
+			Tolerance: defaultTolerance}),
+		sequencer: telemetry.NewSequencer(),
+		commands:  commands.NewDispatcher(),
 	}
 
 	for _, opt := range opts {
@@ -97,6 +126,41 @@ func Port(port string) Option {
 	}
 }
 
+//Dispatcher sets the dispatcher which queues the remote commands for the scooters.
+func Dispatcher(dispatcher *commands.Dispatcher) Option {
+	return func(s *Server) {
+		s.commands = dispatcher
+	}
+}
+
+//Validator sets the validator which checks the telemetry received from the scooters.
+func Validator(validator *telemetry.Validator) Option {
+	return func(s *Server) {
+		s.validator = validator
+	}
+}
+
+//Tasks sets the service of the maintenance tasks served by gRPC.
+func Tasks(tasks *service.TaskService) Option {
+	return func(s *Server) {
+		s.tasks = tasks
+	}
+}
+
+//Rebalancer sets the service of the rebalancing served by gRPC.
+func Rebalancer(rebalancer *service.RebalanceService) Option {
+	return func(s *Server) {
+		s.rebalancer = rebalancer
+	}
+}
+
+//Analytics sets the service of the demand forecasts served by gRPC.
+func Analytics(analytics *service.AnalyticsService) Option {
+	return func(s *Server) {
+		s.analytics = analytics
+	}
+}
+
 //ScooterHandler is a special handler which adds a new stream client to the server.
 func (s *Server) ScooterHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("new client connected")
@@ -120,36 +184,123 @@ func (s *Server) AddClient(c *Client) {
 	s.client[len(s.client)] = c
 }
 
-func (s *Server) MatchStreamToScooterId(ctx context.Context, stream proto.ScooterService_RegisterServer) {
+//MatchStreamToScooterId binds the stream to the first scooter ID which has no stream yet and returns that ID.
+func (s *Server) MatchStreamToScooterId(ctx context.Context, stream proto.ScooterService_RegisterServer) uint64 {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+
 	for k, v := range s.ScooterIdMap {
 		if v == nil {
 			s.ScooterIdMap[k] = stream
-			break
+			return k
 		}
 	}
+	return 0
+}
+
+//bindStream moves the stream binding from the previous scooter ID to the given one.
+func (s *Server) bindStream(stream proto.ScooterService_RegisterServer, previous, id uint64) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+
+	if previous != 0 && s.ScooterIdMap[previous] == stream {
+		s.ScooterIdMap[previous] = nil
+	}
+	s.ScooterIdMap[id] = stream
+}
+
+//unbindStream releases the scooter ID bound to the closed stream.
+func (s *Server) unbindStream(stream proto.ScooterService_RegisterServer, id uint64) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+
+	if s.ScooterIdMap[id] == stream {
+		s.ScooterIdMap[id] = nil
+	}
+}
+
+//Online reports whether the scooter has a connected stream.
+func (s *Server) Online(id uint64) bool {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	return s.ScooterIdMap[id] != nil
 }
 
 //Register is a function for implementing gRPC-service.
+//Messages are ordered by the sequencer, the duplicated and stale ones and the messages of the scooter which is not
+//bound to the stream or with implausible values are dropped.
+//Command acknowledgements are passed to the dispatcher as the ones of the bound scooter, queued commands are sent
+//after every received message unless they have already timed out.
+//The scooter identified by its certificate is always bound to its own ID and gets only the trips and the commands
+//queued for it. The fleet events report the scooter online while its identified stream is connected.
+//The other streams are bound to an arbitrary free scooter ID and take the trips of the scooters without a stream,
+//they are rebound to the scooter of the taken trip, so they are not reported.
 func (s *Server) Register(stream proto.ScooterService_RegisterServer) error {
-	s.MatchStreamToScooterId(context.Background(), stream)
-	fmt.Println(s.ScooterIdMap)
+	var boundID, identityID uint64
+	if user, ok := auth.UserFromContext(stream.Context()); ok && user.ScooterID != 0 {
+		identityID = user.ScooterID
+		boundID = identityID
+		s.bindStream(stream, 0, boundID)
+	} else {
+		boundID = s.MatchStreamToScooterId(context.Background(), stream)
+	}
+	if identityID != 0 {
+		s.ScooterService.ConnectivityChanged(context.Background(), identityID, true)
+	}
+	defer func() {
+		s.unbindStream(stream, boundID)
+		if identityID != 0 {
+			s.ScooterService.ConnectivityChanged(context.Background(), identityID, false)
+		}
+	}()
 
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
 			fmt.Printf("Error: %v", err)
-			err = status.Errorf(codes.Internal, "unexpected error %v", err)
+			return status.Errorf(codes.Internal, "unexpected error %v", err)
 		}
 
 		fmt.Printf("This is msg:%v before condition\n", msg)
 
-		if msg.Id > 0 {
-			s.in <- msg
+		if msg.Ack != nil {
+			s.commands.Acknowledge(boundID, msg.Ack)
+		} else if msg.Id > 0 {
+			for _, ready := range s.sequencer.Push(msg) {
+				err = s.validator.Validate(boundID, pointFromMessage(ready))
+				if err == nil {
+					s.in <- ready
+				}
+			}
+		}
+
+		var outbox, trips chan *proto.ScooterClient
+		if boundID != 0 {
+			outbox = s.commands.Outbox(boundID)
+		}
+		if identityID == 0 {
+			trips = s.trips
 		}
 
 		select {
-		case data := <-s.StructureCh:
+		case data := <-trips:
 			fmt.Printf("Data has been received : %v", data)
+			if data.Id != boundID {
+				s.bindStream(stream, boundID, data.Id)
+				s.sequencer.Reset(data.Id)
+				s.validator.Forget(data.Id)
+				boundID = data.Id
+			}
+			err = stream.Send(data)
+			if err != nil {
+				log.Printf("send error %v", err)
+			}
+		case data := <-outbox:
+			if data.Command != nil && !s.commands.Pending(data.Command) {
+				log.Printf("command %d for scooter %d isn't pending anymore, dropped", data.Command.GetId(),
+					data.Id)
+				break
+			}
 			err = stream.Send(data)
 			if err != nil {
 				log.Printf("send error %v", err)
@@ -159,6 +310,50 @@ func (s *Server) Register(stream proto.ScooterService_RegisterServer) error {
 	}
 }
 
+//Deliver sends the trip to the stream bound to its scooter. The trip of the scooter without a stream is taken
+//by a stream of the unidentified client, which is rebound to the scooter. It fails when the queue of the bound stream
+//is full or no stream takes the trip until the context is done.
+func (s *Server) Deliver(ctx context.Context, data *proto.ScooterClient) error {
+	if s.Online(data.Id) {
+		select {
+		case s.commands.Outbox(data.Id) <- data:
+			return nil
+		default:
+			return ErrStreamBusy
+		}
+	}
+
+	select {
+	case s.trips <- data:
+		return nil
+	case <-ctx.Done():
+		return ErrNoStream
+	}
+}
+
+//route delivers the trips started by the routes, the trips which no stream has taken are dropped.
+func (s *Server) route() {
+	for data := range s.StructureCh {
+		ctx, cancel := context.WithTimeout(context.Background(), deliverTimeout)
+		err := s.Deliver(ctx, data)
+		cancel()
+		if err != nil {
+			log.Printf("trip of scooter %d wasn't delivered: %v", data.Id, err)
+		}
+	}
+}
+
+//pointFromMessage converts the stream message to the telemetry point.
+//The receive time is used for the clients which don't send the device time.
+func pointFromMessage(msg *proto.ClientMessage) telemetry.Point {
+	point := telemetry.Point{ScooterID: msg.Id, Latitude: msg.Latitude, Longitude: msg.Longitude,
+		BatteryRemain: msg.BatteryRemain, Time: time.Now()}
+	if msg.DeviceTime != nil {
+		point.Time = msg.DeviceTime.AsTime()
+	}
+	return point
+}
+
 //Receive is the function which receive a message from the gRPC stream and direct it to the Server's 'in' channel.
 func (s *Server) Receive(stream proto.ScooterService_ReceiveServer) error {
 	var err error
@@ -180,6 +375,9 @@ func (s *Server) Receive(stream proto.ScooterService_ReceiveServer) error {
 
 //run runs the Server and wait for messages into the channel. Then encode them and print to the console.
 func (s *Server) run() {
+	if s.StructureCh != nil {
+		go s.route()
+	}
 	go func() {
 		for {
 			select {
@@ -208,8 +406,23 @@ func (s *Server) run() {
 	}()
 }
 
-func (s *Server) SendCurrentStatus(ctx context.Context, status *proto.SendStatus) (*proto.Response, error) {
-	return s.ScooterService.Repo.SendCurrentStatus(ctx, status)
+//SendCurrentStatus validates the status and gives the access to the ScooterService.SendCurrentStatus function.
+func (s *Server) SendCurrentStatus(ctx context.Context, sendStatus *proto.SendStatus) (*proto.Response, error) {
+	err := s.validator.ValidatePush(telemetry.Point{ScooterID: sendStatus.ScooterID, Latitude: sendStatus.Latitude,
+		Longitude: sendStatus.Longitude, BatteryRemain: sendStatus.BatteryRemain, Time: time.Now()})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.ScooterService.SendCurrentStatus(ctx, sendStatus)
+}
+
+//TelemetryRejectionsHandler shows the operators how many telemetry messages were rejected and why.
+func (s *Server) TelemetryRejectionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(s.validator.Stats())
+	if err != nil {
+		fmt.Println(err)
+	}
 }
 
 //GetScooterStatus gives the access to the ScooterRepo.GetScooterStatus function.
@@ -221,4 +434,66 @@ func (s *Server) GetScooterStatus(ctx context.Context, status *proto.ScooterID)
 func (s *Server) CreateScooterStatusInRent(ctx context.Context, id *proto.ScooterID) (*proto.ScooterStatusInRent,
 	error) {
 	return s.ScooterService.Repo.CreateScooterStatusInRent(ctx, id)
-}
\ No newline at end of file
+}
+
+//SendCommand sends the remote command to the online scooter and waits for its acknowledgement.
+func (s *Server) SendCommand(ctx context.Context, request *proto.CommandRequest) (*proto.CommandResult, error) {
+	if !s.Online(request.ScooterID) {
+		return nil, status.Errorf(codes.Unavailable, "scooter %d is offline", request.ScooterID)
+	}
+
+	result, err := s.commands.Send(ctx, request.ScooterID, request.Command,
+		time.Duration(request.TimeoutSeconds)*time.Second)
+	switch {
+	case errors.Is(err, commands.ErrUnknownCommand):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, commands.ErrBusy):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, commands.ErrTimeout):
+		return nil, status.Error(codes.DeadlineExceeded, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
+
+type commandRequest struct {
+	Command        string  `json:"command"`
+	Value          float64 `json:"value"`
+	TimeoutSeconds uint32  `json:"timeoutSeconds"`
+}
+
+//CommandHandler is the admin handler which sends the remote command to the scooter and returns the result.
+func (s *Server) CommandHandler(w http.ResponseWriter, r *http.Request) {
+	scooterID, err := strconv.Atoi(mux.Vars(r)["scooterId"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request commandRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	command, err := commands.Parse(request.Command, request.Value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.SendCommand(r.Context(), &proto.CommandRequest{ScooterID: uint64(scooterID), Command: command,
+		TimeoutSeconds: request.TimeoutSeconds})
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), gateway.HTTPStatus(status.Code(err)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,90 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"scooter_micro/auth"
+	"scooter_micro/proto"
+	"scooter_micro/repository"
+	"time"
+)
+
+//GetTasks returns the maintenance task queue selected by the filter.
+func (s *Server) GetTasks(ctx context.Context, filter *proto.TaskFilter) (*proto.TaskList, error) {
+	if s.tasks == nil {
+		return nil, status.Error(codes.Unimplemented, "maintenance tasks are not served")
+	}
+
+	tasks, err := s.tasks.ListTasks(ctx, repository.TaskFilter{State: filter.State, Kind: filter.Kind,
+		StationID: filter.StationID, AssigneeID: filter.AssigneeID})
+	if err != nil {
+		return nil, taskError(err)
+	}
+
+	list := &proto.TaskList{}
+	for _, task := range tasks {
+		list.Tasks = append(list.Tasks, protoTask(task))
+	}
+	return list, nil
+}
+
+//AssignTask assigns the task to the station and the operator. The task without the operator is assigned
+//to the calling operator.
+func (s *Server) AssignTask(ctx context.Context, assignment *proto.TaskAssignment) (*proto.Task, error) {
+	if s.tasks == nil {
+		return nil, status.Error(codes.Unimplemented, "maintenance tasks are not served")
+	}
+
+	assigneeID := assignment.AssigneeID
+	if user, ok := auth.UserFromContext(ctx); ok && assigneeID == 0 {
+		assigneeID = user.ID
+	}
+
+	task, err := s.tasks.AssignTask(ctx, assignment.TaskID, assignment.StationID, assigneeID)
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return protoTask(task), nil
+}
+
+//CompleteTask marks the task as done.
+func (s *Server) CompleteTask(ctx context.Context, id *proto.TaskID) (*proto.Task, error) {
+	if s.tasks == nil {
+		return nil, status.Error(codes.Unimplemented, "maintenance tasks are not served")
+	}
+
+	task, err := s.tasks.CompleteTask(ctx, id.Id)
+	if err != nil {
+		return nil, taskError(err)
+	}
+	return protoTask(task), nil
+}
+
+//taskError converts the errors of the tasks to the gRPC errors.
+func taskError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrTaskClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func protoTask(task *repository.Task) *proto.Task {
+	return &proto.Task{Id: task.ID, ScooterID: task.ScooterID, Kind: task.Kind, State: task.State,
+		Priority: int32(task.Priority), BatteryRemain: task.BatteryRemain, StationID: task.StationID,
+		AssigneeID: task.AssigneeID, CreatedAt: timestamppb.New(task.CreatedAt),
+		AssignedAt: optionalTimestamp(task.AssignedAt), ClosedAt: optionalTimestamp(task.ClosedAt)}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,133 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/maintenance"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+)
+
+type maintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+}
+
+type damageReportRequest struct {
+	Severity     string `json:"severity"`
+	Description  string `json:"description"`
+	OutOfService bool   `json:"outOfService"`
+}
+
+type damageReportResponse struct {
+	Report *repository.DamageReport `json:"report"`
+	Task   *repository.Task         `json:"task"`
+}
+
+type outOfServiceRequest struct {
+	OutOfService bool   `json:"outOfService"`
+	Reason       string `json:"reason"`
+}
+
+//RegisterMaintenanceRoutes adds the routes of the damage reports and the scooter service state to the router.
+//Riders and operators report the damage, operators take the scooters out of service and see their history.
+func RegisterMaintenanceRoutes(router *mux.Router, maintenanceService *service.MaintenanceService) {
+	handler := &maintenanceHandler{maintenanceService: maintenanceService}
+	router.HandleFunc(`/scooters/{`+scooterIDKey+`}/damage-reports`, requireUser(handler.reportDamage)).
+		Methods("POST")
+	router.HandleFunc(`/admin/scooters/{`+scooterIDKey+`}/out-of-service`, requireUser(handler.setOutOfService)).
+		Methods("POST")
+	router.HandleFunc(`/admin/scooters/{`+scooterIDKey+`}/maintenance`, requireUser(handler.getHistory)).
+		Methods("GET")
+}
+
+//reportDamage saves the damage report of the user. Only the operators can take the scooter out of service
+//with their report, the major damage reported by a rider opens the high priority repair task.
+func (h *maintenanceHandler) reportDamage(w http.ResponseWriter, r *http.Request) {
+	scooterID, ok := routeScooterID(w, r)
+	if !ok {
+		return
+	}
+
+	var request damageReportRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	report, task, err := h.maintenanceService.ReportDamage(r.Context(), &repository.DamageReport{ScooterID: scooterID,
+		ReporterID: user.ID, Severity: request.Severity, Description: request.Description},
+		request.OutOfService && user.HasRole(auth.RoleOperator))
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, damageReportResponse{Report: report, Task: task})
+}
+
+func (h *maintenanceHandler) setOutOfService(w http.ResponseWriter, r *http.Request) {
+	scooterID, ok := routeScooterID(w, r)
+	if !ok {
+		return
+	}
+
+	var request outOfServiceRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	err = h.maintenanceService.SetOutOfService(r.Context(), scooterID, request.OutOfService, request.Reason, user.ID)
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+
+	history, err := h.maintenanceService.GetHistory(r.Context(), scooterID)
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (h *maintenanceHandler) getHistory(w http.ResponseWriter, r *http.Request) {
+	scooterID, ok := routeScooterID(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := h.maintenanceService.GetHistory(r.Context(), scooterID)
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func routeScooterID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	scooterID, err := strconv.ParseUint(mux.Vars(r)[scooterIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return scooterID, true
+}
+
+func writeMaintenanceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrScooterNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, maintenance.ErrInvalidSeverity):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,185 @@
+package routing
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths map[string]map[string]struct {
+		Parameters []struct {
+			Name string `json:"name"`
+			In   string `json:"in"`
+		} `json:"parameters"`
+	} `json:"paths"`
+}
+
+var pathVariable = regexp.MustCompile(`{([^}:]+)[^}]*}`)
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	t.Helper()
+	var document openAPIDocument
+	err := json.Unmarshal(openAPI, &document)
+	if err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	return document
+}
+
+//apiOperations returns the "METHOD path" operations served under the API prefix by the router assembled
+//as in cmd/main.go, the paths are relative to the API prefix. The gateway routes are generated from the google.api.http
+//options and tested by TestGatewayRoutes.
+func apiOperations(t *testing.T) map[string]bool {
+	t.Helper()
+	operations := make(map[string]bool)
+	for _, route := range walkRoutes(t, newAssembledRouter(t)) {
+		if !strings.HasPrefix(route.template, apiPrefix+"/") {
+			continue
+		}
+		for _, method := range route.methods {
+			operations[method+" "+strings.TrimPrefix(route.template, apiPrefix)] = true
+		}
+	}
+	return operations
+}
+
+func specOperations(document openAPIDocument) map[string]bool {
+	operations := make(map[string]bool)
+	for path, item := range document.Paths {
+		for method := range item {
+			operations[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	return operations
+}
+
+func TestOpenAPIVersionAndServer(t *testing.T) {
+	document := loadOpenAPI(t)
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", document.OpenAPI)
+	}
+	if len(document.Servers) != 1 || document.Servers[0].URL != apiPrefix {
+		t.Errorf("servers = %v, want the single %q server", document.Servers, apiPrefix)
+	}
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	routes := apiOperations(t)
+	spec := specOperations(loadOpenAPI(t))
+
+	var missing, stale []string
+	for operation := range routes {
+		if !spec[operation] {
+			missing = append(missing, operation)
+		}
+	}
+	for operation := range spec {
+		if !routes[operation] {
+			stale = append(stale, operation)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("routes missing from openapi.json: %v", missing)
+	}
+	if len(stale) > 0 {
+		t.Errorf("openapi.json operations without routes: %v", stale)
+	}
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	for path, item := range loadOpenAPI(t).Paths {
+		var want []string
+		for _, match := range pathVariable.FindAllStringSubmatch(path, -1) {
+			want = append(want, match[1])
+		}
+
+		for method, operation := range item {
+			var got []string
+			for _, parameter := range operation.Parameters {
+				if parameter.In == "path" {
+					got = append(got, parameter.Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("%s %s path parameters = %v, want %v", strings.ToUpper(method), path, got, want)
+			}
+		}
+	}
+}
+
+func TestOpenAPIReferences(t *testing.T) {
+	var document map[string]interface{}
+	err := json.Unmarshal(openAPI, &document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var check func(value interface{})
+	check = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok && resolve(document, ref) == nil {
+				t.Errorf("unresolved reference %q", ref)
+			}
+			for _, child := range v {
+				check(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				check(child)
+			}
+		}
+	}
+	check(document)
+}
+
+func resolve(document map[string]interface{}, ref string) interface{} {
+	var node interface{} = document
+	for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = object[key]
+	}
+	return node
+}
+
+func TestOpenAPIRoutesArePolicied(t *testing.T) {
+	for operation := range apiOperations(t) {
+		path := apiPrefix + operation[strings.Index(operation, " ")+1:]
+		if _, ok := HTTPPolicy[path]; !ok {
+			t.Errorf("route %s is not listed in HTTPPolicy", path)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	router := mux.NewRouter()
+	RegisterAPIRoutes(router, nil, nil, nil)
+	router.Use(AuthorizeMiddleware(HTTPPolicy))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != jsonType {
+		t.Errorf("Content-Type = %q, want %q", contentType, jsonType)
+	}
+	if w.Body.String() != string(openAPI) {
+		t.Error("served document differs from openapi.json")
+	}
+}
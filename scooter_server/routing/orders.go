@@ -0,0 +1,137 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/proto"
+	"strconv"
+)
+
+var orderIDKey = "orderId"
+
+type orderHandler struct {
+	order proto.OrderServiceClient
+}
+
+type orderChangeFunc func(ctx context.Context, in *proto.OrderChange, opts ...grpc.CallOption) (*proto.Order,
+	error)
+
+type orderChangeRequest struct {
+	Reason string `json:"reason"`
+	Amount uint64 `json:"amount"`
+}
+
+//RegisterOrderRoutes adds the routes which show the orders and change their states to the router.
+//Riders can see and dispute their own orders, operators cancel and refund the orders.
+func RegisterOrderRoutes(router *mux.Router, order proto.OrderServiceClient) {
+	handler := &orderHandler{order: order}
+	router.HandleFunc(`/orders/{`+orderIDKey+`}`, requireUser(handler.getOrder)).Methods("GET")
+	router.HandleFunc(`/orders/{`+orderIDKey+`}/receipt`, requireUser(handler.getReceipt)).Methods("GET")
+	router.HandleFunc(`/orders/{`+orderIDKey+`}/dispute`, requireUser(handler.disputeOrder)).Methods("POST")
+	router.HandleFunc(`/admin/orders/{`+orderIDKey+`}/cancel`, requireUser(handler.cancelOrder)).Methods("POST")
+	router.HandleFunc(`/admin/orders/{`+orderIDKey+`}/refund`, requireUser(handler.refundOrder)).Methods("POST")
+}
+
+func (h *orderHandler) getOrder(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.ownOrder(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
+
+//getReceipt writes the receipt of the order in the format from the "format" query parameter:
+//"text", "html" or "json" which is the default.
+func (h *orderHandler) getReceipt(w http.ResponseWriter, r *http.Request) {
+	order, ok := h.ownOrder(w, r)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.order.GetReceipt(r.Context(), &proto.OrderID{Id: order.Id})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, receipt.Text)
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, receipt.Html)
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, receipt.Json)
+	default:
+		http.Error(w, "unknown receipt format", http.StatusBadRequest)
+	}
+}
+
+func (h *orderHandler) disputeOrder(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.ownOrder(w, r); !ok {
+		return
+	}
+	h.changeOrder(w, r, h.order.DisputeOrder)
+}
+
+func (h *orderHandler) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	h.changeOrder(w, r, h.order.CancelOrder)
+}
+
+func (h *orderHandler) refundOrder(w http.ResponseWriter, r *http.Request) {
+	h.changeOrder(w, r, h.order.RefundOrder)
+}
+
+//ownOrder returns the order from the route if the user can access it, otherwise it writes the error.
+func (h *orderHandler) ownOrder(w http.ResponseWriter, r *http.Request) (*proto.Order, bool) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)[orderIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	order, err := h.order.GetOrder(r.Context(), &proto.OrderID{Id: orderID})
+	if err != nil {
+		writeOrderError(w, err)
+		return nil, false
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	if order.UserID != user.ID && !user.HasRole(auth.RoleOperator) {
+		http.Error(w, "access denied", http.StatusForbidden)
+		return nil, false
+	}
+	return order, true
+}
+
+//changeOrder calls the order state change on behalf of the user and writes the changed order.
+func (h *orderHandler) changeOrder(w http.ResponseWriter, r *http.Request, change orderChangeFunc) {
+	orderID, err := strconv.ParseUint(mux.Vars(r)[orderIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request orderChangeRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	order, err := change(r.Context(), &proto.OrderChange{OrderID: orderID, Actor: fmt.Sprintf("user:%d", user.ID),
+		Reason: request.Reason, Amount: request.Amount})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, order)
+}
@@ -0,0 +1,107 @@
+package routing
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/auth"
+)
+
+var (
+	riders    = []auth.Role{auth.RoleRider, auth.RoleOperator}
+	operators = []auth.Role{auth.RoleOperator}
+	admins    = []auth.Role{auth.RoleAdmin}
+)
+
+//HTTPPolicy lists the roles allowed to call every route by its path template, the gateway routes are listed
+//by their names.
+var HTTPPolicy = mergePolicies(auth.Policy{
+	`/register`:                            {auth.Anyone},
+	`/login`:                               {auth.Anyone},
+	`/logout`:                              riders,
+	`/me`:                                  riders,
+	`/scooters`:                            riders,
+	`/scooter`:                             riders,
+	`/scooter/{` + scooterIDKey + `}`:      riders,
+	`/start-trip/{` + stationIDKey + `}`:   riders,
+	`/run`:                                 riders,
+	`/choose-station`:                      riders,
+	`/choose-scooter`:                      riders,
+	`/trips`:                               riders,
+	`/trips/{` + tripIDKey + `}`:           riders,
+	`/trips/{` + tripIDKey + `}/pause`:     riders,
+	`/trips/{` + tripIDKey + `}/resume`:    riders,
+	`/trips/{` + tripIDKey + `}/end`:       riders,
+	`/wallet`:                              riders,
+	`/wallet/top-up`:                       riders,
+	`/passes`:                              riders,
+	`/passes/plans`:                        riders,
+	`/orders/{` + orderIDKey + `}`:         riders,
+	`/orders/{` + orderIDKey + `}/receipt`: riders,
+	`/orders/{` + orderIDKey + `}/dispute`: riders,
+	`/scooters/{` + scooterIDKey + `}/damage-reports`:       riders,
+	`/telemetry/rejections`:                                 operators,
+	`/admin/scooters/{` + scooterIDKey + `}/commands`:       operators,
+	`/admin/users/{` + userIDKey + `}/role`:                 admins,
+	`/admin/promos`:                                         admins,
+	`/admin/orders/{` + orderIDKey + `}/cancel`:             operators,
+	`/admin/orders/{` + orderIDKey + `}/refund`:             operators,
+	`/admin/tasks`:                                          operators,
+	`/admin/tasks/{` + taskIDKey + `}`:                      operators,
+	`/admin/tasks/{` + taskIDKey + `}/assign`:               operators,
+	`/admin/tasks/{` + taskIDKey + `}/complete`:             operators,
+	`/admin/tasks/{` + taskIDKey + `}/cancel`:               operators,
+	`/admin/scooters/{` + scooterIDKey + `}/out-of-service`: operators,
+	`/admin/scooters/{` + scooterIDKey + `}/maintenance`:    operators,
+	`/admin/rebalance`:                                      operators,
+	`/admin/stations/{` + stationIDKey + `}/target`:         operators,
+	`/admin/demand`:                                         operators,
+	`/admin/demand/forecast`:                                operators,
+	`/admin/reports/heatmap`:                                operators,
+	`/admin/reports/utilization`:                            operators,
+	`/admin/export/scooters`:                                operators,
+	`/admin/export/stations`:                                operators,
+	apiPrefix + `/openapi.json`:                             {auth.Anyone},
+	apiPrefix + `/scooters`:                                 riders,
+	apiPrefix + `/scooters/{` + scooterIDKey + `}`:          riders,
+	apiPrefix + `/stations`:                                 riders,
+	apiPrefix + `/stations/{` + stationIDKey + `}`:          riders,
+	apiPrefix + `/stations/{` + stationIDKey + `}/scooters`: riders,
+	apiPrefix + `/trips`:                                    riders,
+	apiPrefix + `/trips/{` + tripIDKey + `}`:                riders,
+	apiPrefix + `/trips/{` + tripIDKey + `}/pause`:          riders,
+	apiPrefix + `/trips/{` + tripIDKey + `}/resume`:         riders,
+	apiPrefix + `/trips/{` + tripIDKey + `}/end`:            riders,
+	apiPrefix + `/orders/{` + orderIDKey + `}`:              riders,
+	apiPrefix + `/orders/{` + orderIDKey + `}/receipt`:      riders,
+	apiPrefix + `/orders/{` + orderIDKey + `}/dispute`:      riders,
+}, GatewayPolicy)
+
+//AuthorizeMiddleware checks that the user injected by AuthMiddleware is allowed to call the matched route.
+//The named routes are checked by their names, the others by their path templates.
+func AuthorizeMiddleware(policy auth.Policy) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var key string
+			if route := mux.CurrentRoute(r); route != nil {
+				key = route.GetName()
+				if key == "" {
+					key, _ = route.GetPathTemplate()
+				}
+			}
+
+			user, ok := auth.UserFromContext(r.Context())
+			if !ok {
+				user = nil
+			}
+			if !policy.Allowed(key, user) {
+				if user == nil {
+					writeError(w, r, http.StatusUnauthorized, auth.ErrUnauthenticated.Error())
+					return
+				}
+				writeError(w, r, http.StatusForbidden, "access denied")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
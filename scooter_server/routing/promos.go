@@ -0,0 +1,61 @@
+package routing
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net/http"
+	"scooter_micro/proto"
+	"time"
+)
+
+type promoHandler struct {
+	order proto.OrderServiceClient
+}
+
+//promo is the JSON form of the promo code, the validity window is open when its times are missing.
+type promo struct {
+	ID             uint64     `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Value          uint64     `json:"value"`
+	FirstRideOnly  bool       `json:"firstRideOnly"`
+	MaxRedemptions uint32     `json:"maxRedemptions"`
+	PerUserLimit   uint32     `json:"perUserLimit"`
+	ValidFrom      *time.Time `json:"validFrom,omitempty"`
+	ValidUntil     *time.Time `json:"validUntil,omitempty"`
+}
+
+//RegisterPromoRoutes adds the routes which manage the promo codes to the router.
+func RegisterPromoRoutes(router *mux.Router, order proto.OrderServiceClient) {
+	handler := &promoHandler{order: order}
+	router.HandleFunc(`/admin/promos`, requireUser(handler.createPromo)).Methods("POST")
+}
+
+func (h *promoHandler) createPromo(w http.ResponseWriter, r *http.Request) {
+	var request promo
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.order.CreatePromo(r.Context(), &proto.Promo{Code: request.Code, Kind: request.Kind,
+		Value: request.Value, FirstRideOnly: request.FirstRideOnly, MaxRedemptions: request.MaxRedemptions,
+		PerUserLimit: request.PerUserLimit, ValidFrom: timestamp(request.ValidFrom),
+		ValidUntil: timestamp(request.ValidUntil)})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+
+	request.ID, request.Code = created.Id, created.Code
+	writeJSON(w, http.StatusCreated, request)
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,74 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+)
+
+type rebalanceHandler struct {
+	rebalanceService *service.RebalanceService
+}
+
+type rebalanceRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+type stationTargetRequest struct {
+	Target int `json:"target"`
+}
+
+//RegisterRebalanceRoutes adds the routes of the fleet rebalancing to the router. They are used by the operators.
+func RegisterRebalanceRoutes(router *mux.Router, rebalanceService *service.RebalanceService) {
+	handler := &rebalanceHandler{rebalanceService: rebalanceService}
+	router.HandleFunc(`/admin/rebalance`, requireUser(handler.rebalance)).Methods("POST")
+	router.HandleFunc(`/admin/stations/{`+stationIDKey+`}/target`, requireUser(handler.setTarget)).Methods("PUT")
+}
+
+//rebalance writes the planned moves, they are dispatched to the scooters unless it is a dry run.
+func (h *rebalanceHandler) rebalance(w http.ResponseWriter, r *http.Request) {
+	var request rebalanceRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.rebalanceService.Rebalance(r.Context(), request.DryRun)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func (h *rebalanceHandler) setTarget(w http.ResponseWriter, r *http.Request) {
+	stationID, err := strconv.ParseUint(mux.Vars(r)[stationIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request stationTargetRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.rebalanceService.SetTarget(r.Context(), stationID, request.Target)
+	switch {
+	case errors.Is(err, service.ErrInvalidTarget):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrStationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
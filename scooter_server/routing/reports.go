@@ -0,0 +1,99 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/report"
+	"scooter_micro/service"
+	"strconv"
+)
+
+type reportHandler struct {
+	reportService *service.ReportService
+}
+
+//RegisterReportRoutes adds the routes of the fleet usage reports to the router. They are used by the operators.
+func RegisterReportRoutes(router *mux.Router, reportService *service.ReportService) {
+	handler := &reportHandler{reportService: reportService}
+	router.HandleFunc(`/admin/reports/heatmap`, requireUser(handler.getHeatmap)).Methods("GET")
+	router.HandleFunc(`/admin/reports/utilization`, requireUser(handler.getUtilization)).Methods("GET")
+}
+
+//getHeatmap writes the heatmap of the trips in the period from the "from" to the "to" query parameters.
+//The "cellSize" query parameter sets the size of the grid cells in degrees. The "format" query parameter is
+//"csv" or "json" which is the default.
+func (h *reportHandler) getHeatmap(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := queryPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	var cellSize float64
+	if value := r.URL.Query().Get("cellSize"); value != "" {
+		var err error
+		cellSize, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	heatmap, err := h.reportService.Heatmap(r.Context(), from, to, cellSize)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		writeCSVHeader(w, "heatmap.csv")
+		err = report.WriteHeatmapCSV(w, heatmap)
+		if err != nil {
+			fmt.Println(err)
+		}
+	case "", "json":
+		writeJSON(w, http.StatusOK, heatmap)
+	default:
+		http.Error(w, "unknown report format", http.StatusBadRequest)
+	}
+}
+
+//getUtilization writes the minutes every scooter was rented, idle and offline in the period from the "from"
+//to the "to" query parameters. The "format" query parameter is "csv" or "json" which is the default.
+//The idle and offline minutes of the scooters which have never connected with their certificates are unknown,
+//they are null in JSON and empty in CSV.
+func (h *reportHandler) getUtilization(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := queryPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	utilization, err := h.reportService.Utilization(r.Context(), from, to)
+	if err != nil {
+		writeReportError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		writeCSVHeader(w, "utilization.csv")
+		err = report.WriteUtilizationCSV(w, utilization)
+		if err != nil {
+			fmt.Println(err)
+		}
+	case "", "json":
+		writeJSON(w, http.StatusOK, utilization)
+	default:
+		http.Error(w, "unknown report format", http.StatusBadRequest)
+	}
+}
+
+func writeReportError(w http.ResponseWriter, err error) {
+	if errors.Is(err, report.ErrInvalidCellSize) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
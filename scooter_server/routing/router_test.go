@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"github.com/gorilla/mux"
+	"scooter_micro/gateway"
+	"scooter_micro/proto"
+	"strings"
+	"testing"
+)
+
+//gatewayServices are the services served by the gateway in cmd/main.go, without the invokers.
+func gatewayServices() []gateway.Service {
+	return []gateway.Service{
+		{Descriptor: proto.File_scooter_micro_proto.Services().ByName("ScooterService")},
+		{Descriptor: proto.File_proto_order_micro_proto.Services().ByName("OrderService")},
+	}
+}
+
+//newAssembledRouter registers the routes the way cmd/main.go does, the services are not needed to walk them.
+//The routes main adds to the server handlers directly are not under the API and the gateway prefixes.
+func newAssembledRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	router := NewRouter(nil, nil)
+	RegisterTripRoutes(router, nil)
+	RegisterWalletRoutes(router, nil)
+	RegisterPromoRoutes(router, nil)
+	RegisterOrderRoutes(router, nil)
+	RegisterTaskRoutes(router, nil)
+	RegisterMaintenanceRoutes(router, nil)
+	RegisterRebalanceRoutes(router, nil)
+	RegisterAnalyticsRoutes(router, nil)
+	RegisterReportRoutes(router, nil)
+	RegisterExportRoutes(router, nil)
+	RegisterAPIRoutes(router, nil, nil, nil)
+	err := RegisterGatewayRoutes(router, gatewayServices()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterUserRoutes(router, nil)
+	return router
+}
+
+//routeOperation is the route of the assembled router with its methods.
+type routeOperation struct {
+	name     string
+	template string
+	methods  []string
+}
+
+func walkRoutes(t *testing.T, router *mux.Router) []routeOperation {
+	t.Helper()
+	var routes []routeOperation
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		routes = append(routes, routeOperation{name: route.GetName(), template: template, methods: methods})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestGatewayRoutes(t *testing.T) {
+	methods, err := gateway.Methods(gatewayServices()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(map[string]bool)
+	for _, method := range methods {
+		served[method.FullMethod] = true
+	}
+	for fullMethod := range orderPolicy {
+		if !served[fullMethod] {
+			t.Errorf("gateway policy of %s has no unary method", fullMethod)
+		}
+	}
+
+	routes := make(map[string]string)
+	for _, route := range walkRoutes(t, newAssembledRouter(t)) {
+		if !strings.HasPrefix(route.template, gatewayPrefix+"/") {
+			continue
+		}
+		//The routes are authorized by their names, the methods missing from GatewayPolicy are left to the admins.
+		if !served[route.name] {
+			t.Errorf("gateway route %s isn't named by the served method: %q", route.template, route.name)
+		}
+		for _, method := range route.methods {
+			operation := method + " " + route.template
+			if previous, ok := routes[operation]; ok {
+				t.Errorf("%s serves both %s and %s", operation, previous, route.name)
+			}
+			routes[operation] = route.name
+		}
+	}
+	if len(routes) != len(methods) {
+		t.Errorf("gateway serves %d routes, want one for each of %d unary methods", len(routes), len(methods))
+	}
+}
@@ -2,13 +2,17 @@ package routing
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
 	"html/template"
 	"net/http"
+	"scooter_micro/auth"
 	"scooter_micro/config"
 	"scooter_micro/proto"
+	"scooter_micro/repository"
 	"scooter_micro/service"
 	"strconv"
 )
@@ -52,7 +56,7 @@ func NewRouter(scooterService *service.ScooterService, structure chan *proto.Sco
 	router.HandleFunc(`/scooters`, handler.getAllScooters).Methods("GET")
 	router.HandleFunc(`/scooter/{`+scooterIDKey+`}`, handler.getScooterById).Methods("GET")
 	router.HandleFunc(`/start-trip/{`+stationIDKey+`}`, handler.showTripPage).Methods("GET")
-	router.HandleFunc(`/run`, handler.startScooterTrip).Methods("GET")
+	router.HandleFunc(`/run`, requireUser(handler.startScooterTrip)).Methods("GET")
 	router.HandleFunc(`/choose-station`, handler.chooseStation).Methods("POST")
 	router.HandleFunc(`/choose-scooter`, handler.chooseScooter).Methods("POST")
 	return router
@@ -64,31 +68,33 @@ func (h *handler) getAllScooters(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(scooters)
 }
 
 func (h *handler) getScooterById(w http.ResponseWriter, r *http.Request) {
 	scooterID, err := strconv.Atoi(mux.Vars(r)[scooterIDKey])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	scooter, err := h.scooterService.GetScooterById(context.Background(), &proto.ScooterID{Id: uint64(scooterID)})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, repository.ErrScooterNotFound.Error(), http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(scooter)
 }
 
 func (h *handler) startScooterTrip(w http.ResponseWriter, r *http.Request) {
-	//userID is a temporary value
-	//userID := 3
-
+	user, _ := auth.UserFromContext(r.Context())
 
 	scooterStatus, err  := h.scooterService.GetScooterStatus(context.Background(), &proto.ScooterID{Id: uint64(chosenScooterID)})
 	if err!=nil {
@@ -103,7 +109,7 @@ func (h *handler) startScooterTrip(w http.ResponseWriter, r *http.Request) {
 		Longitude: scooterStatus.Longitude, BatteryRemain: scooterStatus.BatteryRemain,
 		DestLatitude: station.Latitude, DestLongitude: station.Longitude, StationID: int64(chosenStationID)}
 
-	fmt.Printf("ScooterForClient: %v\n", &scooterForClient)
+	fmt.Printf("ScooterForClient: %v, user: %v\n", &scooterForClient, user.ID)
 
 	h.StructureCh <- &scooterForClient
 	fmt.Println("Data has been sent")
@@ -133,7 +139,7 @@ func (h *handler) startScooterTrip(w http.ResponseWriter, r *http.Request) {
 	//}
 
 	//fmt.Println("StatusEnd created...")
-	//tripInfo := &proto.TripInfo{ScooterID: uint64(chosenScooterID), UserID: uint64(userID),
+	//tripInfo := &proto.TripInfo{ScooterID: uint64(chosenScooterID), UserID: user.ID,
 	//	StatusStartID: statusStart.Id,
 	//	StatusEndID:   statusEnd.Id}
 	//tripOrder, err := h.scooterService.Order.CreateOrder(context.Background(), tripInfo)
@@ -0,0 +1,154 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+)
+
+var taskIDKey = "taskId"
+
+type taskHandler struct {
+	taskService *service.TaskService
+}
+
+type assignTaskRequest struct {
+	StationID  uint64 `json:"stationId"`
+	OperatorID uint64 `json:"operatorId"`
+}
+
+//RegisterTaskRoutes adds the routes of the maintenance task queue to the router. They are used by the operators.
+func RegisterTaskRoutes(router *mux.Router, taskService *service.TaskService) {
+	handler := &taskHandler{taskService: taskService}
+	router.HandleFunc(`/admin/tasks`, requireUser(handler.listTasks)).Methods("GET")
+	router.HandleFunc(`/admin/tasks/{`+taskIDKey+`}`, requireUser(handler.getTask)).Methods("GET")
+	router.HandleFunc(`/admin/tasks/{`+taskIDKey+`}/assign`, requireUser(handler.assignTask)).Methods("POST")
+	router.HandleFunc(`/admin/tasks/{`+taskIDKey+`}/complete`, requireUser(handler.completeTask)).Methods("POST")
+	router.HandleFunc(`/admin/tasks/{`+taskIDKey+`}/cancel`, requireUser(handler.cancelTask)).Methods("POST")
+}
+
+//listTasks writes the task queue filtered by the "state", "kind", "stationId" and "assigneeId" query parameters.
+//"assigneeId=me" selects the tasks of the current operator.
+func (h *taskHandler) listTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.TaskFilter{State: query.Get("state"), Kind: query.Get("kind")}
+
+	var err error
+	if value := query.Get("stationId"); value != "" {
+		filter.StationID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	switch value := query.Get("assigneeId"); value {
+	case "":
+	case "me":
+		user, _ := auth.UserFromContext(r.Context())
+		filter.AssigneeID = user.ID
+	default:
+		filter.AssigneeID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	tasks, err := h.taskService.ListTasks(r.Context(), filter)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (h *taskHandler) getTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := routeTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := h.taskService.GetTask(r.Context(), taskID)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+//assignTask assigns the task to the station and the operator from the request, the task without the operator
+//is taken by the current operator.
+func (h *taskHandler) assignTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := routeTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	var request assignTaskRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.OperatorID == 0 {
+		user, _ := auth.UserFromContext(r.Context())
+		request.OperatorID = user.ID
+	}
+
+	task, err := h.taskService.AssignTask(r.Context(), taskID, request.StationID, request.OperatorID)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+//completeTask marks the task as done, the scooter is returned to service when its last repair task is done.
+func (h *taskHandler) completeTask(w http.ResponseWriter, r *http.Request) {
+	h.closeTask(w, r, h.taskService.CompleteTask)
+}
+
+func (h *taskHandler) cancelTask(w http.ResponseWriter, r *http.Request) {
+	h.closeTask(w, r, h.taskService.CancelTask)
+}
+
+func (h *taskHandler) closeTask(w http.ResponseWriter, r *http.Request,
+	close func(ctx context.Context, id uint64) (*repository.Task, error)) {
+	taskID, ok := routeTaskID(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := close(r.Context(), taskID)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func routeTaskID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	taskID, err := strconv.ParseUint(mux.Vars(r)[taskIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return taskID, true
+}
+
+func writeTaskError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repository.ErrTaskClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
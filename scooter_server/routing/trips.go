@@ -0,0 +1,137 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+)
+
+var tripIDKey = "tripId"
+
+type tripHandler struct {
+	tripService *service.TripService
+}
+
+type startTripRequest struct {
+	ScooterID uint64 `json:"scooterId"`
+	PromoCode string `json:"promoCode"`
+}
+
+//RegisterTripRoutes adds the routes of the rider-driven trips to the router. All of them need the logged-in user.
+//Riders can see and change only their own trips, operators can access all of them.
+func RegisterTripRoutes(router *mux.Router, tripService *service.TripService) {
+	handler := &tripHandler{tripService: tripService}
+	router.HandleFunc(`/trips`, requireUser(handler.startTrip)).Methods("POST")
+	router.HandleFunc(`/trips`, requireUser(handler.listTrips)).Methods("GET")
+	router.HandleFunc(`/trips/{`+tripIDKey+`}`, requireUser(handler.getTrip)).Methods("GET")
+	router.HandleFunc(`/trips/{`+tripIDKey+`}/pause`, requireUser(handler.pauseTrip)).Methods("POST")
+	router.HandleFunc(`/trips/{`+tripIDKey+`}/resume`, requireUser(handler.resumeTrip)).Methods("POST")
+	router.HandleFunc(`/trips/{`+tripIDKey+`}/end`, requireUser(handler.endTrip)).Methods("POST")
+}
+
+func (h *tripHandler) startTrip(w http.ResponseWriter, r *http.Request) {
+	var request startTripRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	trip, err := h.tripService.StartTrip(r.Context(), user.ID, request.ScooterID, request.PromoCode)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, trip)
+}
+
+func (h *tripHandler) listTrips(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	userID := user.ID
+	if user.HasRole(auth.RoleOperator) {
+		userID = 0
+	}
+
+	trips, err := h.tripService.ListTrips(r.Context(), userID)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trips)
+}
+
+func (h *tripHandler) getTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.GetTrip)
+}
+
+func (h *tripHandler) pauseTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.PauseTrip)
+}
+
+func (h *tripHandler) resumeTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.ResumeTrip)
+}
+
+func (h *tripHandler) endTrip(w http.ResponseWriter, r *http.Request) {
+	h.handleTrip(w, r, h.tripService.EndTrip)
+}
+
+//handleTrip checks that the user can access the trip from the route, calls the trip action
+//and writes the resulting trip.
+func (h *tripHandler) handleTrip(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, tripID uint64) (*repository.Trip, error)) {
+	tripID, err := strconv.ParseUint(mux.Vars(r)[tripIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trip, err := h.tripService.GetTrip(r.Context(), tripID)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	if trip.UserID != user.ID && !user.HasRole(auth.RoleOperator) {
+		http.Error(w, "access denied", http.StatusForbidden)
+		return
+	}
+
+	trip, err = action(r.Context(), tripID)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, trip)
+}
+
+func writeTripError(w http.ResponseWriter, err error) {
+	code := tripErrorStatus(err)
+	if code == http.StatusInternalServerError {
+		fmt.Println(err)
+	}
+	http.Error(w, err.Error(), code)
+}
+
+//tripErrorStatus returns the HTTP status of the error of the trip service.
+func tripErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrTripNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPaymentRequired):
+		return http.StatusPaymentRequired
+	case errors.Is(err, service.ErrInvalidTransition), errors.Is(err, service.ErrScooterUnavailable),
+		errors.Is(err, service.ErrNotAllowedLocation), errors.Is(err, repository.ErrTripStateChanged):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,194 @@
+package routing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/repository"
+	"scooter_micro/service"
+	"strconv"
+	"strings"
+)
+
+const sessionCookie = "session"
+
+var userIDKey = "userId"
+
+type userHandler struct {
+	userService *service.UserService
+}
+
+type roleRequest struct {
+	Role auth.Role `json:"role"`
+}
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+//RegisterUserRoutes adds the registration and the session routes to the router.
+func RegisterUserRoutes(router *mux.Router, userService *service.UserService) {
+	handler := &userHandler{userService: userService}
+	router.HandleFunc(`/register`, handler.register).Methods("POST")
+	router.HandleFunc(`/login`, handler.login).Methods("POST")
+	router.HandleFunc(`/logout`, requireUser(handler.logout)).Methods("POST")
+	router.HandleFunc(`/me`, requireUser(handler.me)).Methods("GET")
+	router.HandleFunc(`/admin/users/{`+userIDKey+`}/role`, requireUser(handler.setRole)).Methods("PUT")
+}
+
+//AuthMiddleware injects the user of the session token into the request context.
+//The token is taken from the "Authorization: Bearer" header or from the session cookie.
+//Requests without a token stay anonymous, requests with an invalid token are rejected.
+func AuthMiddleware(userService *service.UserService) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := sessionToken(r)
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := userService.Authenticate(r.Context(), token)
+			if errors.Is(err, repository.ErrSessionNotFound) {
+				writeError(w, r, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+//requireUser rejects the anonymous requests.
+func requireUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.UserFromContext(r.Context()); !ok {
+			writeError(w, r, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func sessionToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func (h *userHandler) register(w http.ResponseWriter, r *http.Request) {
+	var request credentials
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.Register(r.Context(), request.Email, request.Password)
+	switch {
+	case errors.Is(err, service.ErrInvalidEmail), errors.Is(err, service.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrUserExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		fmt.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, user)
+}
+
+func (h *userHandler) login(w http.ResponseWriter, r *http.Request) {
+	var request credentials
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.userService.Login(r.Context(), request.Email, request.Password)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: session.Token, Path: "/",
+		Expires: session.ExpiresAt, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	writeJSON(w, http.StatusOK, session)
+}
+
+func (h *userHandler) logout(w http.ResponseWriter, r *http.Request) {
+	err := h.userService.Logout(r.Context(), sessionToken(r))
+	if err != nil {
+		fmt.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "", Path: "/", MaxAge: -1})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *userHandler) me(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *userHandler) setRole(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)[userIDKey], 10, 64)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request roleRequest
+	err = json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.userService.SetRole(r.Context(), userID, request.Role)
+	switch {
+	case errors.Is(err, service.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, repository.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		fmt.Println(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
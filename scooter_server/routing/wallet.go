@@ -0,0 +1,117 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"scooter_micro/auth"
+	"scooter_micro/gateway"
+	"scooter_micro/proto"
+)
+
+type walletHandler struct {
+	order proto.OrderServiceClient
+}
+
+type topUpRequest struct {
+	Amount uint64 `json:"amount"`
+}
+
+type buyPassRequest struct {
+	Plan string `json:"plan"`
+}
+
+//RegisterWalletRoutes adds the routes of the user's wallet and passes kept by the order service to the router.
+func RegisterWalletRoutes(router *mux.Router, order proto.OrderServiceClient) {
+	handler := &walletHandler{order: order}
+	router.HandleFunc(`/wallet`, requireUser(handler.balance)).Methods("GET")
+	router.HandleFunc(`/wallet/top-up`, requireUser(handler.topUp)).Methods("POST")
+	router.HandleFunc(`/passes/plans`, requireUser(handler.passPlans)).Methods("GET")
+	router.HandleFunc(`/passes`, requireUser(handler.passes)).Methods("GET")
+	router.HandleFunc(`/passes`, requireUser(handler.buyPass)).Methods("POST")
+}
+
+func (h *walletHandler) balance(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	balance, err := h.order.GetBalance(r.Context(), &proto.WalletRequest{UserID: user.ID})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+func (h *walletHandler) topUp(w http.ResponseWriter, r *http.Request) {
+	var request topUpRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	balance, err := h.order.TopUp(r.Context(), &proto.TopUpRequest{UserID: user.ID, Amount: request.Amount})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, balance)
+}
+
+func (h *walletHandler) passPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.order.GetPassPlans(r.Context(), &proto.PassPlansRequest{})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, plans.Plans)
+}
+
+func (h *walletHandler) passes(w http.ResponseWriter, r *http.Request) {
+	user, _ := auth.UserFromContext(r.Context())
+	passes, err := h.order.GetPasses(r.Context(), &proto.WalletRequest{UserID: user.ID})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, passes.Passes)
+}
+
+func (h *walletHandler) buyPass(w http.ResponseWriter, r *http.Request) {
+	var request buyPassRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, _ := auth.UserFromContext(r.Context())
+	pass, err := h.order.BuyPass(r.Context(), &proto.BuyPassRequest{UserID: user.ID, Plan: request.Plan})
+	if err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, pass)
+}
+
+//writeOrderError writes the error returned by the order service.
+func writeOrderError(w http.ResponseWriter, err error) {
+	code, message := orderErrorStatus(err)
+	if code == http.StatusInternalServerError {
+		fmt.Println(err)
+	}
+	http.Error(w, message, code)
+}
+
+//orderErrorStatus returns the HTTP status and the message of the gRPC error of the order service.
+//The order service aborts the payments which the provider declined, they are reported as 402.
+func orderErrorStatus(err error) (int, string) {
+	st := status.Convert(err)
+	if st.Code() == codes.Aborted {
+		return http.StatusPaymentRequired, st.Message()
+	}
+	return gateway.HTTPStatus(st.Code()), st.Message()
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"scooter_micro/forecast"
+	"scooter_micro/repository"
+	"sort"
+	"time"
+)
+
+//AnalyticsService aggregates the trip history into the hourly demand of the stations and forecasts it.
+//It is used to size the stations.
+type AnalyticsService struct {
+	Repo    *repository.AnalyticsRepo
+	History time.Duration
+	Window  int
+}
+
+//NewAnalyticsService creates a new AnalyticsService. The forecasts are made from the history of the given length,
+//the moving average is taken over the latest window hours.
+func NewAnalyticsService(repo *repository.AnalyticsRepo, history time.Duration, window int) *AnalyticsService {
+	return &AnalyticsService{
+		Repo:    repo,
+		History: history,
+		Window:  window,
+	}
+}
+
+//GetDemand returns the hourly demand series of the stations in [from, to), zero stationID selects all the stations.
+func (as *AnalyticsService) GetDemand(ctx context.Context, stationID uint64, from,
+	to time.Time) ([]forecast.Series, error) {
+	demand, err := as.Repo.GetHourlyDemand(ctx, stationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make([]forecast.Series, 0, len(demand))
+	for id, hours := range demand {
+		series = append(series, forecast.NewSeries(id, hours, from, to))
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].StationID < series[j].StationID
+	})
+	return series, nil
+}
+
+//Forecast forecasts the demand of the stations for the next hours from the history ending at the current hour.
+//Zero stationID forecasts all the stations.
+func (as *AnalyticsService) Forecast(ctx context.Context, stationID uint64, hours int) ([]forecast.Forecast, error) {
+	if hours < 1 || hours > forecast.MaxHours {
+		return nil, forecast.ErrInvalidHours
+	}
+
+	to := time.Now().UTC().Truncate(time.Hour)
+	series, err := as.GetDemand(ctx, stationID, to.Add(-as.History), to)
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := make([]forecast.Forecast, 0, len(series))
+	for _, s := range series {
+		f, err := forecast.NewForecast(s, hours, as.Window)
+		if err != nil {
+			return nil, err
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, nil
+}
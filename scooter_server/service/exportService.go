@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"scooter_micro/export"
+	"scooter_micro/repository"
+)
+
+//ExportService exports the current state of the fleet for the GIS tools and the spreadsheets.
+type ExportService struct {
+	Repo *repository.ExportRepo
+}
+
+//NewExportService creates a new ExportService.
+func NewExportService(repo *repository.ExportRepo) *ExportService {
+	return &ExportService{Repo: repo}
+}
+
+//GetScooters returns the current state of all the scooters.
+func (es *ExportService) GetScooters(ctx context.Context) ([]export.Scooter, error) {
+	return es.Repo.GetScooters(ctx)
+}
+
+//GetStations returns all the stations with the number of the scooters parked at them.
+func (es *ExportService) GetStations(ctx context.Context) ([]export.Station, error) {
+	return es.Repo.GetStations(ctx)
+}
+
+//GetScooter returns the current state of the scooter by its ID.
+func (es *ExportService) GetScooter(ctx context.Context, id uint64) (*export.Scooter, error) {
+	return es.Repo.GetScooter(ctx, id)
+}
+
+//GetStationScooters returns the current state of the scooters parked at the station.
+func (es *ExportService) GetStationScooters(ctx context.Context, stationID uint64) ([]export.Scooter, error) {
+	return es.Repo.GetStationScooters(ctx, stationID)
+}
+
+//GetStation returns the station by its ID with the number of the scooters parked at it.
+func (es *ExportService) GetStation(ctx context.Context, id uint64) (*export.Station, error) {
+	return es.Repo.GetStation(ctx, id)
+}
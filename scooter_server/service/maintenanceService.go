@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"scooter_micro/repository"
+)
+
+//MaintenanceService is responsible for the damage reports and the service state of the scooters.
+//The scooter out of service can't be rented until its repair tasks are done or the operator returns it to service.
+//GroundingReports is the number of the riders whose major damage reports take the scooter out of service.
+type MaintenanceService struct {
+	Repo             *repository.MaintenanceRepo
+	GroundingReports int
+}
+
+//NewMaintenanceService creates a new MaintenanceService.
+func NewMaintenanceService(repo *repository.MaintenanceRepo, groundingReports int) *MaintenanceService {
+	return &MaintenanceService{Repo: repo, GroundingReports: groundingReports}
+}
+
+//ReportDamage saves the damage report and opens the repair task, the major damage gets the high priority.
+//The scooter is taken out of service by the operator who asks for it or when enough different riders
+//have reported the major damage, the report of one rider never grounds the scooter.
+func (ms *MaintenanceService) ReportDamage(ctx context.Context, report *repository.DamageReport,
+	outOfService bool) (*repository.DamageReport, *repository.Task, error) {
+	return ms.Repo.ReportDamage(ctx, report, outOfService, ms.GroundingReports)
+}
+
+//SetOutOfService takes the scooter out of service or returns it to service on behalf of the operator.
+func (ms *MaintenanceService) SetOutOfService(ctx context.Context, scooterID uint64, outOfService bool,
+	reason string, operatorID uint64) error {
+	return ms.Repo.SetOutOfService(ctx, scooterID, outOfService, reason, operatorID)
+}
+
+//GetHistory returns the maintenance history of the scooter.
+func (ms *MaintenanceService) GetHistory(ctx context.Context, scooterID uint64) (*repository.ScooterHistory, error) {
+	return ms.Repo.GetHistory(ctx, scooterID)
+}
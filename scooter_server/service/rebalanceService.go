@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"scooter_micro/proto"
+	"scooter_micro/rebalance"
+	"scooter_micro/repository"
+	"time"
+)
+
+var (
+	ErrInvalidTarget   = errors.New("station target can't be negative")
+	ErrScooterOffline  = errors.New("scooter is offline")
+	ErrScooterReserved = errors.New("scooter isn't rentable at the station anymore")
+	ErrNotDelivered    = errors.New("move wasn't delivered")
+)
+
+//dispatchTimeout is how long the move waits for a scooter stream to take it.
+const dispatchTimeout = 5 * time.Second
+
+//Presence reports whether the scooter has a connected stream.
+type Presence interface {
+	Online(scooterID uint64) bool
+}
+
+//Deliverer sends the trip to the stream of the scooter, it fails when no stream has taken the trip.
+type Deliverer interface {
+	Deliver(ctx context.Context, data *proto.ScooterClient) error
+}
+
+//RebalanceService plans the moves of the scooters between the stations and dispatches them as the simulated trips
+//through the Register streams of the scooters. The nil Presence treats every scooter as online.
+//The scooter which doesn't reach its new station in the MoveTimeout is released by RunExpiry.
+type RebalanceService struct {
+	Repo        *repository.RebalanceRepo
+	Scooters    *ScooterService
+	Presence    Presence
+	Streams     Deliverer
+	MoveTimeout time.Duration
+}
+
+//NewRebalanceService creates a new RebalanceService. The moves are delivered by the streams.
+func NewRebalanceService(repo *repository.RebalanceRepo, scooters *ScooterService, streams Deliverer,
+	moveTimeout time.Duration) *RebalanceService {
+	return &RebalanceService{
+		Repo:        repo,
+		Scooters:    scooters,
+		Streams:     streams,
+		MoveTimeout: moveTimeout,
+	}
+}
+
+//Rebalance plans the moves from the current occupancy of the stations. Unless it is a dry run, the moves
+//are dispatched to the scooters, each scooter drives to its new station and reports the status there.
+//The moves of the offline scooters and the moves no stream has taken are not dispatched, their failures
+//are reported in the plan.
+func (rs *RebalanceService) Rebalance(ctx context.Context, dryRun bool) (rebalance.Plan, error) {
+	stations, err := rs.Repo.GetOccupancy(ctx)
+	if err != nil {
+		return rebalance.Plan{}, err
+	}
+
+	plan := rebalance.NewPlan(stations)
+	if dryRun {
+		return plan, nil
+	}
+
+	for i, move := range plan.Moves {
+		err = rs.dispatch(ctx, move)
+		switch {
+		case errors.Is(err, ErrScooterOffline), errors.Is(err, ErrScooterReserved), errors.Is(err, ErrNotDelivered):
+			plan.Moves[i].Failure = err.Error()
+		case err != nil:
+			return plan, fmt.Errorf("scooter %d wasn't dispatched: %w", move.ScooterID, err)
+		}
+	}
+	return plan, nil
+}
+
+//SetTarget sets the number of the scooters the station should have.
+func (rs *RebalanceService) SetTarget(ctx context.Context, stationID uint64, target int) error {
+	if target < 0 {
+		return ErrInvalidTarget
+	}
+	return rs.Repo.SetTarget(ctx, stationID, target)
+}
+
+//ReleaseExpired releases the scooters which haven't reached their new stations in time, so they can be rented again.
+func (rs *RebalanceService) ReleaseExpired(ctx context.Context) (int, error) {
+	released, err := rs.Repo.ReleaseExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for scooterID, current := range released {
+		moving := current
+		moving.CanBeRent = false
+		rs.Scooters.Events.StatusChanged(scooterID, moving, current)
+	}
+	return len(released), nil
+}
+
+//RunExpiry releases the expired reservations every interval until the context is done.
+func (rs *RebalanceService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := rs.ReleaseExpired(ctx)
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//dispatch reserves the scooter and delivers the move to the stream of the scooter. The reservation is released
+//when no stream takes the move in time.
+func (rs *RebalanceService) dispatch(ctx context.Context, move rebalance.Move) error {
+	if rs.Presence != nil && !rs.Presence.Online(move.ScooterID) {
+		return ErrScooterOffline
+	}
+
+	scooterStatus, err := rs.Scooters.GetScooterStatus(ctx, &proto.ScooterID{Id: move.ScooterID})
+	if err != nil {
+		return err
+	}
+	station, err := rs.Scooters.GetStationById(ctx, &proto.StationID{Id: move.ToStationID})
+	if err != nil {
+		return err
+	}
+
+	reserved, err := rs.Repo.ReserveScooter(ctx, move.ScooterID, move.FromStationID, rs.MoveTimeout)
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return ErrScooterReserved
+	}
+	rentable := repository.ScooterState{CanBeRent: true, StationID: move.FromStationID,
+		BatteryRemain: scooterStatus.BatteryRemain}
+	moving := rentable
+	moving.CanBeRent = false
+	rs.Scooters.Events.StatusChanged(move.ScooterID, rentable, moving)
+
+	data := &proto.ScooterClient{Id: move.ScooterID, Latitude: scooterStatus.Latitude,
+		Longitude: scooterStatus.Longitude, BatteryRemain: scooterStatus.BatteryRemain,
+		DestLatitude: station.Latitude, DestLongitude: station.Longitude, StationID: int64(station.Id)}
+	deliverCtx, cancel := context.WithTimeout(ctx, dispatchTimeout)
+	err = rs.Streams.Deliver(deliverCtx, data)
+	cancel()
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	default:
+		err = fmt.Errorf("%w: %v", ErrNotDelivered, err)
+	}
+
+	releaseErr := rs.Repo.ReleaseScooter(context.Background(), move.ScooterID)
+	if releaseErr != nil {
+		fmt.Println(releaseErr)
+		return err
+	}
+	rs.Scooters.Events.StatusChanged(move.ScooterID, moving, rentable)
+	return err
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"scooter_micro/report"
+	"scooter_micro/repository"
+	"time"
+)
+
+//ReportService builds the usage reports of the fleet from the stored trips and connections of the scooters.
+type ReportService struct {
+	Repo     *repository.ReportRepo
+	CellSize float64
+}
+
+//NewReportService creates a new ReportService. The heatmaps use the cells of the given size in degrees by default.
+func NewReportService(repo *repository.ReportRepo, cellSize float64) *ReportService {
+	return &ReportService{
+		Repo:     repo,
+		CellSize: cellSize,
+	}
+}
+
+//Heatmap bins the places where the trips started and ended in [from, to) into the grid. Zero cellSize uses
+//the default one.
+func (rs *ReportService) Heatmap(ctx context.Context, from, to time.Time, cellSize float64) (report.Heatmap, error) {
+	if cellSize == 0 {
+		cellSize = rs.CellSize
+	}
+	if cellSize <= 0 {
+		return report.Heatmap{}, report.ErrInvalidCellSize
+	}
+
+	points, err := rs.Repo.GetTripPoints(ctx, from, to)
+	if err != nil {
+		return report.Heatmap{}, err
+	}
+
+	heatmap, err := report.NewHeatmap(points, cellSize)
+	heatmap.From, heatmap.To = from, to
+	return heatmap, err
+}
+
+//Utilization returns the minutes every scooter was rented, idle and offline in [from, to).
+func (rs *ReportService) Utilization(ctx context.Context, from, to time.Time) ([]report.Utilization, error) {
+	usage, err := rs.Repo.GetUsage(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	utilization := make([]report.Utilization, 0, len(usage))
+	for _, u := range usage {
+		utilization = append(utilization, report.NewUtilization(u, from, to))
+	}
+	return utilization, nil
+}
@@ -6,9 +6,12 @@ import (
 	"google.golang.org/grpc"
 	"log"
 	"net"
+	"scooter_micro/auth"
 	"scooter_micro/config"
+	"scooter_micro/fleet"
 	"scooter_micro/proto"
 	"scooter_micro/repository"
+	"scooter_micro/tlsconfig"
 	"time"
 )
 
@@ -27,6 +30,8 @@ type Location struct {
 type ScooterService struct {
 	Repo *repository.ScooterRepo
 	Order proto.OrderServiceClient
+	Events *fleet.Publisher
+	Tasks *TaskService
 	*proto.UnimplementedScooterServiceServer
 }
 
@@ -39,11 +44,15 @@ type ScooterClient struct {
 	Stream        proto.ScooterService_ReceiveClient
 }
 
-//NewScooterService creates a new GrpcScooterService.
-func NewScooterService(repoScooter *repository.ScooterRepo, order proto.OrderServiceClient) *ScooterService {
+//NewScooterService creates a new GrpcScooterService. The changes of the scooters are published by the events
+//publisher, the nil publisher disables the fleet events. The tasks service opens the charging tasks.
+func NewScooterService(repoScooter *repository.ScooterRepo, order proto.OrderServiceClient,
+	events *fleet.Publisher, tasks *TaskService) *ScooterService {
 	return &ScooterService{
 		Repo: repoScooter,
 		Order: order,
+		Events: events,
+		Tasks: tasks,
 	}
 }
 
@@ -85,7 +94,16 @@ func (gss *ScooterService) InitAndRun(ctx context.Context, id *proto.ScooterID,
 		coordinate.Latitude = station.Latitude
 		coordinate.Longitude = station.Longitude
 
-		conn, err := grpc.DialContext(ctx, net.JoinHostPort("", config.GRPC_PORT), grpc.WithInsecure())
+		transportCredentials, err := tlsconfig.DialCredentials(tlsconfig.Config{CertFile: config.TLS_CERT_FILE,
+			KeyFile: config.TLS_KEY_FILE, CAFile: config.TLS_CA_FILE, ServerName: "localhost"})
+		if err != nil {
+			return err
+		}
+
+		conn, err := grpc.DialContext(ctx, net.JoinHostPort("", config.GRPC_PORT),
+			grpc.WithTransportCredentials(transportCredentials),
+			grpc.WithPerRPCCredentials(auth.TokenCredentials{Token: config.DEVICE_TOKEN,
+				Insecure: config.TLS_CA_FILE == ""}))
 
 		if err != nil {
 			log.Fatal(err)
@@ -243,9 +261,33 @@ func (gss *ScooterService) GetScooterStatus(ctx context.Context, status *proto.S
 	return gss.Repo.GetScooterStatus(ctx, status)
 }
 
-//SendCurrentStatus gives the access to the ScooterRepo.SendCurrentStatus function.
+//SendCurrentStatus updates the scooter status, publishes the fleet events of the changes made by the update
+//and opens or completes the charging tasks of the scooter.
 func (gss *ScooterService) SendCurrentStatus(ctx context.Context, status *proto.SendStatus) (*proto.Response, error) {
-	return gss.Repo.SendCurrentStatus(ctx, status)
+	previous, current, err := gss.Repo.UpdateScooterStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+
+	gss.Events.StatusChanged(status.ScooterID, previous, current)
+	if gss.Tasks != nil {
+		err = gss.Tasks.StatusChanged(ctx, status.ScooterID, previous, current)
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+	return &proto.Response{}, nil
+}
+
+//ConnectivityChanged records the connection of the scooter to the server or its disconnection and publishes
+//the fleet event. It is called only for the scooters identified by their certificates, the connectivity of the other
+//scooters is unknown. The connections are used by the utilization reports and the exports.
+func (gss *ScooterService) ConnectivityChanged(ctx context.Context, scooterID uint64, online bool) {
+	err := gss.Repo.SetConnected(ctx, scooterID, online)
+	if err != nil {
+		fmt.Println(err)
+	}
+	gss.Events.ConnectivityChanged(scooterID, online)
 }
 
 //CreateScooterStatusInRent gives the access to the ScooterRepo.CreateScooterStatusInRent function.
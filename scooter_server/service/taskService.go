@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"scooter_micro/maintenance"
+	"scooter_micro/repository"
+)
+
+//TaskService is responsible for the maintenance tasks of the scooters. The charging tasks are opened
+//when the battery drops below the thresholds and done when the scooter reports the charged battery.
+type TaskService struct {
+	Repo       *repository.TaskRepo
+	Thresholds maintenance.Thresholds
+}
+
+//NewTaskService creates a new TaskService.
+func NewTaskService(repo *repository.TaskRepo, thresholds maintenance.Thresholds) *TaskService {
+	return &TaskService{
+		Repo:       repo,
+		Thresholds: thresholds,
+	}
+}
+
+//StatusChanged opens or raises the charging task of the scooter whose battery has crossed a threshold and
+//completes it when the battery is restored. The new task is assigned to the station the scooter is parked at.
+func (ts *TaskService) StatusChanged(ctx context.Context, scooterID uint64, previous,
+	current repository.ScooterState) error {
+	if ts.Thresholds.Restored(previous.BatteryRemain, current.BatteryRemain) {
+		_, err := ts.Repo.CloseTasks(ctx, scooterID, maintenance.KindCharging)
+		return err
+	}
+	if !ts.Thresholds.Crossed(previous.BatteryRemain, current.BatteryRemain) {
+		return nil
+	}
+
+	task, err := ts.Repo.OpenTask(ctx, &repository.Task{ScooterID: scooterID, Kind: maintenance.KindCharging,
+		Priority: ts.Thresholds.Priority(current.BatteryRemain), BatteryRemain: current.BatteryRemain,
+		StationID: current.StationID})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Charging task %d of scooter %d has priority %d.\n", task.ID, scooterID, task.Priority)
+	return nil
+}
+
+//ListTasks returns the task queue selected by the filter.
+func (ts *TaskService) ListTasks(ctx context.Context, filter repository.TaskFilter) ([]*repository.Task, error) {
+	return ts.Repo.ListTasks(ctx, filter)
+}
+
+//GetTask returns the task by its ID.
+func (ts *TaskService) GetTask(ctx context.Context, id uint64) (*repository.Task, error) {
+	return ts.Repo.GetTask(ctx, id)
+}
+
+//AssignTask assigns the task to the station and the operator.
+func (ts *TaskService) AssignTask(ctx context.Context, id, stationID, operatorID uint64) (*repository.Task, error) {
+	return ts.Repo.AssignTask(ctx, id, stationID, operatorID)
+}
+
+//CompleteTask marks the task as done by the operator.
+func (ts *TaskService) CompleteTask(ctx context.Context, id uint64) (*repository.Task, error) {
+	return ts.Repo.CompleteTask(ctx, id)
+}
+
+//CancelTask cancels the task which isn't needed anymore.
+func (ts *TaskService) CancelTask(ctx context.Context, id uint64) (*repository.Task, error) {
+	return ts.Repo.CancelTask(ctx, id)
+}
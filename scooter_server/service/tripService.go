@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"scooter_micro/commands"
+	"scooter_micro/proto"
+	"scooter_micro/repository"
+	"scooter_micro/telemetry"
+	"time"
+)
+
+var (
+	ErrInvalidTransition  = errors.New("trip can't be moved to this state")
+	ErrScooterUnavailable = errors.New("scooter can't be rented")
+	ErrNotAllowedLocation = errors.New("trip can't be ended here, park the scooter near a station")
+	ErrPaymentRequired    = errors.New("not enough money in the wallet for the trip deposit")
+)
+
+//actorService is the actor of the order state changes made by the trips.
+const actorService = "scooter_server"
+
+//settledOrders are the states of the order which was billed or closed by an operator, the trip with such
+//an order isn't billed again.
+var settledOrders = map[string]bool{"completed": true, "refunded": true, "disputed": true, "cancelled": true}
+
+//transitions lists the states each trip state can be moved to.
+var transitions = map[string][]string{
+	repository.TripActive: {repository.TripPaused, repository.TripEnded},
+	repository.TripPaused: {repository.TripActive, repository.TripEnded},
+}
+
+//Commander sends the remote commands to the scooters.
+type Commander interface {
+	SendCommand(ctx context.Context, request *proto.CommandRequest) (*proto.CommandResult, error)
+}
+
+//PositionSource returns the last point of the scooter accepted from its telemetry stream.
+type PositionSource interface {
+	Last(scooterID uint64) (telemetry.Point, bool)
+}
+
+//TripService is responsible for the trips driven by the riders.
+//The paused scooter is locked, the state transitions are recorded to be priced by the order service.
+//The positions of the trip events and the parking check are taken from the telemetry of the scooter.
+type TripService struct {
+	Repo          *repository.TripRepo
+	Scooters      *ScooterService
+	Commander     Commander
+	Positions     PositionSource
+	ParkingRadius float64
+}
+
+//NewTripService creates a new TripService. parkingRadius is the distance in meters from an active station
+//where the trip can be ended.
+func NewTripService(repo *repository.TripRepo, scooters *ScooterService, commander Commander,
+	positions PositionSource, parkingRadius float64) *TripService {
+	return &TripService{
+		Repo:          repo,
+		Scooters:      scooters,
+		Commander:     commander,
+		Positions:     positions,
+		ParkingRadius: parkingRadius,
+	}
+}
+
+//StartTrip starts the order which holds the trip deposit in the wallet of the rider, unlocks the scooter
+//and creates an active trip. The order is cancelled and its deposit is released if the trip can't be started.
+//The promo code is applied when the trip is priced.
+func (ts *TripService) StartTrip(ctx context.Context, userID, scooterID uint64,
+	promoCode string) (*repository.Trip, error) {
+	id := &proto.ScooterID{Id: scooterID}
+	scooter, err := ts.Scooters.GetScooterById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := ts.Repo.HasOpenTrip(ctx, scooterID)
+	if err != nil {
+		return nil, err
+	}
+	if !scooter.CanBeRent || open {
+		return nil, ErrScooterUnavailable
+	}
+
+	order, err := ts.Scooters.Order.StartOrder(ctx, &proto.TripInfo{UserID: userID, ScooterID: scooterID,
+		PromoCode: promoCode})
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentRequired, status.Convert(err).Message())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ts.position(ctx, scooterID)
+	var statusStart *proto.ScooterStatusInRent
+	if err == nil {
+		statusStart, err = ts.Scooters.CreateScooterStatusInRent(ctx, id)
+	}
+	if err == nil {
+		err = ts.command(ctx, scooterID, "unlock")
+	}
+	if err != nil {
+		ts.cancelOrder(ctx, order.Id, err)
+		return nil, err
+	}
+
+	trip := &repository.Trip{UserID: userID, ScooterID: scooterID, StatusStartID: statusStart.Id,
+		OrderID: order.Id, HoldID: order.HoldID, PromoCode: promoCode}
+	trip, err = ts.Repo.CreateTrip(ctx, trip, repository.TripEvent{State: repository.TripActive,
+		DateTime: time.Now(), Latitude: statusStart.Latitude, Longitude: statusStart.Longitude})
+	if err != nil {
+		lockErr := ts.command(ctx, scooterID, "lock")
+		if lockErr != nil {
+			fmt.Println(lockErr)
+		}
+		ts.cancelOrder(ctx, order.Id, err)
+		return nil, err
+	}
+	ts.Scooters.Events.TripStarted(trip)
+
+	_, err = ts.Scooters.Order.ConfirmOrder(ctx, &proto.OrderChange{OrderID: order.Id, Actor: actorService,
+		Reason: "scooter unlocked"})
+	if err != nil {
+		fmt.Println(err)
+	}
+	return trip, nil
+}
+
+//PauseTrip locks the scooter, the rider is billed by the pause rate until the trip is resumed.
+func (ts *TripService) PauseTrip(ctx context.Context, tripID uint64) (*repository.Trip, error) {
+	return ts.transit(ctx, tripID, repository.TripPaused, "lock")
+}
+
+//ResumeTrip unlocks the paused scooter.
+func (ts *TripService) ResumeTrip(ctx context.Context, tripID uint64) (*repository.Trip, error) {
+	return ts.transit(ctx, tripID, repository.TripActive, "unlock")
+}
+
+//EndTrip ends the trip if the scooter is parked near an active station, locks the scooter
+//and creates the order with all the trip events. The ended trip which can't be billed is left unsettled,
+//SettleTrips retries its order later.
+func (ts *TripService) EndTrip(ctx context.Context, tripID uint64) (*repository.Trip, error) {
+	trip, err := ts.Repo.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransit(trip.State, repository.TripEnded) {
+		return nil, ErrInvalidTransition
+	}
+
+	id := &proto.ScooterID{Id: trip.ScooterID}
+	scooterStatus, err := ts.position(ctx, trip.ScooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	station, err := ts.nearestStation(ctx, scooterStatus.Latitude, scooterStatus.Longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.command(ctx, trip.ScooterID, "end_trip")
+	if err == nil {
+		err = ts.command(ctx, trip.ScooterID, "lock")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	statusEnd, err := ts.Scooters.CreateScooterStatusInRent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from := trip.State
+	trip.StatusEndID = statusEnd.Id
+	err = ts.Repo.ChangeTripState(ctx, trip, from, repository.TripEvent{State: repository.TripEnded,
+		DateTime: time.Now(), Latitude: statusEnd.Latitude, Longitude: statusEnd.Longitude})
+	if err != nil {
+		return nil, err
+	}
+	ts.Scooters.Events.TripEnded(trip, station.Id)
+
+	_, err = ts.Scooters.SendCurrentStatus(ctx, &proto.SendStatus{ScooterID: trip.ScooterID,
+		StationID: station.Id, Latitude: scooterStatus.Latitude, Longitude: scooterStatus.Longitude,
+		BatteryRemain: scooterStatus.BatteryRemain})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	err = ts.settle(ctx, trip)
+	if err != nil {
+		fmt.Printf("trip %d is ended, its order will be retried: %v\n", trip.ID, err)
+	}
+	return trip, nil
+}
+
+//SettleTrips bills the ended trips whose orders weren't created, at most limit trips at once.
+//It returns the number of the settled trips, the trips which still fail are retried by the next call.
+func (ts *TripService) SettleTrips(ctx context.Context, limit uint64) (int, error) {
+	ids, err := ts.Repo.ListUnsettledTrips(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, id := range ids {
+		trip, err := ts.Repo.GetTrip(ctx, id)
+		if err == nil {
+			err = ts.settle(ctx, trip)
+		}
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+//RunSettlement settles the ended trips every interval until the context is done.
+func (ts *TripService) RunSettlement(ctx context.Context, interval time.Duration, limit uint64) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := ts.SettleTrips(ctx, limit)
+		if err != nil {
+			fmt.Println(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//GetTrip returns the trip with its events.
+func (ts *TripService) GetTrip(ctx context.Context, tripID uint64) (*repository.Trip, error) {
+	return ts.Repo.GetTrip(ctx, tripID)
+}
+
+//ListTrips returns the trips of the user, zero userID lists all the trips.
+func (ts *TripService) ListTrips(ctx context.Context, userID uint64) ([]*repository.Trip, error) {
+	return ts.Repo.ListTrips(ctx, userID)
+}
+
+//transit sends the command to the scooter and moves the trip to the new state.
+func (ts *TripService) transit(ctx context.Context, tripID uint64, to, command string) (*repository.Trip, error) {
+	trip, err := ts.Repo.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransit(trip.State, to) {
+		return nil, ErrInvalidTransition
+	}
+
+	scooterStatus, err := ts.position(ctx, trip.ScooterID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.command(ctx, trip.ScooterID, command)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ts.Repo.ChangeTripState(ctx, trip, trip.State, repository.TripEvent{State: to, DateTime: time.Now(),
+		Latitude: scooterStatus.Latitude, Longitude: scooterStatus.Longitude})
+	if err != nil {
+		return nil, err
+	}
+	return trip, nil
+}
+
+//position returns the last position of the scooter accepted from its stream and saves it as the scooter status,
+//so the statuses in rent are created at it. The saved status is used for the scooter which hasn't reported
+//its position by the stream.
+func (ts *TripService) position(ctx context.Context, scooterID uint64) (*proto.ScooterStatus, error) {
+	if ts.Positions != nil {
+		if point, ok := ts.Positions.Last(scooterID); ok {
+			err := ts.Scooters.Repo.SetScooterPosition(ctx, scooterID, point.Latitude, point.Longitude,
+				point.BatteryRemain)
+			if err != nil {
+				return nil, err
+			}
+			return &proto.ScooterStatus{Latitude: point.Latitude, Longitude: point.Longitude,
+				BatteryRemain: point.BatteryRemain}, nil
+		}
+	}
+	return ts.Scooters.GetScooterStatus(ctx, &proto.ScooterID{Id: scooterID})
+}
+
+//command sends the remote command to the scooter and checks that the scooter has executed it.
+func (ts *TripService) command(ctx context.Context, scooterID uint64, name string) error {
+	command, err := commands.Parse(name, 0)
+	if err != nil {
+		return err
+	}
+
+	result, err := ts.Commander.SendCommand(ctx, &proto.CommandRequest{ScooterID: scooterID, Command: command})
+	if err != nil {
+		return err
+	}
+	if !result.Ok {
+		return fmt.Errorf("scooter %d failed to %v: %v", scooterID, name, result.Error)
+	}
+	return nil
+}
+
+//settle completes the order of the ended trip and marks the trip settled. The order completed by the previous
+//attempt, whose reply was lost, or cancelled by an operator is only linked to the trip.
+func (ts *TripService) settle(ctx context.Context, trip *repository.Trip) error {
+	order, err := ts.Scooters.Order.CreateOrder(ctx, tripInfo(trip))
+	if err != nil && trip.OrderID != 0 {
+		current, getErr := ts.Scooters.Order.GetOrder(ctx, &proto.OrderID{Id: trip.OrderID})
+		if getErr == nil && settledOrders[current.State] {
+			order, err = current, nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("order of trip %d isn't created: %w", trip.ID, err)
+	}
+
+	trip.OrderID = order.Id
+	return ts.Repo.SettleTrip(ctx, trip.ID, order.Id)
+}
+
+//cancelOrder cancels the order of the trip which hasn't been started.
+func (ts *TripService) cancelOrder(ctx context.Context, orderID uint64, cause error) {
+	_, err := ts.Scooters.Order.CancelOrder(ctx, &proto.OrderChange{OrderID: orderID, Actor: actorService,
+		Reason: cause.Error()})
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+//nearestStation returns the nearest active station in the parking radius.
+func (ts *TripService) nearestStation(ctx context.Context, latitude, longitude float64) (*proto.Station, error) {
+	stations, err := ts.Scooters.GetAllStations(ctx, &proto.Request{})
+	if err != nil {
+		return nil, err
+	}
+
+	var nearest *proto.Station
+	minDistance := ts.ParkingRadius
+	for _, station := range stations.Stations {
+		if !station.IsActive {
+			continue
+		}
+		distance := telemetry.Distance(latitude, longitude, station.Latitude, station.Longitude)
+		if distance <= minDistance {
+			nearest, minDistance = station, distance
+		}
+	}
+
+	if nearest == nil {
+		return nil, ErrNotAllowedLocation
+	}
+	return nearest, nil
+}
+
+func canTransit(from, to string) bool {
+	for _, state := range transitions[from] {
+		if state == to {
+			return true
+		}
+	}
+	return false
+}
+
+//tripInfo converts the ended trip to the order request. The route distance is measured between
+//the positions of the trip events.
+func tripInfo(trip *repository.Trip) *proto.TripInfo {
+	info := &proto.TripInfo{UserID: trip.UserID, ScooterID: trip.ScooterID, StatusStartID: trip.StatusStartID,
+		StatusEndID: trip.StatusEndID, HoldID: trip.HoldID, PromoCode: trip.PromoCode, OrderID: trip.OrderID}
+	for i, event := range trip.Events {
+		info.Events = append(info.Events, &proto.TripEvent{State: event.State,
+			DateTime: timestamppb.New(event.DateTime)})
+		if i > 0 {
+			previous := trip.Events[i-1]
+			info.Distance += telemetry.Distance(previous.Latitude, previous.Longitude, event.Latitude,
+				event.Longitude)
+		}
+	}
+	return info
+}
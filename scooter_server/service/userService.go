@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"scooter_micro/auth"
+	"scooter_micro/repository"
+	"strings"
+	"time"
+)
+
+const minPasswordLength = 8
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrWeakPassword       = errors.New("password must have at least 8 characters")
+	ErrInvalidRole        = errors.New("invalid role")
+)
+
+//UserService is responsible for the registration and the sessions of the users.
+type UserService struct {
+	Repo       *repository.UserRepo
+	SessionTTL time.Duration
+}
+
+//Session is the token given to the logged-in user.
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+//NewUserService creates a new UserService.
+func NewUserService(repo *repository.UserRepo, sessionTTL time.Duration) *UserService {
+	return &UserService{
+		Repo:       repo,
+		SessionTTL: sessionTTL,
+	}
+}
+
+//Register creates a new user with the hashed password.
+func (us *UserService) Register(ctx context.Context, email, password string) (*repository.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if len(password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return us.Repo.CreateUser(ctx, email, hash)
+}
+
+//Login checks the password of the user and creates a new session.
+func (us *UserService) Login(ctx context.Context, email, password string) (*Session, error) {
+	user, err := us.Repo.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !auth.CheckPassword(user.PasswordHash, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{Token: token, ExpiresAt: time.Now().Add(us.SessionTTL)}
+	err = us.Repo.CreateSession(ctx, hash, user.ID, session.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+//Logout ends the session of the token.
+func (us *UserService) Logout(ctx context.Context, token string) error {
+	return us.Repo.DeleteSession(ctx, auth.HashToken(token))
+}
+
+//Authenticate returns the user of the session token.
+func (us *UserService) Authenticate(ctx context.Context, token string) (*auth.User, error) {
+	user, err := us.Repo.GetSessionUser(ctx, auth.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	return &auth.User{ID: user.ID, Email: user.Email, Role: auth.Role(user.Role)}, nil
+}
+
+//SetRole changes the role of the user.
+func (us *UserService) SetRole(ctx context.Context, userID uint64, role auth.Role) error {
+	if !auth.ValidRole(role) {
+		return ErrInvalidRole
+	}
+	return us.Repo.SetRole(ctx, userID, string(role))
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"scooter_micro/proto"
+	"sort"
+	"sync"
+)
+
+//reorderWindow is how far ahead of the last applied sequence number the messages are held while the missing ones
+//are awaited.
+const reorderWindow = 8
+
+//Sequencer drops duplicated and stale telemetry messages by their sequence numbers and orders the rest.
+//The message which skips sequence numbers is held until the missing messages arrive, then they are applied
+//in the sequence order. The wait is bounded by the reorder window: the message too far ahead of the last applied
+//one, like the first one after the client restarts, releases the held messages in order instead of waiting,
+//the missing ones which arrive later are stale and dropped.
+type Sequencer struct {
+	mu      sync.Mutex
+	windows map[uint64]*window
+}
+
+//window is the last applied sequence number of the scooter with its held messages by their sequence numbers.
+type window struct {
+	last uint64
+	held map[uint64]*proto.ClientMessage
+}
+
+//NewSequencer creates a new Sequencer.
+func NewSequencer() *Sequencer {
+	return &Sequencer{windows: make(map[uint64]*window)}
+}
+
+//Push adds the message of the scooter and returns the messages ready to be applied in the sequence order,
+//none when the message is held or dropped. Zero sequence means the client doesn't number its messages,
+//such messages are applied at once.
+func (sq *Sequencer) Push(msg *proto.ClientMessage) []*proto.ClientMessage {
+	if msg.Sequence == 0 {
+		return []*proto.ClientMessage{msg}
+	}
+
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+
+	w, ok := sq.windows[msg.Id]
+	if !ok {
+		w = &window{held: make(map[uint64]*proto.ClientMessage)}
+		sq.windows[msg.Id] = w
+	}
+
+	switch {
+	case w.last == 0 || msg.Sequence == w.last+1:
+		w.last = msg.Sequence
+		return append([]*proto.ClientMessage{msg}, w.release(false)...)
+	case msg.Sequence <= w.last || w.held[msg.Sequence] != nil:
+		return nil
+	case msg.Sequence-w.last > reorderWindow:
+		ready := append(w.release(true), msg)
+		w.last = msg.Sequence
+		return ready
+	}
+	w.held[msg.Sequence] = msg
+	return nil
+}
+
+//release removes the held messages which follow the last applied one without a gap and returns them in order,
+//all the held messages when all is set.
+func (w *window) release(all bool) []*proto.ClientMessage {
+	sequences := make([]uint64, 0, len(w.held))
+	for sequence := range w.held {
+		sequences = append(sequences, sequence)
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+
+	var ready []*proto.ClientMessage
+	for _, sequence := range sequences {
+		if !all && sequence != w.last+1 {
+			break
+		}
+		ready = append(ready, w.held[sequence])
+		delete(w.held, sequence)
+		w.last = sequence
+	}
+	return ready
+}
+
+//Reset forgets the last sequence number and the held messages of the scooter. It is called when the scooter
+//is bound to another device, which numbers its messages independently.
+func (sq *Sequencer) Reset(scooterID uint64) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	delete(sq.windows, scooterID)
+}
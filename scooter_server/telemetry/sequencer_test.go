@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"reflect"
+	"scooter_micro/proto"
+	"testing"
+)
+
+//sequences returns the sequence numbers of the messages.
+func sequences(messages []*proto.ClientMessage) []uint64 {
+	var numbers []uint64
+	for _, msg := range messages {
+		numbers = append(numbers, msg.Sequence)
+	}
+	return numbers
+}
+
+func TestSequencer(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []uint64
+		want  [][]uint64
+	}{
+		{name: "in order", steps: []uint64{5, 6, 7}, want: [][]uint64{{5}, {6}, {7}}},
+		{name: "duplicate and stale", steps: []uint64{5, 5, 4, 6}, want: [][]uint64{{5}, nil, nil, {6}}},
+		{name: "reordered", steps: []uint64{5, 7, 8, 6, 9}, want: [][]uint64{{5}, nil, nil, {6, 7, 8}, {9}}},
+		{name: "held duplicate", steps: []uint64{5, 7, 7, 6}, want: [][]uint64{{5}, nil, nil, {6, 7}}},
+		{name: "gap filled partly", steps: []uint64{5, 8, 7, 6}, want: [][]uint64{{5}, nil, nil, {6, 7, 8}}},
+		{name: "jump beyond the window", steps: []uint64{5, 7, 9, 5 + reorderWindow + 1, 6},
+			want: [][]uint64{{5}, nil, nil, {7, 9, 5 + reorderWindow + 1}, nil}},
+		{name: "restart", steps: []uint64{5, 1000, 1001}, want: [][]uint64{{5}, {1000}, {1001}}},
+		{name: "not numbered", steps: []uint64{5, 0, 0, 4}, want: [][]uint64{{5}, {0}, {0}, nil}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sq := NewSequencer()
+			for i, sequence := range test.steps {
+				got := sequences(sq.Push(&proto.ClientMessage{Id: 1, Sequence: sequence}))
+				if !reflect.DeepEqual(got, test.want[i]) {
+					t.Fatalf("step %d: Push(%d) = %v, want %v", i, sequence, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSequencerHoldsAtMostTheWindow(t *testing.T) {
+	sq := NewSequencer()
+	sq.Push(&proto.ClientMessage{Id: 1, Sequence: 1})
+	for sequence := uint64(3); sequence <= 1+reorderWindow; sequence++ {
+		if got := sq.Push(&proto.ClientMessage{Id: 1, Sequence: sequence}); got != nil {
+			t.Fatalf("Push(%d) = %v, want the message held until 2 arrives", sequence, sequences(got))
+		}
+	}
+
+	got := sequences(sq.Push(&proto.ClientMessage{Id: 1, Sequence: 2 + reorderWindow}))
+	if len(got) != reorderWindow || got[0] != 3 || got[len(got)-1] != 2+reorderWindow {
+		t.Errorf("Push beyond the window = %v, want the held messages from 3 in order and the pushed one", got)
+	}
+}
+
+func TestSequencerReset(t *testing.T) {
+	sq := NewSequencer()
+	sq.Push(&proto.ClientMessage{Id: 1, Sequence: 10})
+	sq.Push(&proto.ClientMessage{Id: 1, Sequence: 12})
+
+	if got := sq.Push(&proto.ClientMessage{Id: 2, Sequence: 1}); len(got) != 1 {
+		t.Error("sequence numbers of the scooters aren't independent")
+	}
+
+	sq.Reset(1)
+	if got := sequences(sq.Push(&proto.ClientMessage{Id: 1, Sequence: 5})); !reflect.DeepEqual(got, []uint64{5}) {
+		t.Errorf("Push after Reset = %v, want [5] without the held message", got)
+	}
+	if got := sequences(sq.Push(&proto.ClientMessage{Id: 1, Sequence: 6})); !reflect.DeepEqual(got, []uint64{6}) {
+		t.Errorf("Push after Reset = %v, want [6]", got)
+	}
+}
@@ -0,0 +1,207 @@
+package telemetry
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	earthRadius     = 6371000.0
+	recentRejection = 50
+)
+
+//Reason describes why a telemetry message was rejected.
+type Reason string
+
+const (
+	ReasonLatitude  Reason = "latitude_out_of_range"
+	ReasonLongitude Reason = "longitude_out_of_range"
+	ReasonBattery   Reason = "battery_out_of_range"
+	ReasonSpeed     Reason = "implausible_speed"
+	ReasonUnbound   Reason = "scooter_not_bound_to_stream"
+	ReasonTimestamp Reason = "out_of_order_timestamp"
+)
+
+//Point is a single telemetry sample received from a scooter.
+type Point struct {
+	ScooterID     uint64
+	Latitude      float64
+	Longitude     float64
+	BatteryRemain float64
+	Time          time.Time
+}
+
+//Limits holds the thresholds used by the Validator.
+type Limits struct {
+	//MaxSpeed is the highest plausible speed between two consecutive points in km/h.
+	MaxSpeed float64
+	//Tolerance is the distance in meters which is always accepted regardless of the speed,
+	//it absorbs the GPS noise and the jitter of the receive time.
+	Tolerance float64
+}
+
+//Rejection is an error returned by the Validator for a message which must not be processed.
+type Rejection struct {
+	ScooterID uint64    `json:"scooterId"`
+	Reason    Reason    `json:"reason"`
+	Detail    string    `json:"detail"`
+	Time      time.Time `json:"time"`
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("telemetry of scooter %d rejected: %s (%s)", r.ScooterID, r.Reason, r.Detail)
+}
+
+//Stats is a snapshot of the rejected telemetry counters.
+type Stats struct {
+	Total     uint64            `json:"total"`
+	ByReason  map[Reason]uint64 `json:"byReason"`
+	ByScooter map[uint64]uint64 `json:"byScooter"`
+	Recent    []Rejection       `json:"recent"`
+}
+
+//Validator checks the incoming telemetry and counts the rejected messages.
+//The points of the streams carry the device time and the status pushes are timed by the server clock,
+//so each source is compared only with its own last points and the clock skew doesn't reject them.
+type Validator struct {
+	limits    Limits
+	mu        sync.Mutex
+	last      map[uint64]Point
+	lastPush  map[uint64]Point
+	total     uint64
+	byReason  map[Reason]uint64
+	byScooter map[uint64]uint64
+	recent    []Rejection
+}
+
+//NewValidator creates a new Validator with the given limits.
+func NewValidator(limits Limits) *Validator {
+	return &Validator{
+		limits:    limits,
+		last:      make(map[uint64]Point),
+		lastPush:  make(map[uint64]Point),
+		byReason:  make(map[Reason]uint64),
+		byScooter: make(map[uint64]uint64),
+	}
+}
+
+//Validate checks the point received by the stream and remembers it as the last accepted point of the scooter.
+//boundID is the scooter ID bound to the stream the point came from.
+func (v *Validator) Validate(boundID uint64, p Point) error {
+	return v.validate(boundID, p, v.last)
+}
+
+//ValidatePush checks the status pushed by the scooter without the stream, its time is the receive time.
+func (v *Validator) ValidatePush(p Point) error {
+	return v.validate(0, p, v.lastPush)
+}
+
+func (v *Validator) validate(boundID uint64, p Point, last map[uint64]Point) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	rejection := v.check(boundID, p, last)
+	if rejection != nil {
+		v.count(rejection)
+		log.Println(rejection)
+		return rejection
+	}
+
+	last[p.ScooterID] = p
+	return nil
+}
+
+func (v *Validator) check(boundID uint64, p Point, points map[uint64]Point) *Rejection {
+	reject := func(reason Reason, format string, args ...interface{}) *Rejection {
+		return &Rejection{ScooterID: p.ScooterID, Reason: reason, Detail: fmt.Sprintf(format, args...), Time: p.Time}
+	}
+
+	switch {
+	case boundID != 0 && p.ScooterID != boundID:
+		return reject(ReasonUnbound, "stream is bound to scooter %d", boundID)
+	case math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90:
+		return reject(ReasonLatitude, "latitude %v", p.Latitude)
+	case math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180:
+		return reject(ReasonLongitude, "longitude %v", p.Longitude)
+	case math.IsNaN(p.BatteryRemain) || p.BatteryRemain < 0 || p.BatteryRemain > 100:
+		return reject(ReasonBattery, "battery %v", p.BatteryRemain)
+	}
+
+	last, ok := points[p.ScooterID]
+	if !ok {
+		return nil
+	}
+
+	if p.Time.Before(last.Time) {
+		return reject(ReasonTimestamp, "%v is before the last accepted %v", p.Time, last.Time)
+	}
+
+	distance := Distance(last.Latitude, last.Longitude, p.Latitude, p.Longitude)
+	elapsed := p.Time.Sub(last.Time).Hours()
+	if distance > v.limits.Tolerance && elapsed > 0 {
+		speed := distance / 1000 / elapsed
+		if speed > v.limits.MaxSpeed {
+			return reject(ReasonSpeed, "%.1f km/h over %.0f m", speed, distance)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) count(r *Rejection) {
+	v.total++
+	v.byReason[r.Reason]++
+	v.byScooter[r.ScooterID]++
+
+	v.recent = append(v.recent, *r)
+	if len(v.recent) > recentRejection {
+		v.recent = v.recent[len(v.recent)-recentRejection:]
+	}
+}
+
+//Forget drops the last point of the scooter accepted from a stream, so the next point is not compared with it.
+func (v *Validator) Forget(scooterID uint64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.last, scooterID)
+}
+
+//Last returns the last point of the scooter accepted from its stream.
+func (v *Validator) Last(scooterID uint64) (Point, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	point, ok := v.last[scooterID]
+	return point, ok
+}
+
+//Stats returns a copy of the rejected telemetry counters.
+func (v *Validator) Stats() Stats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	stats := Stats{
+		Total:     v.total,
+		ByReason:  make(map[Reason]uint64, len(v.byReason)),
+		ByScooter: make(map[uint64]uint64, len(v.byScooter)),
+		Recent:    append([]Rejection(nil), v.recent...),
+	}
+	for k, n := range v.byReason {
+		stats.ByReason[k] = n
+	}
+	for k, n := range v.byScooter {
+		stats.ByScooter[k] = n
+	}
+	return stats
+}
+
+//Distance returns the great-circle distance between two points in meters.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,131 @@
+package telemetry
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func newTestValidator() *Validator {
+	return NewValidator(Limits{MaxSpeed: 60, Tolerance: 25})
+}
+
+func reason(err error) Reason {
+	var rejection *Rejection
+	if errors.As(err, &rejection) {
+		return rejection.Reason
+	}
+	return ""
+}
+
+func TestValidateRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		point Point
+		want  Reason
+	}{
+		{name: "valid", point: Point{ScooterID: 1, Latitude: 48.45, Longitude: 35.05, BatteryRemain: 50}},
+		{name: "latitude", point: Point{ScooterID: 1, Latitude: 91, BatteryRemain: 50}, want: ReasonLatitude},
+		{name: "NaN latitude", point: Point{ScooterID: 1, Latitude: math.NaN()}, want: ReasonLatitude},
+		{name: "longitude", point: Point{ScooterID: 1, Longitude: -181}, want: ReasonLongitude},
+		{name: "battery", point: Point{ScooterID: 1, BatteryRemain: 101}, want: ReasonBattery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.point.Time = start
+			got := reason(newTestValidator().Validate(1, tt.point))
+			if got != tt.want {
+				t.Errorf("Validate reason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnbound(t *testing.T) {
+	err := newTestValidator().Validate(2, Point{ScooterID: 1, Time: start})
+	if got := reason(err); got != ReasonUnbound {
+		t.Errorf("Validate reason = %q, want %q", got, ReasonUnbound)
+	}
+}
+
+func TestValidateSequence(t *testing.T) {
+	tests := []struct {
+		name string
+		next Point
+		want Reason
+	}{
+		{name: "plausible speed", next: Point{Latitude: 0.001, Time: start.Add(10 * time.Second)}},
+		{name: "within tolerance", next: Point{Latitude: 0.0002, Time: start}},
+		{name: "too fast", next: Point{Latitude: 0.01, Time: start.Add(time.Second)}, want: ReasonSpeed},
+		{name: "out of order", next: Point{Time: start.Add(-time.Second)}, want: ReasonTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestValidator()
+			err := v.Validate(1, Point{ScooterID: 1, Time: start})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.next.ScooterID = 1
+			got := reason(v.Validate(1, tt.next))
+			if got != tt.want {
+				t.Errorf("Validate reason = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && v.Stats().ByReason[tt.want] != 1 {
+				t.Errorf("rejection %q isn't counted: %v", tt.want, v.Stats())
+			}
+		})
+	}
+}
+
+func TestValidatePushHasOwnClock(t *testing.T) {
+	v := newTestValidator()
+	err := v.Validate(1, Point{ScooterID: 1, Time: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = v.ValidatePush(Point{ScooterID: 1, Time: start})
+	if err != nil {
+		t.Errorf("push behind the device clock is rejected: %v", err)
+	}
+	err = v.ValidatePush(Point{ScooterID: 1, Time: start.Add(-time.Second)})
+	if got := reason(err); got != ReasonTimestamp {
+		t.Errorf("out of order push reason = %q, want %q", got, ReasonTimestamp)
+	}
+
+	last, ok := v.Last(1)
+	if !ok || !last.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("Last = %v, %v, want the stream point", last, ok)
+	}
+}
+
+func TestForget(t *testing.T) {
+	v := newTestValidator()
+	err := v.Validate(1, Point{ScooterID: 1, Time: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v.Forget(1)
+	if _, ok := v.Last(1); ok {
+		t.Error("forgotten point is still the last one")
+	}
+	err = v.Validate(1, Point{ScooterID: 1, Latitude: 1, Time: start})
+	if err != nil {
+		t.Errorf("point after Forget is compared with the forgotten one: %v", err)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	got := Distance(0, 0, 0, 1)
+	if math.Abs(got-111195) > 1 {
+		t.Errorf("Distance of one degree of the equator = %.0f m, want 111195 m", got)
+	}
+}
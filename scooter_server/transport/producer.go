@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"errors"
+	"github.com/Shopify/sarama"
+	"log"
+	"scooter_micro/events"
+	"scooter_micro/proto"
+)
+
+var kafkaVersion = sarama.V3_0_0_0
+
+//ErrProducerBusy is returned when the buffer of the producer is full and the message is dropped.
+var ErrProducerBusy = errors.New("producer buffer is full")
+
+//CreateProducer creates the asynchronous producer which keeps the order of the messages with the same key.
+//The messages are partitioned by the hash of the key and only one request is in flight while retrying.
+//The messages which could not be delivered after the retries are logged.
+func CreateProducer(brokerList []string, clientID string) (sarama.AsyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Retry.Max = 10
+	config.Producer.Return.Errors = true
+	config.Producer.Partitioner = sarama.NewHashPartitioner
+	config.Net.MaxOpenRequests = 1
+	config.ClientID = clientID
+
+	producer, err := sarama.NewAsyncProducer(brokerList, config)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for err := range producer.Errors() {
+			log.Printf("transport: failed to deliver the message to %v: %v\n", err.Msg.Topic, err.Err)
+		}
+	}()
+	return producer, nil
+}
+
+//SendEvent queues the protobuf encoded envelope with the headers of its content type, event type and schema version.
+//It doesn't wait for the delivery and never blocks: the message is dropped with ErrProducerBusy
+//when the buffer of the producer is full.
+func SendEvent(producer sarama.AsyncProducer, topic, key string, envelope *proto.EventEnvelope) error {
+	message, err := events.Message(topic, key, envelope)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case producer.Input() <- message:
+		return nil
+	default:
+		return ErrProducerBusy
+	}
+}
+
+//CreateTopic creates the topic with nPartitions partitions. The existing topic which has fewer partitions
+//is extended, so the number of partitions can be raised by the configuration. Messages already written
+//keep their partitions, only the new messages of a key may be moved to another partition.
+func CreateTopic(brokerList []string, topicName string, nPartitions int32, replicas int16) error {
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+
+	admin, err := sarama.NewClusterAdmin(brokerList, config)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = admin.Close() }()
+
+	err = admin.CreateTopic(topicName, &sarama.TopicDetail{
+		NumPartitions:     nPartitions,
+		ReplicationFactor: replicas,
+	}, false)
+	var topicErr *sarama.TopicError
+	if !errors.As(err, &topicErr) || topicErr.Err != sarama.ErrTopicAlreadyExists {
+		return err
+	}
+
+	topics, err := admin.DescribeTopics([]string{topicName})
+	if err != nil {
+		return err
+	}
+	if len(topics) == 0 || len(topics[0].Partitions) >= int(nPartitions) {
+		return nil
+	}
+	return admin.CreatePartitions(topicName, nPartitions, nil, false)
+}
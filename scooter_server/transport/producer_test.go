@@ -0,0 +1,35 @@
+package transport
+
+import (
+	"errors"
+	"github.com/Shopify/sarama"
+	"scooter_micro/events"
+	"scooter_micro/proto"
+	"testing"
+)
+
+type fullProducer struct {
+	sarama.AsyncProducer
+	input chan *sarama.ProducerMessage
+}
+
+func (p *fullProducer) Input() chan<- *sarama.ProducerMessage {
+	return p.input
+}
+
+func TestSendEventDoesNotBlock(t *testing.T) {
+	envelope, err := events.New(events.TypeScooterOnline, "test", &proto.ScooterConnectivityChanged{ScooterID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	producer := &fullProducer{input: make(chan *sarama.ProducerMessage, 1)}
+	err = SendEvent(producer, "fleet_events", "1", envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = SendEvent(producer, "fleet_events", "1", envelope)
+	if !errors.Is(err, ErrProducerBusy) {
+		t.Errorf("SendEvent error = %v, want %v", err, ErrProducerBusy)
+	}
+}